@@ -0,0 +1,237 @@
+package aircraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lyarwood/godar/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WTCValue is the wake turbulence category reported by VRS
+type WTCValue int
+
+// Wake turbulence categories as enumerated by VRS
+const (
+	WTCNone WTCValue = iota
+	WTCLight
+	WTCMedium
+	WTCHeavy
+)
+
+var wtcNames = []string{"None", "Light", "Medium", "Heavy"}
+
+// wtcAliases maps the single letter ICAO categories onto VRS values
+var wtcAliases = map[string]WTCValue{"l": WTCLight, "m": WTCMedium, "h": WTCHeavy}
+
+// String returns the name of the wake turbulence category
+func (w WTCValue) String() string {
+	return enumName("WTC", wtcNames, w)
+}
+
+// ParseWTC parses a wake turbulence category from its name, ICAO letter or VRS number
+func ParseWTC(s string) (WTCValue, error) {
+	return parseEnum("WTC", s, wtcNames, wtcAliases)
+}
+
+// MarshalJSON encodes the category by name
+func (w WTCValue) MarshalJSON() ([]byte, error) {
+	return marshalEnum(wtcNames, w)
+}
+
+// UnmarshalJSON accepts VRS numbers, numeric strings and category names
+func (w *WTCValue) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("WTC", data, wtcNames, wtcAliases, w)
+}
+
+// SpeciesValue is the kind of aircraft reported by VRS
+type SpeciesValue int
+
+// Aircraft species as enumerated by VRS
+const (
+	SpeciesNone SpeciesValue = iota
+	SpeciesLandPlane
+	SpeciesSeaPlane
+	SpeciesAmphibian
+	SpeciesHelicopter
+	SpeciesGyrocopter
+	SpeciesTiltwing
+	SpeciesGroundVehicle
+	SpeciesTower
+)
+
+var speciesNames = []string{"None", "LandPlane", "SeaPlane", "Amphibian", "Helicopter", "Gyrocopter", "Tiltwing", "GroundVehicle", "Tower"}
+
+// speciesAliases maps the ICAO aircraft description letters onto VRS values
+var speciesAliases = map[string]SpeciesValue{
+	"l": SpeciesLandPlane,
+	"s": SpeciesSeaPlane,
+	"a": SpeciesAmphibian,
+	"h": SpeciesHelicopter,
+	"g": SpeciesGyrocopter,
+	"t": SpeciesTiltwing,
+}
+
+// String returns the name of the species
+func (s SpeciesValue) String() string {
+	return enumName("Species", speciesNames, s)
+}
+
+// ParseSpecies parses a species from its name, ICAO description letter or VRS number
+func ParseSpecies(s string) (SpeciesValue, error) {
+	return parseEnum("Species", s, speciesNames, speciesAliases)
+}
+
+// MarshalJSON encodes the species by name
+func (s SpeciesValue) MarshalJSON() ([]byte, error) {
+	return marshalEnum(speciesNames, s)
+}
+
+// UnmarshalJSON accepts VRS numbers, numeric strings and species names
+func (s *SpeciesValue) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("Species", data, speciesNames, speciesAliases, s)
+}
+
+// EngTypeValue is the engine type reported by VRS
+type EngTypeValue int
+
+// Engine types as enumerated by VRS
+const (
+	EngTypeNone EngTypeValue = iota
+	EngTypePiston
+	EngTypeTurbo
+	EngTypeJet
+	EngTypeElectric
+)
+
+var engTypeNames = []string{"None", "Piston", "Turbo", "Jet", "Electric"}
+
+// engTypeAliases maps the ICAO engine type letters and common names onto VRS values
+var engTypeAliases = map[string]EngTypeValue{
+	"p":          EngTypePiston,
+	"t":          EngTypeTurbo,
+	"turboprop":  EngTypeTurbo,
+	"turboshaft": EngTypeTurbo,
+	"j":          EngTypeJet,
+	"e":          EngTypeElectric,
+}
+
+// String returns the name of the engine type
+func (e EngTypeValue) String() string {
+	return enumName("EngType", engTypeNames, e)
+}
+
+// ParseEngType parses an engine type from its name, ICAO letter or VRS number
+func ParseEngType(s string) (EngTypeValue, error) {
+	return parseEnum("EngType", s, engTypeNames, engTypeAliases)
+}
+
+// MarshalJSON encodes the engine type by name
+func (e EngTypeValue) MarshalJSON() ([]byte, error) {
+	return marshalEnum(engTypeNames, e)
+}
+
+// UnmarshalJSON accepts VRS numbers, numeric strings and engine type names
+func (e *EngTypeValue) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum("EngType", data, engTypeNames, engTypeAliases, e)
+}
+
+// EngMountValue is the engine placement reported by VRS
+type EngMountValue int
+
+// Engine placements as enumerated by VRS
+const (
+	EngMountUnknown EngMountValue = iota
+	EngMountAft
+	EngMountWingBuried
+	EngMountFuselageBuried
+	EngMountNose
+	EngMountWing
+)
+
+var engMountNames = []string{"Unknown", "Aft", "WingBuried", "FuselageBuried", "Nose", "Wing"}
+
+// String returns the name of the engine placement
+func (e EngMountValue) String() string {
+	return enumName("EngMount", engMountNames, e)
+}
+
+// ParseEngMount parses an engine placement from its name or VRS number
+func ParseEngMount(s string) (EngMountValue, error) {
+	return parseEnum[EngMountValue]("EngMount", s, engMountNames, nil)
+}
+
+// MarshalJSON encodes the engine placement by name
+func (e EngMountValue) MarshalJSON() ([]byte, error) {
+	return marshalEnum(engMountNames, e)
+}
+
+// UnmarshalJSON accepts VRS numbers, numeric strings and engine placement names
+func (e *EngMountValue) UnmarshalJSON(data []byte) error {
+	return unmarshalEnum[EngMountValue]("EngMount", data, engMountNames, nil, e)
+}
+
+// enumName returns the name of v, or a numeric placeholder for values VRS added after this list
+func enumName[T ~int](kind string, names []string, v T) string {
+	if int(v) >= 0 && int(v) < len(names) {
+		return names[v]
+	}
+	return fmt.Sprintf("%s(%d)", kind, int(v))
+}
+
+// normaliseEnumName lowercases a name and strips separators so "Land Plane" matches "LandPlane"
+func normaliseEnumName(s string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.TrimSpace(s)))
+}
+
+// parseEnum resolves a name, alias or number into an enum value
+func parseEnum[T ~int](kind, s string, names []string, aliases map[string]T) (T, error) {
+	key := normaliseEnumName(s)
+	if key == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(key); err == nil {
+		return T(n), nil
+	}
+	for i, name := range names {
+		if normaliseEnumName(name) == key {
+			return T(i), nil
+		}
+	}
+	if v, ok := aliases[key]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("%s: unknown value %q", kind, s)
+}
+
+// marshalEnum encodes known values by name and unknown values as plain numbers
+func marshalEnum[T ~int](names []string, v T) ([]byte, error) {
+	if int(v) >= 0 && int(v) < len(names) {
+		return json.Marshal(names[v])
+	}
+	return json.Marshal(int(v))
+}
+
+// unmarshalEnum decodes either a JSON number or a JSON string into an enum value.
+// A name it does not recognise decodes as the zero value rather than failing the whole feed.
+func unmarshalEnum[T ~int](kind string, data []byte, names []string, aliases map[string]T, v *T) error {
+	// Try to unmarshal as string first
+	var strVal string
+	if err := json.Unmarshal(data, &strVal); err == nil {
+		parsed, err := parseEnum(kind, strVal, names, aliases)
+		if err != nil {
+			logger.Debug("Ignoring unknown aircraft attribute", zap.String("kind", kind), zap.String("value", strVal))
+		}
+		*v = parsed
+		return nil
+	}
+	// If string fails, try as number
+	var numVal int
+	if err := json.Unmarshal(data, &numVal); err == nil {
+		*v = T(numVal)
+		return nil
+	}
+	return fmt.Errorf("%sValue: could not unmarshal %s", kind, string(data))
+}
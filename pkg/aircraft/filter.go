@@ -0,0 +1,58 @@
+package aircraft
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CategoryFilter matches aircraft by species, wake turbulence category and engine type.
+// An empty list matches every aircraft for that attribute.
+type CategoryFilter struct {
+	Species []SpeciesValue
+	WTC     []WTCValue
+	EngType []EngTypeValue
+}
+
+// NewCategoryFilter parses configured category names into a CategoryFilter
+func NewCategoryFilter(species, wtc, engTypes []string) (*CategoryFilter, error) {
+	f := &CategoryFilter{}
+	for _, s := range species {
+		v, err := ParseSpecies(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid species filter: %w", err)
+		}
+		f.Species = append(f.Species, v)
+	}
+	for _, s := range wtc {
+		v, err := ParseWTC(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wtc filter: %w", err)
+		}
+		f.WTC = append(f.WTC, v)
+	}
+	for _, s := range engTypes {
+		v, err := ParseEngType(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid engine_type filter: %w", err)
+		}
+		f.EngType = append(f.EngType, v)
+	}
+	return f, nil
+}
+
+// Matches reports whether the aircraft satisfies every configured category
+func (f *CategoryFilter) Matches(ac Aircraft) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Species) > 0 && !slices.Contains(f.Species, ac.Species) {
+		return false
+	}
+	if len(f.WTC) > 0 && !slices.Contains(f.WTC, ac.WTC) {
+		return false
+	}
+	if len(f.EngType) > 0 && !slices.Contains(f.EngType, ac.EngType) {
+		return false
+	}
+	return true
+}
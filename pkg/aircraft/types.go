@@ -2,7 +2,6 @@ package aircraft
 
 import (
 	"encoding/json"
-	"fmt"
 	"strconv"
 )
 
@@ -155,83 +154,3 @@ func (s *SqkValue) UnmarshalJSON(data []byte) error {
 	*s = SqkValue(intVal)
 	return nil
 }
-
-// WTCValue handles both string and number formats for the WTC field
-type WTCValue string
-
-// UnmarshalJSON implements custom unmarshaling for WTCValue
-func (w *WTCValue) UnmarshalJSON(data []byte) error {
-	// Try to unmarshal as string first
-	var strVal string
-	if err := json.Unmarshal(data, &strVal); err == nil {
-		*w = WTCValue(strVal)
-		return nil
-	}
-	// If string fails, try as number
-	var numVal int
-	if err := json.Unmarshal(data, &numVal); err == nil {
-		*w = WTCValue(strconv.Itoa(numVal))
-		return nil
-	}
-	return fmt.Errorf("WTCValue: could not unmarshal %s", string(data))
-}
-
-// SpeciesValue handles both string and number formats for the Species field
-type SpeciesValue string
-
-// UnmarshalJSON implements custom unmarshaling for SpeciesValue
-func (s *SpeciesValue) UnmarshalJSON(data []byte) error {
-	// Try to unmarshal as string first
-	var strVal string
-	if err := json.Unmarshal(data, &strVal); err == nil {
-		*s = SpeciesValue(strVal)
-		return nil
-	}
-	// If string fails, try as number
-	var numVal int
-	if err := json.Unmarshal(data, &numVal); err == nil {
-		*s = SpeciesValue(strconv.Itoa(numVal))
-		return nil
-	}
-	return fmt.Errorf("SpeciesValue: could not unmarshal %s", string(data))
-}
-
-// EngTypeValue handles both string and number formats for the EngType field
-type EngTypeValue string
-
-// UnmarshalJSON implements custom unmarshaling for EngTypeValue
-func (e *EngTypeValue) UnmarshalJSON(data []byte) error {
-	// Try to unmarshal as string first
-	var strVal string
-	if err := json.Unmarshal(data, &strVal); err == nil {
-		*e = EngTypeValue(strVal)
-		return nil
-	}
-	// If string fails, try as number
-	var numVal int
-	if err := json.Unmarshal(data, &numVal); err == nil {
-		*e = EngTypeValue(strconv.Itoa(numVal))
-		return nil
-	}
-	return fmt.Errorf("EngTypeValue: could not unmarshal %s", string(data))
-}
-
-// EngMountValue handles both string and number formats for the EngMount field
-type EngMountValue string
-
-// UnmarshalJSON implements custom unmarshaling for EngMountValue
-func (e *EngMountValue) UnmarshalJSON(data []byte) error {
-	// Try to unmarshal as string first
-	var strVal string
-	if err := json.Unmarshal(data, &strVal); err == nil {
-		*e = EngMountValue(strVal)
-		return nil
-	}
-	// If string fails, try as number
-	var numVal int
-	if err := json.Unmarshal(data, &numVal); err == nil {
-		*e = EngMountValue(strconv.Itoa(numVal))
-		return nil
-	}
-	return fmt.Errorf("EngMountValue: could not unmarshal %s", string(data))
-}
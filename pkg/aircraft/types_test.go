@@ -90,22 +90,32 @@ var _ = Describe("Aircraft Types", func() {
 	})
 
 	Describe("WTCValue", func() {
-		It("should unmarshal string value correctly", func() {
+		It("should unmarshal ICAO letter correctly", func() {
 			data := []byte(`"M"`)
 			var wtc WTCValue
 			err := json.Unmarshal(data, &wtc)
 
 			Expect(err).To(BeNil())
-			Expect(string(wtc)).To(Equal("M"))
+			Expect(wtc).To(Equal(WTCMedium))
 		})
 
-		It("should unmarshal numeric value as string", func() {
-			data := []byte(`1`)
+		It("should unmarshal numeric value correctly", func() {
+			data := []byte(`3`)
+			var wtc WTCValue
+			err := json.Unmarshal(data, &wtc)
+
+			Expect(err).To(BeNil())
+			Expect(wtc).To(Equal(WTCHeavy))
+			Expect(wtc.String()).To(Equal("Heavy"))
+		})
+
+		It("should unmarshal numeric string correctly", func() {
+			data := []byte(`"1"`)
 			var wtc WTCValue
 			err := json.Unmarshal(data, &wtc)
 
 			Expect(err).To(BeNil())
-			Expect(string(wtc)).To(Equal("1"))
+			Expect(wtc).To(Equal(WTCLight))
 		})
 
 		It("should handle empty string", func() {
@@ -114,7 +124,36 @@ var _ = Describe("Aircraft Types", func() {
 			err := json.Unmarshal(data, &wtc)
 
 			Expect(err).To(BeNil())
-			Expect(string(wtc)).To(Equal(""))
+			Expect(wtc).To(Equal(WTCNone))
+		})
+
+		It("should round-trip through JSON by name", func() {
+			data, err := json.Marshal(WTCHeavy)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`"Heavy"`))
+
+			var wtc WTCValue
+			Expect(json.Unmarshal(data, &wtc)).To(Succeed())
+			Expect(wtc).To(Equal(WTCHeavy))
+		})
+
+		It("should keep unknown numeric values", func() {
+			var wtc WTCValue
+			Expect(json.Unmarshal([]byte(`9`), &wtc)).To(Succeed())
+			Expect(wtc.String()).To(Equal("WTC(9)"))
+
+			data, err := json.Marshal(wtc)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("9"))
+		})
+
+		It("should map unknown names to None", func() {
+			data := []byte(`"Enormous"`)
+			wtc := WTCHeavy
+			err := json.Unmarshal(data, &wtc)
+
+			Expect(err).To(BeNil())
+			Expect(wtc).To(Equal(WTCNone))
 		})
 
 		It("should return error for invalid JSON", func() {
@@ -127,22 +166,23 @@ var _ = Describe("Aircraft Types", func() {
 	})
 
 	Describe("SpeciesValue", func() {
-		It("should unmarshal string value correctly", func() {
+		It("should unmarshal name case-insensitively", func() {
 			data := []byte(`"Landplane"`)
 			var species SpeciesValue
 			err := json.Unmarshal(data, &species)
 
 			Expect(err).To(BeNil())
-			Expect(string(species)).To(Equal("Landplane"))
+			Expect(species).To(Equal(SpeciesLandPlane))
 		})
 
-		It("should unmarshal numeric value as string", func() {
-			data := []byte(`2`)
+		It("should unmarshal numeric value correctly", func() {
+			data := []byte(`4`)
 			var species SpeciesValue
 			err := json.Unmarshal(data, &species)
 
 			Expect(err).To(BeNil())
-			Expect(string(species)).To(Equal("2"))
+			Expect(species).To(Equal(SpeciesHelicopter))
+			Expect(species.String()).To(Equal("Helicopter"))
 		})
 
 		It("should handle empty string", func() {
@@ -151,7 +191,17 @@ var _ = Describe("Aircraft Types", func() {
 			err := json.Unmarshal(data, &species)
 
 			Expect(err).To(BeNil())
-			Expect(string(species)).To(Equal(""))
+			Expect(species).To(Equal(SpeciesNone))
+		})
+
+		It("should round-trip through JSON by name", func() {
+			data, err := json.Marshal(SpeciesGyrocopter)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`"Gyrocopter"`))
+
+			var species SpeciesValue
+			Expect(json.Unmarshal(data, &species)).To(Succeed())
+			Expect(species).To(Equal(SpeciesGyrocopter))
 		})
 
 		It("should return error for invalid JSON", func() {
@@ -164,22 +214,30 @@ var _ = Describe("Aircraft Types", func() {
 	})
 
 	Describe("EngTypeValue", func() {
-		It("should unmarshal string value correctly", func() {
+		It("should unmarshal name correctly", func() {
 			data := []byte(`"Jet"`)
 			var engType EngTypeValue
 			err := json.Unmarshal(data, &engType)
 
 			Expect(err).To(BeNil())
-			Expect(string(engType)).To(Equal("Jet"))
+			Expect(engType).To(Equal(EngTypeJet))
 		})
 
-		It("should unmarshal numeric value as string", func() {
+		It("should unmarshal numeric value correctly", func() {
 			data := []byte(`1`)
 			var engType EngTypeValue
 			err := json.Unmarshal(data, &engType)
 
 			Expect(err).To(BeNil())
-			Expect(string(engType)).To(Equal("1"))
+			Expect(engType).To(Equal(EngTypePiston))
+			Expect(engType.String()).To(Equal("Piston"))
+		})
+
+		It("should accept turboprop as an alias", func() {
+			engType, err := ParseEngType("Turboprop")
+
+			Expect(err).To(BeNil())
+			Expect(engType).To(Equal(EngTypeTurbo))
 		})
 
 		It("should handle empty string", func() {
@@ -188,7 +246,7 @@ var _ = Describe("Aircraft Types", func() {
 			err := json.Unmarshal(data, &engType)
 
 			Expect(err).To(BeNil())
-			Expect(string(engType)).To(Equal(""))
+			Expect(engType).To(Equal(EngTypeNone))
 		})
 
 		It("should return error for invalid JSON", func() {
@@ -201,22 +259,23 @@ var _ = Describe("Aircraft Types", func() {
 	})
 
 	Describe("EngMountValue", func() {
-		It("should unmarshal string value correctly", func() {
+		It("should unmarshal name correctly", func() {
 			data := []byte(`"Wing"`)
 			var engMount EngMountValue
 			err := json.Unmarshal(data, &engMount)
 
 			Expect(err).To(BeNil())
-			Expect(string(engMount)).To(Equal("Wing"))
+			Expect(engMount).To(Equal(EngMountWing))
 		})
 
-		It("should unmarshal numeric value as string", func() {
+		It("should unmarshal numeric value correctly", func() {
 			data := []byte(`1`)
 			var engMount EngMountValue
 			err := json.Unmarshal(data, &engMount)
 
 			Expect(err).To(BeNil())
-			Expect(string(engMount)).To(Equal("1"))
+			Expect(engMount).To(Equal(EngMountAft))
+			Expect(engMount.String()).To(Equal("Aft"))
 		})
 
 		It("should handle empty string", func() {
@@ -225,7 +284,7 @@ var _ = Describe("Aircraft Types", func() {
 			err := json.Unmarshal(data, &engMount)
 
 			Expect(err).To(BeNil())
-			Expect(string(engMount)).To(Equal(""))
+			Expect(engMount).To(Equal(EngMountUnknown))
 		})
 
 		It("should return error for invalid JSON", func() {
@@ -237,6 +296,34 @@ var _ = Describe("Aircraft Types", func() {
 		})
 	})
 
+	Describe("CategoryFilter", func() {
+		It("should match everything when empty", func() {
+			f, err := NewCategoryFilter(nil, nil, nil)
+			Expect(err).To(BeNil())
+			Expect(f.Matches(Aircraft{Species: SpeciesLandPlane})).To(BeTrue())
+		})
+
+		It("should only match configured species", func() {
+			f, err := NewCategoryFilter([]string{"helicopter"}, nil, nil)
+			Expect(err).To(BeNil())
+			Expect(f.Matches(Aircraft{Species: SpeciesHelicopter})).To(BeTrue())
+			Expect(f.Matches(Aircraft{Species: SpeciesLandPlane})).To(BeFalse())
+		})
+
+		It("should require every configured attribute to match", func() {
+			f, err := NewCategoryFilter(nil, []string{"Heavy"}, []string{"Jet"})
+			Expect(err).To(BeNil())
+			Expect(f.Matches(Aircraft{WTC: WTCHeavy, EngType: EngTypeJet})).To(BeTrue())
+			Expect(f.Matches(Aircraft{WTC: WTCHeavy, EngType: EngTypeTurbo})).To(BeFalse())
+		})
+
+		It("should reject unknown names", func() {
+			_, err := NewCategoryFilter([]string{"Zeppelin"}, nil, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid species filter"))
+		})
+	})
+
 	Describe("Aircraft struct", func() {
 		It("should marshal and unmarshal correctly", func() {
 			aircraft := Aircraft{
@@ -333,6 +420,23 @@ var _ = Describe("Aircraft Types", func() {
 			Expect(len(unmarshaled.Aircraft)).To(Equal(len(aircraftList.Aircraft)))
 			Expect(len(unmarshaled.Feeds)).To(Equal(len(aircraftList.Feeds)))
 		})
+
+		It("should keep every aircraft when one has unknown attributes", func() {
+			data := []byte(`{"acList":[
+				{"Id":1,"Icao":"ABCD12","WTC":"J","Species":"Balloon","EngType":"Rocket","EngMount":"Tail"},
+				{"Id":2,"Icao":"EFGH56","WTC":"H","Species":"L","EngType":"J","EngMount":"Wing"}
+			]}`)
+
+			var list AircraftList
+			Expect(json.Unmarshal(data, &list)).To(Succeed())
+			Expect(list.Aircraft).To(HaveLen(2))
+			Expect(list.Aircraft[0].WTC).To(Equal(WTCNone))
+			Expect(list.Aircraft[0].Species).To(Equal(SpeciesNone))
+			Expect(list.Aircraft[0].EngType).To(Equal(EngTypeNone))
+			Expect(list.Aircraft[0].EngMount).To(Equal(EngMountUnknown))
+			Expect(list.Aircraft[1].WTC).To(Equal(WTCHeavy))
+			Expect(list.Aircraft[1].EngMount).To(Equal(EngMountWing))
+		})
 	})
 
 	Describe("Feed struct", func() {
@@ -11,7 +11,7 @@ import (
 	"github.com/gen2brain/beeep"
 	"github.com/getlantern/systray"
 	"github.com/lyarwood/godar/pkg/config"
-	"github.com/lyarwood/godar/pkg/fetch"
+	"github.com/lyarwood/godar/pkg/events"
 	"github.com/lyarwood/godar/pkg/monitor"
 	"go.uber.org/zap"
 )
@@ -147,33 +147,18 @@ func (a *Applet) toggleMonitoring() {
 		systray.SetTooltip("Aircraft Monitor - Stopped")
 		a.logger.Info("Monitoring stopped via applet")
 	} else {
-		// Start monitoring with custom notifier that updates the applet
-		fetcher := fetch.NewFetcher(a.config.Server.URL, a.logger)
-		fetcher.SetFilters(
-			a.config.Filters.AircraftType,
-			a.config.Filters.MinAltitude,
-			a.config.Filters.MaxAltitude,
-			a.config.Filters.Military,
-			a.config.Filters.Operator,
-			a.config.Filters.FlightNumber,
-		)
-		fetcher.SetLocation(
-			a.config.Location.Latitude,
-			a.config.Location.Longitude,
-			a.config.Location.MaxDistance,
-		)
-		if a.config.Server.Username != "" || a.config.Server.Password != "" {
-			fetcher.SetAuth(a.config.Server.Username, a.config.Server.Password)
-		}
-
-		notifier := NewAppletNotifier(a, a.config.Notification.Enabled, a.config.Notification.Duration, a.logger, a.config.Notification.ViewableDistance, a.config.Notification.PredictionWindow)
-
-		mon, err := monitor.NewMonitorWithDeps(a.config, a.logger, fetcher, notifier)
+		// Start monitoring
+		mon, err := monitor.NewMonitor(a.config, a.logger)
 		if err != nil {
 			a.logger.Error("Failed to create monitor", zap.Error(err))
 			return
 		}
 
+		// Keep the recent aircraft list in step with newly seen aircraft
+		mon.Events().Subscribe(func(e events.Event) {
+			a.AddAircraftDetection(e.Aircraft.Call, e.Aircraft.Type, e.Aircraft.Alt, e.Distance)
+		}, events.FirstSeen)
+
 		if err := mon.Start(); err != nil {
 			a.logger.Error("Failed to start monitor", zap.Error(err))
 			return
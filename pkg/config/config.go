@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/geo"
+	"github.com/lyarwood/godar/pkg/templates"
 	"github.com/spf13/viper"
 )
 
@@ -14,6 +17,7 @@ type Config struct {
 	Location     LocationConfig     `mapstructure:"location"`
 	Monitoring   MonitoringConfig   `mapstructure:"monitoring"`
 	Notification NotificationConfig `mapstructure:"notification"`
+	MQTT         MQTTConfig         `mapstructure:"mqtt"`
 }
 
 // ServerConfig holds server-related configuration
@@ -31,6 +35,14 @@ type FilterConfig struct {
 	Military     bool   `mapstructure:"military"`
 	Operator     string `mapstructure:"operator"`
 	FlightNumber string `mapstructure:"flight_number"`
+	// Category filters, applied locally to each aircraft in the feed
+	Species    []string `mapstructure:"species"`     // e.g. ["Helicopter"], see aircraft.ParseSpecies
+	WTC        []string `mapstructure:"wtc"`         // e.g. ["Heavy"], see aircraft.ParseWTC
+	EngineType []string `mapstructure:"engine_type"` // e.g. ["Jet", "Turbo"], see aircraft.ParseEngType
+	// Line of sight filters, applied locally and only once a location is set
+	MinElevation  float64 `mapstructure:"min_elevation"`   // Degrees above the horizon, e.g. to clear rooftops (0 = no cut-off)
+	MaxSlantRange float64 `mapstructure:"max_slant_range"` // Line of sight distance in km (0 = no limit)
+	VisibleOnly   bool    `mapstructure:"visible_only"`    // Skip aircraft outside the location's horizon and field of view instead of marking them
 }
 
 // LocationConfig holds location-related configuration
@@ -38,13 +50,25 @@ type LocationConfig struct {
 	Latitude    float64 `mapstructure:"latitude"`
 	Longitude   float64 `mapstructure:"longitude"`
 	MaxDistance float64 `mapstructure:"max_distance"`
-	Heading     float64 `mapstructure:"heading"` // Direction user is facing in degrees (0-360, 0=North)
+	Heading     float64 `mapstructure:"heading"`   // Direction user is facing in degrees (0-360, 0=North)
+	Elevation   float64 `mapstructure:"elevation"` // Observer's height above sea level in metres
+	// The part of the sky the observer can see
+	Horizon     []HorizonPoint `mapstructure:"horizon"`       // Lowest visible elevation by azimuth, e.g. over buildings and hills
+	HorizonFile string         `mapstructure:"horizon_file"`  // CSV of azimuth,elevation pairs from a horizon survey, added to horizon
+	FieldOfView float64        `mapstructure:"field_of_view"` // Width of the view centred on heading in degrees, e.g. 90 for ±45° (0 = all round)
+}
+
+// HorizonPoint is the lowest elevation that can be seen in one direction
+type HorizonPoint struct {
+	Azimuth   float64 `mapstructure:"azimuth"`   // Degrees clockwise from north
+	Elevation float64 `mapstructure:"elevation"` // Degrees above the horizontal
 }
 
 // MonitoringConfig holds monitoring-related configuration
 type MonitoringConfig struct {
 	PollInterval time.Duration `mapstructure:"poll_interval"`
 	Debug        bool          `mapstructure:"debug"`
+	Smoothing    bool          `mapstructure:"smoothing"` // Kalman filter positions and dead reckon them to the time of the poll (default: true)
 }
 
 // NotificationConfig holds notification-related configuration
@@ -55,9 +79,297 @@ type NotificationConfig struct {
 	NotifyOnCloserOnly bool          `mapstructure:"notify_on_closer_only"` // Only notify when aircraft get closer
 	ReNotifyAfter      time.Duration `mapstructure:"re_notify_after"`       // Re-notify after this time even if not closer
 	CleanupInterval    time.Duration `mapstructure:"cleanup_interval"`      // How often to clean up old aircraft history
+	OverheadDistance   float64       `mapstructure:"overhead_distance"`     // Distance in km within which an aircraft is considered overhead (default: 2km)
+	Mode               string        `mapstructure:"mode"`                  // "approach" or "closest_approach", see NotifyMode constants (default: approach)
+	Hysteresis         float64       `mapstructure:"hysteresis"`            // Distance in km a trend must move before it counts, smoothing jittery MLAT positions (default: 0.5km)
+	Bands              []BandConfig  `mapstructure:"bands"`                 // Notify as aircraft enter each band instead of whenever they get closer
+	HeadsUp            HeadsUpConfig `mapstructure:"heads_up"`              // Warn ahead of aircraft predicted to pass within viewable_distance
+	Transit            TransitConfig `mapstructure:"transit"`               // Alert when aircraft are predicted to cross the sun or moon
 	// Trajectory prediction options
 	ViewableDistance float64       `mapstructure:"viewable_distance"` // Distance in km within which aircraft is considered viewable (default: 15km)
 	PredictionWindow time.Duration `mapstructure:"prediction_window"` // Only show predictions within this time window (default: 30min)
+	// Message text shared by every backend unless overridden per backend
+	MessageTemplate `mapstructure:",squash"`
+	// Backends
+	Desktop  bool             `mapstructure:"desktop"` // Show desktop popups (default: true), disable when running headless
+	DBus     DBusConfig       `mapstructure:"dbus"`    // Show desktop popups through D-Bus on Linux
+	Webhooks []WebhookConfig  `mapstructure:"webhooks"`
+	Ntfy     []NtfyConfig     `mapstructure:"ntfy"`
+	Gotify   []GotifyConfig   `mapstructure:"gotify"`
+	Slack    []SlackConfig    `mapstructure:"slack"`
+	Discord  []DiscordConfig  `mapstructure:"discord"`
+	Matrix   []MatrixConfig   `mapstructure:"matrix"`
+	Telegram []TelegramConfig `mapstructure:"telegram"`
+	Email    []EmailConfig    `mapstructure:"email"`
+	Exec     []ExecConfig     `mapstructure:"exec"`
+	Voice    VoiceConfig      `mapstructure:"voice"`
+	// Routing
+	Channels []ChannelConfig `mapstructure:"channels"` // Named groups of backends with their own limits
+	Rules    []RuleConfig    `mapstructure:"rules"`    // Route matching detections to channels, every detection goes everywhere without rules
+	// Limits shared by every channel
+	RateLimit  RateLimitConfig  `mapstructure:"rate_limit"`  // Caps the notifications sent by all channels together
+	QuietHours QuietHoursConfig `mapstructure:"quiet_hours"` // Applies to every channel
+	Burst      BurstConfig      `mapstructure:"burst"`
+	// Delivery
+	Queue QueueConfig `mapstructure:"queue"` // Send notifications on a pool of workers instead of the poll loop
+}
+
+// Notification modes deciding when an aircraft is notified
+const (
+	// NotifyModeApproach notifies on first sighting and then as set by notify_on_closer_only and re_notify_after
+	NotifyModeApproach = "approach"
+	// NotifyModeClosestApproach notifies once as an aircraft starts approaching and once as it passes its closest point
+	NotifyModeClosestApproach = "closest_approach"
+)
+
+// HeadsUpConfig schedules an alert ahead of each aircraft's predicted closest approach
+type HeadsUpConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	LeadTime time.Duration `mapstructure:"lead_time"` // How long before the closest approach to alert (default: 2m)
+}
+
+// TransitConfig predicts aircraft crossing in front of the sun or moon as seen from the observer's location
+type TransitConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Bodies        []string      `mapstructure:"bodies"`         // "sun" and/or "moon" (default: both)
+	Window        time.Duration `mapstructure:"window"`         // How far ahead to project each aircraft (default: 5m)
+	MaxSeparation float64       `mapstructure:"max_separation"` // Also alert for near misses within this many arc-minutes of the centre of the disk (default: 0, crossing the disk only)
+}
+
+// BandConfig is a circle around the observer. Aircraft are notified when they cross into a closer band.
+type BandConfig struct {
+	Distance float64  `mapstructure:"distance"` // Radius in km
+	Name     string   `mapstructure:"name"`     // e.g. "overhead" (default: the distance, e.g. "20 km")
+	Priority string   `mapstructure:"priority"` // Raise notifications entering the band to at least this priority
+	Channels []string `mapstructure:"channels"` // Send notifications entering the band to these channels instead of following the rules
+}
+
+// Label returns the band's name, or its distance when it has none
+func (b BandConfig) Label() string {
+	if b.Name != "" {
+		return b.Name
+	}
+	return fmt.Sprintf("%g km", b.Distance)
+}
+
+// ChannelConfig groups backends that share delivery limits and message templates.
+// Backends not listed by any channel belong to the "default" channel, and a backend may be listed by several.
+type ChannelConfig struct {
+	Name        string           `mapstructure:"name"`
+	Backends    []string         `mapstructure:"backends"`     // Backend types, e.g. ["desktop", "ntfy"]
+	MinPriority string           `mapstructure:"min_priority"` // low, normal, high or urgent (default: every priority)
+	RateLimit   RateLimitConfig  `mapstructure:"rate_limit"`
+	QuietHours  QuietHoursConfig `mapstructure:"quiet_hours"`
+
+	MessageTemplate `mapstructure:",squash"` // Overrides the templates of its backends and notification.title and notification.body
+}
+
+// RateLimitConfig is a token bucket limiting how many notifications are sent
+type RateLimitConfig struct {
+	Count  int           `mapstructure:"count"`  // Notifications allowed per period, 0 for no limit
+	Period time.Duration `mapstructure:"period"` // (default: 1h)
+	Burst  int           `mapstructure:"burst"`  // Notifications that may be sent back to back (default: count)
+}
+
+// QuietHoursConfig is a daily window of local time during which only high priority notifications are sent
+type QuietHoursConfig struct {
+	Start    string `mapstructure:"start"`    // e.g. "22:00"
+	End      string `mapstructure:"end"`      // e.g. "07:00", earlier than start to span midnight
+	Override string `mapstructure:"override"` // Priority that is still sent during quiet hours, e.g. "urgent" (default: none)
+}
+
+// BurstConfig collapses many notifications raised by the same poll into one summary
+type BurstConfig struct {
+	Threshold int `mapstructure:"threshold"` // Notifications per channel and poll that become a summary, 0 disables
+}
+
+// QueueConfig sizes the worker pool that delivers notifications.
+// Notifications for the same aircraft are always sent in order by the same worker.
+type QueueConfig struct {
+	Workers int `mapstructure:"workers"` // 0 sends on the poll loop (default: 4)
+	Size    int `mapstructure:"size"`    // Notifications waiting per worker before the oldest is dropped (default: 100)
+}
+
+// RuleConfig routes the detections it matches to one or more channels.
+// Every criterion that is set must match, a rule without criteria matches everything.
+type RuleConfig struct {
+	Name        string   `mapstructure:"name"`
+	Military    bool     `mapstructure:"military"`     // Only military aircraft
+	Emergency   bool     `mapstructure:"emergency"`    // Only aircraft squawking 7500, 7600 or 7700
+	Watchlist   []string `mapstructure:"watchlist"`    // ICAO, callsign or registration patterns, e.g. ["G-EUU*", "43C*"]
+	MinDistance float64  `mapstructure:"min_distance"` // Distance band in km
+	MaxDistance float64  `mapstructure:"max_distance"` // 0 for no limit
+	Priority    string   `mapstructure:"priority"`     // Raise matching detections to at least this priority
+	Channels    []string `mapstructure:"channels"`
+}
+
+// MessageTemplate holds Go text/templates overriding the notification title and body.
+// Empty templates keep the built-in text.
+type MessageTemplate struct {
+	Title string `mapstructure:"title"` // e.g. "{{.Callsign}} {{ft .Aircraft.Alt}}"
+	Body  string `mapstructure:"body"`  // Replaces the built-in lines, see README for the available fields
+}
+
+// Or returns the template with any empty field taken from fallback
+func (t MessageTemplate) Or(fallback MessageTemplate) MessageTemplate {
+	if t.Title == "" {
+		t.Title = fallback.Title
+	}
+	if t.Body == "" {
+		t.Body = fallback.Body
+	}
+	return t
+}
+
+// DBusConfig holds configuration for native org.freedesktop.Notifications popups
+type DBusConfig struct {
+	Enabled bool          `mapstructure:"enabled"`  // Use D-Bus instead of the portable desktop popups
+	Address string        `mapstructure:"address"`  // Bus address (default: the session bus)
+	AppName string        `mapstructure:"app_name"` // Application name shown by the notification server (default: godar)
+	MuteFor time.Duration `mapstructure:"mute_for"` // How long "Mute this aircraft" silences an aircraft, 0 hides the button (default: 1h)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// WebhookConfig holds configuration for an HTTP endpoint that receives detections
+type WebhookConfig struct {
+	Name     string            `mapstructure:"name"` // Used in logs and errors (default: the url's host)
+	URL      string            `mapstructure:"url"`
+	Template string            `mapstructure:"template"` // Go text/template rendering the JSON body (default: the detection as JSON)
+	Headers  map[string]string `mapstructure:"headers"`  // Extra request headers, e.g. Authorization
+	Secret   string            `mapstructure:"secret"`   // Signs the body with HMAC-SHA256 in the X-Godar-Signature header
+	Timeout  time.Duration     `mapstructure:"timeout"`  // Request timeout (default: 10s)
+}
+
+// NtfyConfig holds configuration for an ntfy push notification topic
+type NtfyConfig struct {
+	URL       string        `mapstructure:"url"`        // Topic URL, e.g. https://ntfy.sh/my-godar-alerts
+	Token     string        `mapstructure:"token"`      // Optional access token
+	Username  string        `mapstructure:"username"`   // Optional username, used when no token is set
+	Password  string        `mapstructure:"password"`   // Optional password
+	Priority  int           `mapstructure:"priority"`   // 1 (min) to 5 (max) (default: server default)
+	Tags      []string      `mapstructure:"tags"`       // Tags or emoji shortcodes, e.g. ["airplane"]
+	Click     string        `mapstructure:"click"`      // URL opened when the notification is tapped, may be a Go template
+	SkipImage bool          `mapstructure:"skip_image"` // Don't attach the aircraft image
+	Timeout   time.Duration `mapstructure:"timeout"`    // Request timeout (default: 10s)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// GotifyConfig holds configuration for a Gotify server
+type GotifyConfig struct {
+	URL       string        `mapstructure:"url"`        // Server URL, e.g. https://gotify.example.com
+	Token     string        `mapstructure:"token"`      // Application token
+	Priority  int           `mapstructure:"priority"`   // Message priority (default: the application's default)
+	Markdown  bool          `mapstructure:"markdown"`   // Render the message as markdown with the aircraft image inline
+	Click     string        `mapstructure:"click"`      // URL opened when the notification is tapped, may be a Go template
+	SkipImage bool          `mapstructure:"skip_image"` // Don't include the aircraft image
+	Timeout   time.Duration `mapstructure:"timeout"`    // Request timeout (default: 10s)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// SlackConfig holds configuration for a Slack incoming webhook
+type SlackConfig struct {
+	URL       string        `mapstructure:"url"`        // Incoming webhook URL
+	SkipImage bool          `mapstructure:"skip_image"` // Don't include the aircraft image
+	Timeout   time.Duration `mapstructure:"timeout"`    // Request timeout (default: 10s)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// DiscordConfig holds configuration for a Discord webhook
+type DiscordConfig struct {
+	URL       string        `mapstructure:"url"`        // Webhook URL
+	Username  string        `mapstructure:"username"`   // Overrides the webhook's display name
+	SkipImage bool          `mapstructure:"skip_image"` // Don't include the aircraft thumbnail
+	Timeout   time.Duration `mapstructure:"timeout"`    // Request timeout (default: 10s)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// MatrixConfig holds configuration for posting to a Matrix room
+type MatrixConfig struct {
+	Homeserver  string        `mapstructure:"homeserver"`   // Homeserver base URL, e.g. https://matrix.example.com
+	AccessToken string        `mapstructure:"access_token"` // Access token of the posting user
+	RoomID      string        `mapstructure:"room_id"`      // Room ID, e.g. !abc123:example.com
+	Timeout     time.Duration `mapstructure:"timeout"`      // Request timeout (default: 10s)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// TelegramConfig holds configuration for a Telegram bot
+type TelegramConfig struct {
+	APIURL    string        `mapstructure:"api_url"`    // Bot API base URL (default: https://api.telegram.org)
+	Token     string        `mapstructure:"token"`      // Bot token
+	ChatID    string        `mapstructure:"chat_id"`    // Chat, group or @channel to post to
+	SkipImage bool          `mapstructure:"skip_image"` // Send text only, without the aircraft photo
+	Timeout   time.Duration `mapstructure:"timeout"`    // Request timeout (default: 10s)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// EmailConfig holds configuration for sending notifications over SMTP
+type EmailConfig struct {
+	Host               string        `mapstructure:"host"`                 // SMTP server host name
+	Port               int           `mapstructure:"port"`                 // SMTP server port (default: 587, or 465 for implicit TLS)
+	TLS                string        `mapstructure:"tls"`                  // starttls (default), implicit or none
+	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify"` // Skip server certificate verification
+	Username           string        `mapstructure:"username"`             // Optional SMTP username
+	Password           string        `mapstructure:"password"`             // Optional SMTP password
+	From               string        `mapstructure:"from"`                 // Sender address
+	To                 []string      `mapstructure:"to"`                   // Recipient addresses
+	Digest             time.Duration `mapstructure:"digest"`               // Batch detections into one email per window (0 = send each immediately)
+	SkipImage          bool          `mapstructure:"skip_image"`           // Don't embed the aircraft image
+	Timeout            time.Duration `mapstructure:"timeout"`              // Connection timeout (default: 10s)
+
+	MessageTemplate `mapstructure:",squash"` // Overrides notification.title and notification.body
+}
+
+// ExecConfig holds configuration for an external command run on each notification
+type ExecConfig struct {
+	Name          string            `mapstructure:"name"`           // Used in logs (default: the command)
+	Command       string            `mapstructure:"command"`        // Executable to run
+	Args          []string          `mapstructure:"args"`           // Arguments passed to the command
+	Env           map[string]string `mapstructure:"env"`            // Extra environment variables
+	Timeout       time.Duration     `mapstructure:"timeout"`        // Kill the command after this long (default: 30s)
+	MaxConcurrent int               `mapstructure:"max_concurrent"` // Runs allowed at once, further notifications are skipped (default: 1)
+}
+
+// VoiceConfig holds configuration for spoken callouts through a text-to-speech command
+type VoiceConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	Command            string        `mapstructure:"command"`             // TTS command (default: espeak)
+	Args               []string      `mapstructure:"args"`                // Arguments, {text} is replaced by the callout, otherwise it is written to stdin
+	Template           string        `mapstructure:"template"`            // Go text/template rendering the phrase
+	TransitionAltitude int           `mapstructure:"transition_altitude"` // Altitude in feet above which flight levels are spoken (default: 6000)
+	QueueSize          int           `mapstructure:"queue_size"`          // Callouts waiting to be spoken before new ones are dropped (default: 10)
+	Timeout            time.Duration `mapstructure:"timeout"`             // Kill the command after this long (default: 30s)
+}
+
+// MQTTConfig holds configuration for publishing aircraft state and alerts to an MQTT broker
+type MQTTConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Broker      string `mapstructure:"broker"`       // e.g. tcp://localhost:1883 or ssl://broker:8883
+	ClientID    string `mapstructure:"client_id"`    // (default: godar)
+	Username    string `mapstructure:"username"`     // Optional broker username
+	Password    string `mapstructure:"password"`     // Optional broker password
+	TopicPrefix string `mapstructure:"topic_prefix"` // Root of every topic (default: godar)
+	QoS         int    `mapstructure:"qos"`          // 0, 1 or 2 (default: 0)
+	// TLS options, used for ssl:// and tls:// brokers
+	CACert             string `mapstructure:"ca_cert"`              // PEM file used to verify the broker
+	ClientCert         string `mapstructure:"client_cert"`          // PEM client certificate for mutual TLS
+	ClientKey          string `mapstructure:"client_key"`           // PEM client key for mutual TLS
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // Skip broker certificate verification
+
+	HomeAssistant HomeAssistantConfig `mapstructure:"home_assistant"`
+}
+
+// HomeAssistantConfig holds configuration for Home Assistant MQTT discovery
+type HomeAssistantConfig struct {
+	Enabled         bool   `mapstructure:"enabled"`
+	DiscoveryPrefix string `mapstructure:"discovery_prefix"` // Home Assistant discovery prefix (default: homeassistant)
+	DeviceName      string `mapstructure:"device_name"`      // Name of the device the sensors belong to (default: Godar)
 }
 
 // Load loads configuration from Viper (which is already set up by Cobra)
@@ -101,19 +413,56 @@ func setDefaults() {
 	viper.SetDefault("filters.military", false)
 	viper.SetDefault("filters.operator", "")
 	viper.SetDefault("filters.flight_number", "")
+	viper.SetDefault("filters.species", []string{})
+	viper.SetDefault("filters.wtc", []string{})
+	viper.SetDefault("filters.engine_type", []string{})
+	viper.SetDefault("filters.min_elevation", 0.0)
+	viper.SetDefault("filters.max_slant_range", 0.0)
+	viper.SetDefault("filters.visible_only", false)
 	viper.SetDefault("location.latitude", 0.0)
 	viper.SetDefault("location.longitude", 0.0)
 	viper.SetDefault("location.max_distance", 0.0)
 	viper.SetDefault("location.heading", 0.0)
+	viper.SetDefault("location.elevation", 0.0)
+	viper.SetDefault("location.horizon_file", "")
+	viper.SetDefault("location.field_of_view", 0.0)
 	viper.SetDefault("monitoring.poll_interval", "60s")
 	viper.SetDefault("monitoring.debug", false)
+	viper.SetDefault("monitoring.smoothing", true)
 	viper.SetDefault("notification.enabled", false)
 	viper.SetDefault("notification.duration", 30*time.Second)
+	viper.SetDefault("notification.desktop", true)
 	viper.SetDefault("notification.notify_on_closer_only", true)
 	viper.SetDefault("notification.re_notify_after", 0*time.Second)
 	viper.SetDefault("notification.cleanup_interval", 0*time.Second)
+	viper.SetDefault("notification.overhead_distance", 2.0)
+	viper.SetDefault("notification.mode", NotifyModeApproach)
+	viper.SetDefault("notification.hysteresis", 0.5)
+	viper.SetDefault("notification.heads_up.enabled", false)
+	viper.SetDefault("notification.heads_up.lead_time", 2*time.Minute)
+	viper.SetDefault("notification.transit.enabled", false)
+	viper.SetDefault("notification.transit.bodies", []string{"sun", "moon"})
+	viper.SetDefault("notification.transit.window", 5*time.Minute)
+	viper.SetDefault("notification.transit.max_separation", 0.0)
 	viper.SetDefault("notification.viewable_distance", 15.0)
 	viper.SetDefault("notification.prediction_window", 30*time.Minute)
+	viper.SetDefault("notification.queue.workers", 4)
+	viper.SetDefault("notification.queue.size", 100)
+	viper.SetDefault("notification.dbus.enabled", false)
+	viper.SetDefault("notification.dbus.app_name", "godar")
+	viper.SetDefault("notification.dbus.mute_for", time.Hour)
+	viper.SetDefault("notification.voice.enabled", false)
+	viper.SetDefault("notification.voice.command", "espeak")
+	viper.SetDefault("notification.voice.transition_altitude", 6000)
+	viper.SetDefault("notification.voice.queue_size", 10)
+	viper.SetDefault("mqtt.enabled", false)
+	viper.SetDefault("mqtt.broker", "")
+	viper.SetDefault("mqtt.client_id", "godar")
+	viper.SetDefault("mqtt.topic_prefix", "godar")
+	viper.SetDefault("mqtt.qos", 0)
+	viper.SetDefault("mqtt.home_assistant.enabled", false)
+	viper.SetDefault("mqtt.home_assistant.discovery_prefix", "homeassistant")
+	viper.SetDefault("mqtt.home_assistant.device_name", "Godar")
 }
 
 // validateConfig validates the configuration
@@ -128,6 +477,10 @@ func validateConfig(config *Config) error {
 		}
 	}
 
+	if _, err := aircraft.NewCategoryFilter(config.Filters.Species, config.Filters.WTC, config.Filters.EngineType); err != nil {
+		return err
+	}
+
 	if config.Location.Latitude != 0.0 || config.Location.Longitude != 0.0 {
 		if config.Location.Latitude < -90 || config.Location.Latitude > 90 {
 			return fmt.Errorf("latitude must be between -90 and 90")
@@ -137,9 +490,172 @@ func validateConfig(config *Config) error {
 		}
 	}
 
+	if config.Filters.MinElevation < -90 || config.Filters.MinElevation >= 90 {
+		return fmt.Errorf("min_elevation must be between -90 and 90")
+	}
+	if config.Filters.MaxSlantRange < 0 {
+		return fmt.Errorf("max_slant_range cannot be negative")
+	}
+
 	if config.Location.Heading < 0 || config.Location.Heading >= 360 {
 		return fmt.Errorf("heading must be between 0 and 359")
 	}
+	if config.Location.FieldOfView < 0 || config.Location.FieldOfView > 360 {
+		return fmt.Errorf("field_of_view must be between 0 and 360")
+	}
+	for i, point := range config.Location.Horizon {
+		if point.Azimuth < 0 || point.Azimuth >= 360 {
+			return fmt.Errorf("horizon[%d]: azimuth must be between 0 and 359", i)
+		}
+		if point.Elevation < -90 || point.Elevation > 90 {
+			return fmt.Errorf("horizon[%d]: elevation must be between -90 and 90", i)
+		}
+	}
+
+	switch config.Notification.Mode {
+	case "", NotifyModeApproach, NotifyModeClosestApproach:
+	default:
+		return fmt.Errorf("notification.mode must be %q or %q", NotifyModeApproach, NotifyModeClosestApproach)
+	}
+	if config.Notification.Hysteresis < 0 {
+		return fmt.Errorf("notification.hysteresis cannot be negative")
+	}
+	if config.Notification.HeadsUp.LeadTime < 0 {
+		return fmt.Errorf("notification.heads_up.lead_time cannot be negative")
+	}
+	if err := validateTransit(config.Notification.Transit); err != nil {
+		return err
+	}
+
+	if err := validateMessageTemplate("notification", config.Notification.MessageTemplate); err != nil {
+		return err
+	}
+
+	if config.Notification.DBus.MuteFor < 0 {
+		return fmt.Errorf("notification.dbus.mute_for cannot be negative")
+	}
+	if err := validateMessageTemplate("notification.dbus", config.Notification.DBus.MessageTemplate); err != nil {
+		return err
+	}
+
+	for i, webhook := range config.Notification.Webhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("notification.webhooks[%d]: url is required", i)
+		}
+		if err := templates.Validate("webhook", webhook.Template, templates.SampleDetection()); err != nil {
+			return fmt.Errorf("notification.webhooks[%d]: invalid template: %w", i, err)
+		}
+	}
+
+	for i, ntfy := range config.Notification.Ntfy {
+		if ntfy.URL == "" {
+			return fmt.Errorf("notification.ntfy[%d]: url is required", i)
+		}
+		if ntfy.Priority < 0 || ntfy.Priority > 5 {
+			return fmt.Errorf("notification.ntfy[%d]: priority must be between 1 and 5", i)
+		}
+		if err := templates.Validate("click", ntfy.Click, templates.SampleDetection()); err != nil {
+			return fmt.Errorf("notification.ntfy[%d]: invalid click template: %w", i, err)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.ntfy[%d]", i), ntfy.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
+	for i, gotify := range config.Notification.Gotify {
+		if gotify.URL == "" {
+			return fmt.Errorf("notification.gotify[%d]: url is required", i)
+		}
+		if gotify.Token == "" {
+			return fmt.Errorf("notification.gotify[%d]: token is required", i)
+		}
+		if err := templates.Validate("click", gotify.Click, templates.SampleDetection()); err != nil {
+			return fmt.Errorf("notification.gotify[%d]: invalid click template: %w", i, err)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.gotify[%d]", i), gotify.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
+	for i, slack := range config.Notification.Slack {
+		if slack.URL == "" {
+			return fmt.Errorf("notification.slack[%d]: url is required", i)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.slack[%d]", i), slack.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
+	for i, discord := range config.Notification.Discord {
+		if discord.URL == "" {
+			return fmt.Errorf("notification.discord[%d]: url is required", i)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.discord[%d]", i), discord.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
+	for i, matrix := range config.Notification.Matrix {
+		if matrix.Homeserver == "" || matrix.AccessToken == "" || matrix.RoomID == "" {
+			return fmt.Errorf("notification.matrix[%d]: homeserver, access_token and room_id are required", i)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.matrix[%d]", i), matrix.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
+	for i, telegram := range config.Notification.Telegram {
+		if telegram.Token == "" || telegram.ChatID == "" {
+			return fmt.Errorf("notification.telegram[%d]: token and chat_id are required", i)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.telegram[%d]", i), telegram.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
+	for i, email := range config.Notification.Email {
+		if email.Host == "" || email.From == "" || len(email.To) == 0 {
+			return fmt.Errorf("notification.email[%d]: host, from and to are required", i)
+		}
+		switch email.TLS {
+		case "", "starttls", "implicit", "none":
+		default:
+			return fmt.Errorf("notification.email[%d]: tls must be starttls, implicit or none", i)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.email[%d]", i), email.MessageTemplate); err != nil {
+			return err
+		}
+	}
+
+	for i, hook := range config.Notification.Exec {
+		if hook.Command == "" {
+			return fmt.Errorf("notification.exec[%d]: command is required", i)
+		}
+		if hook.MaxConcurrent < 0 {
+			return fmt.Errorf("notification.exec[%d]: max_concurrent cannot be negative", i)
+		}
+	}
+
+	if config.Notification.Voice.Enabled {
+		transitionAltitude := config.Notification.Voice.TransitionAltitude
+		if err := templates.Validate("voice", config.Notification.Voice.Template, templates.SampleDetection(), templates.VoiceFuncs(transitionAltitude)); err != nil {
+			return fmt.Errorf("notification.voice: invalid template: %w", err)
+		}
+	}
+
+	if err := validateRouting(config.Notification); err != nil {
+		return err
+	}
+
+	if config.MQTT.Enabled && config.MQTT.Broker == "" {
+		return fmt.Errorf("mqtt.broker is required when mqtt is enabled")
+	}
+	if config.MQTT.QoS < 0 || config.MQTT.QoS > 2 {
+		return fmt.Errorf("mqtt.qos must be 0, 1 or 2")
+	}
+	if config.MQTT.HomeAssistant.Enabled && !config.MQTT.Enabled {
+		return fmt.Errorf("mqtt.home_assistant requires mqtt to be enabled")
+	}
 
 	if config.Monitoring.PollInterval < time.Second {
 		return fmt.Errorf("poll_interval must be at least 1 second")
@@ -148,6 +664,146 @@ func validateConfig(config *Config) error {
 	return nil
 }
 
+// validateRouting checks that channels, bands, limits and the delivery queue are well formed and rules and bands
+// only refer to channels that exist
+func validateRouting(cfg NotificationConfig) error {
+	channels := map[string]bool{"default": true}
+	for i, channel := range cfg.Channels {
+		if channel.Name == "" {
+			return fmt.Errorf("notification.channels[%d]: name is required", i)
+		}
+		if err := validatePriority(channel.MinPriority); err != nil {
+			return fmt.Errorf("notification.channels[%d]: min_priority: %w", i, err)
+		}
+		if err := validateRateLimit(channel.RateLimit); err != nil {
+			return fmt.Errorf("notification.channels[%d]: %w", i, err)
+		}
+		if err := validateQuietHours(channel.QuietHours); err != nil {
+			return fmt.Errorf("notification.channels[%d]: %w", i, err)
+		}
+		if err := validateMessageTemplate(fmt.Sprintf("notification.channels[%d]", i), channel.MessageTemplate); err != nil {
+			return err
+		}
+		channels[channel.Name] = true
+	}
+
+	for i, rule := range cfg.Rules {
+		if len(rule.Channels) == 0 {
+			return fmt.Errorf("notification.rules[%d]: at least one channel is required", i)
+		}
+		for _, name := range rule.Channels {
+			if !channels[name] {
+				return fmt.Errorf("notification.rules[%d]: unknown channel %q", i, name)
+			}
+		}
+		if err := validatePriority(rule.Priority); err != nil {
+			return fmt.Errorf("notification.rules[%d]: priority: %w", i, err)
+		}
+		if rule.MaxDistance > 0 && rule.MinDistance > rule.MaxDistance {
+			return fmt.Errorf("notification.rules[%d]: min_distance cannot be greater than max_distance", i)
+		}
+	}
+
+	labels := map[string]bool{}
+	for i, band := range cfg.Bands {
+		if band.Distance <= 0 {
+			return fmt.Errorf("notification.bands[%d]: distance must be greater than 0", i)
+		}
+		if labels[band.Label()] {
+			return fmt.Errorf("notification.bands[%d]: band %q is defined more than once", i, band.Label())
+		}
+		labels[band.Label()] = true
+		if err := validatePriority(band.Priority); err != nil {
+			return fmt.Errorf("notification.bands[%d]: priority: %w", i, err)
+		}
+		for _, name := range band.Channels {
+			if !channels[name] {
+				return fmt.Errorf("notification.bands[%d]: unknown channel %q", i, name)
+			}
+		}
+	}
+	if len(cfg.Bands) > 0 && cfg.Mode == NotifyModeClosestApproach {
+		return fmt.Errorf("notification.bands cannot be combined with the %s mode", NotifyModeClosestApproach)
+	}
+
+	if err := validateRateLimit(cfg.RateLimit); err != nil {
+		return fmt.Errorf("notification: %w", err)
+	}
+	if err := validateQuietHours(cfg.QuietHours); err != nil {
+		return fmt.Errorf("notification: %w", err)
+	}
+	if cfg.Burst.Threshold < 0 || cfg.Burst.Threshold == 1 {
+		return fmt.Errorf("notification.burst.threshold must be 0 or at least 2")
+	}
+	if cfg.Queue.Workers < 0 {
+		return fmt.Errorf("notification.queue.workers cannot be negative")
+	}
+	if cfg.Queue.Workers > 0 && cfg.Queue.Size < 1 {
+		return fmt.Errorf("notification.queue.size must be at least 1")
+	}
+	return nil
+}
+
+// validateTransit rejects unknown bodies and a window or separation that cannot be used
+func validateTransit(transit TransitConfig) error {
+	for _, name := range transit.Bodies {
+		if _, ok := geo.ParseBody(name); !ok {
+			return fmt.Errorf("notification.transit.bodies: unknown body %q, must be sun or moon", name)
+		}
+	}
+	if transit.Enabled && transit.Window <= 0 {
+		return fmt.Errorf("notification.transit.window must be positive")
+	}
+	if transit.MaxSeparation < 0 {
+		return fmt.Errorf("notification.transit.max_separation cannot be negative")
+	}
+	return nil
+}
+
+// validateRateLimit rejects negative counts and periods
+func validateRateLimit(limit RateLimitConfig) error {
+	if limit.Count < 0 || limit.Burst < 0 || limit.Period < 0 {
+		return fmt.Errorf("rate_limit cannot be negative")
+	}
+	return nil
+}
+
+// validateQuietHours requires both ends of the window as HH:MM once either is set
+func validateQuietHours(quiet QuietHoursConfig) error {
+	if quiet.Start != "" || quiet.End != "" {
+		for _, t := range []string{quiet.Start, quiet.End} {
+			if _, err := time.Parse("15:04", t); err != nil {
+				return fmt.Errorf("quiet_hours start and end must be HH:MM")
+			}
+		}
+	}
+	if err := validatePriority(quiet.Override); err != nil {
+		return fmt.Errorf("quiet_hours.override: %w", err)
+	}
+	return nil
+}
+
+// validatePriority accepts an empty priority or one of the priority names
+func validatePriority(priority string) error {
+	switch priority {
+	case "", "low", "normal", "high", "urgent":
+		return nil
+	default:
+		return fmt.Errorf("%q must be low, normal, high or urgent", priority)
+	}
+}
+
+// validateMessageTemplate renders a title and body against a sample detection so mistakes fail at load time
+func validateMessageTemplate(path string, t MessageTemplate) error {
+	if err := templates.Validate("title", t.Title, templates.SampleMessage()); err != nil {
+		return fmt.Errorf("%s.title: invalid template: %w", path, err)
+	}
+	if err := templates.Validate("body", t.Body, templates.SampleMessage()); err != nil {
+		return fmt.Errorf("%s.body: invalid template: %w", path, err)
+	}
+	return nil
+}
+
 // SaveDefaultConfig saves a default configuration file
 func SaveDefaultConfig(path string) error {
 	setDefaults()
@@ -80,6 +80,7 @@ notification:
 				Expect(cfg.Location.MaxDistance).To(Equal(100.0))
 				Expect(cfg.Monitoring.PollInterval.String()).To(Equal("5s"))
 				Expect(cfg.Monitoring.Debug).To(BeTrue())
+				Expect(cfg.Monitoring.Smoothing).To(BeTrue())
 				Expect(cfg.Notification.Enabled).To(BeTrue())
 			})
 		})
@@ -123,6 +124,187 @@ filters:
 				Expect(err.Error()).To(ContainSubstring("min_altitude cannot be greater than max_altitude"))
 			})
 
+			It("should validate category filters", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+filters:
+  species: ["Zeppelin"]
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid species filter"))
+			})
+
+			It("should require a url for each webhook", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+notification:
+  webhooks:
+    - name: "home"
+      template: "{}"
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("notification.webhooks[0]: url is required"))
+			})
+
+			It("should require a token for each gotify server", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+notification:
+  gotify:
+    - url: "https://gotify.example.com"
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("notification.gotify[0]: token is required"))
+			})
+
+			It("should validate message templates", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+notification:
+  title: "{{.Callsign}} {{ft .Aircraft.Alt}}"
+  slack:
+    - url: "https://hooks.slack.com/services/T000/B000/XXXX"
+      body: "{{.Aircraft.Tail}}"
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("notification.slack[0].body: invalid template"))
+			})
+
+			It("should validate channel message templates", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+notification:
+  channels:
+    - name: "military"
+      backends: ["slack"]
+      title: "{{.Aircraft.Tail}}"
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("notification.channels[0].title: invalid template"))
+			})
+
+			It("should validate routing rules", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+notification:
+  channels:
+    - name: "phone"
+      backends: ["ntfy"]
+      min_priority: "high"
+  rules:
+    - military: true
+      channels: ["pager"]
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`notification.rules[0]: unknown channel "pager"`))
+			})
+
+			It("should reject a quiet hours override that is not a priority", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+notification:
+  quiet_hours:
+    start: "23:00"
+    end: "06:30"
+    override: "loud"
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("notification: quiet_hours.override"))
+			})
+
+			It("should reject an unknown transit body", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+notification:
+  transit:
+    enabled: true
+    bodies: ["sun", "mars"]
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`unknown body "mars"`))
+			})
+
+			It("should require a broker when mqtt is enabled", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+mqtt:
+  enabled: true
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("mqtt.broker is required"))
+			})
+
+			It("should require mqtt for home assistant discovery", func() {
+				configContent := `
+server:
+  url: "http://test-server:8080/VirtualRadar/AircraftList.json"
+mqtt:
+  home_assistant:
+    enabled: true
+`
+				configFile := filepath.Join(tempDir, "godar.yaml")
+				err := os.WriteFile(configFile, []byte(configContent), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = config.Load(configFile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("mqtt.home_assistant requires mqtt"))
+			})
+
 			It("should validate latitude range", func() {
 				configContent := `
 server:
@@ -184,6 +366,13 @@ monitoring:
 		})
 	})
 
+	Describe("MessageTemplate", func() {
+		It("should fall back to the shared templates per field", func() {
+			shared := config.MessageTemplate{Title: "shared title", Body: "shared body"}
+			Expect(config.MessageTemplate{Body: "own body"}.Or(shared)).To(Equal(config.MessageTemplate{Title: "shared title", Body: "own body"}))
+		})
+	})
+
 	Describe("SaveDefaultConfig", func() {
 		It("should save default configuration", func() {
 			configFile := filepath.Join(tempDir, "default.yaml")
@@ -0,0 +1,260 @@
+package detection
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/geo"
+)
+
+// EmergencySquawks are the transponder codes for hijack, radio failure and general emergency
+var EmergencySquawks = []int{7500, 7600, 7700}
+
+// Observer describes where the user is and which way they are facing
+type Observer struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Heading   float64 `json:"heading"`   // Direction the observer is facing in degrees (0-360, 0=North)
+	Elevation float64 `json:"elevation"` // Height above sea level in metres
+}
+
+// IsSet reports whether a location has been configured for the observer
+func (o Observer) IsSet() bool {
+	return o.Latitude != 0.0 || o.Longitude != 0.0
+}
+
+// Position returns where the observer is in three dimensions
+func (o Observer) Position() geo.Position {
+	return geo.Position{Latitude: o.Latitude, Longitude: o.Longitude, Altitude: o.Elevation}
+}
+
+// BRAA is the bearing, range, altitude and aspect of an aircraft relative to the observer
+type BRAA struct {
+	Bearing   float64 `json:"bearing"`   // Degrees from the observer
+	RangeNm   float64 `json:"range_nm"`  // Range in nautical miles
+	Altitude  int     `json:"altitude"`  // Altitude in feet
+	Aspect    string  `json:"aspect"`    // Hot, Cold or Flanking
+	Elevation float64 `json:"elevation"` // Degrees above the observer's horizon
+}
+
+// String formats the BRAA as bearing/range/altitude/aspect, e.g. "045/14/35000/Hot"
+func (b BRAA) String() string {
+	return fmt.Sprintf("%03.0f/%.0f/%d/%s", b.Bearing, b.RangeNm, b.Altitude, b.Aspect)
+}
+
+// TrackPoint is a previously observed position of the aircraft
+type TrackPoint struct {
+	Time      time.Time `json:"time"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Altitude  int       `json:"altitude"`
+	Track     float64   `json:"track,omitempty"` // Reported track in degrees, 0 if unknown
+	Distance  float64   `json:"distance_km"`
+}
+
+// Alerts raised by the closest approach notification mode, the heads-up scheduler and transit prediction
+const (
+	AlertApproaching     = "approaching"      // The aircraft has started closing in on the observer
+	AlertClosestApproach = "closest_approach" // The aircraft has passed its closest point and is moving away
+	AlertHeadsUp         = "heads_up"         // The aircraft is predicted to pass close by shortly
+	AlertTransit         = "transit"          // The aircraft is predicted to cross in front of the sun or moon
+)
+
+// Passage is where an aircraft came, or is predicted to come, closest to the observer during a pass
+type Passage struct {
+	Time      time.Time `json:"time"`
+	Distance  float64   `json:"distance_km"`
+	Direction string    `json:"direction"` // 16-point compass direction from the observer
+	Altitude  int       `json:"altitude"`
+	Overhead  bool      `json:"overhead"` // Within the overhead distance
+}
+
+// String describes the passage, e.g. "2.1 km overhead at 14:03" or "4.5 km NNE at 14:03"
+func (p Passage) String() string {
+	where := p.Direction
+	if p.Overhead {
+		where = "overhead"
+	}
+	return fmt.Sprintf("%.1f km %s at %s", p.Distance, where, p.Time.Local().Format("15:04"))
+}
+
+// Fix is an aircraft's position as reported, before it was smoothed
+type Fix struct {
+	Latitude  float64       `json:"latitude"`
+	Longitude float64       `json:"longitude"`
+	Track     float64       `json:"track,omitempty"`
+	Age       time.Duration `json:"age"` // How old the position was when the aircraft was detected
+}
+
+// Detection carries everything known about an aircraft when a notification is raised.
+// It is the payload handed to every notifier.
+type Detection struct {
+	Time             time.Time            `json:"time"`
+	Aircraft         aircraft.Aircraft    `json:"aircraft"`
+	Reported         *Fix                 `json:"reported,omitempty"` // Position as reported when Aircraft holds the smoothed state, nil without smoothing
+	Observer         Observer             `json:"observer"`
+	Distance         float64              `json:"distance_km"`          // Surface distance from the observer in km
+	SlantRange       float64              `json:"slant_range_km"`       // Line of sight distance from the observer in km
+	Elevation        float64              `json:"elevation"`            // Degrees above the observer's horizon, corrected for the curvature of the earth
+	Hidden           string               `json:"hidden,omitempty"`     // Why the aircraft is outside the observer's view, see the geo.HiddenBy constants
+	PreviousDistance float64              `json:"previous_distance_km"` // Distance on the previous poll in km, 0 if unknown
+	Bearing          float64              `json:"bearing"`              // Bearing from the observer in degrees
+	Direction        string               `json:"direction"`            // 16-point compass direction, e.g. "NNE"
+	ClockPosition    int                  `json:"clock_position"`       // 1-12 relative to the observer's heading
+	BRAA             BRAA                 `json:"braa"`
+	ClosestApproach  *geo.ClosestApproach `json:"closest_approach,omitempty"` // nil when the aircraft has no usable track
+	Track            []TrackPoint         `json:"track,omitempty"`            // Recent positions, oldest first
+	Burst            []*Detection         `json:"burst,omitempty"`            // Every detection collapsed into this summary, nearest first
+	Alert            string               `json:"alert,omitempty"`            // Why the notification was raised, see the Alert constants
+	Passage          *Passage             `json:"passage,omitempty"`          // Closest point of the pass, predicted for AlertHeadsUp
+	Band             string               `json:"band,omitempty"`             // Distance band the aircraft has just entered, e.g. "20 km"
+	Transits         []geo.Transit        `json:"transits,omitempty"`         // Predicted passes in front of the sun or moon, soonest first
+}
+
+// New computes the observer geometry for an aircraft
+func New(ac aircraft.Aircraft, observer Observer) *Detection {
+	d := &Detection{
+		Time:     time.Now(),
+		Aircraft: ac,
+		Observer: observer,
+	}
+
+	if observer.IsSet() {
+		d.Distance = geo.CalculateDistance(observer.Latitude, observer.Longitude, ac.Lat, ac.Long)
+		d.Bearing = geo.CalculateBearing(observer.Latitude, observer.Longitude, ac.Lat, ac.Long)
+		_, d.Elevation, d.SlantRange = geo.LookAngle(observer.Position(), AircraftPosition(ac))
+	}
+	d.Direction = geo.BearingToDirection(d.Bearing)
+	d.ClockPosition = geo.BearingToClockPosition(observer.Heading, d.Bearing)
+	d.BRAA = BRAA{
+		Bearing:   d.Bearing,
+		RangeNm:   geo.KmToNauticalMiles(d.Distance),
+		Altitude:  ac.Alt,
+		Aspect:    geo.CalculateAspect(ac.Trak, d.Bearing),
+		Elevation: d.Elevation,
+	}
+
+	// Predict the closest approach if the aircraft has a valid heading and speed
+	if ac.Trak > 0 && ac.Spd > 1.0 && ac.Lat != 0 && ac.Long != 0 {
+		d.ClosestApproach = geo.CalculateClosestApproach(observer.Latitude, observer.Longitude, ac.Lat, ac.Long, ac.Trak, ac.Spd)
+	}
+
+	return d
+}
+
+// AircraftPosition returns where an aircraft is in three dimensions.
+// GAlt is corrected for air pressure, so it is preferred as being closer to the height above sea level.
+func AircraftPosition(ac aircraft.Aircraft) geo.Position {
+	altitude := ac.GAlt
+	if altitude == 0 {
+		altitude = ac.Alt
+	}
+	return geo.Position{Latitude: ac.Lat, Longitude: ac.Long, Altitude: geo.FeetToMetres(float64(altitude))}
+}
+
+// LookAngle describes where to look for the aircraft, e.g. "NE, 35° up"
+func (d *Detection) LookAngle() string {
+	return fmt.Sprintf("%s, %.0f° up", d.Direction, d.Elevation)
+}
+
+// Visibility describes why the aircraft cannot be seen and whether it will come into view,
+// e.g. "below the horizon, in view in 3 min". It is empty when the aircraft can be seen.
+func (d *Detection) Visibility() string {
+	if d.Hidden == "" {
+		return ""
+	}
+	approach := d.ClosestApproach
+	switch {
+	case approach == nil:
+		return d.Hidden
+	case approach.WillBeVisible:
+		return fmt.Sprintf("%s, in view in %s", d.Hidden, geo.FormatTimeToClosest(approach.TimeToVisible))
+	default:
+		return d.Hidden + ", not expected in view"
+	}
+}
+
+// Callsign returns the callsign, falling back to the registration or ICAO address
+func (d *Detection) Callsign() string {
+	switch {
+	case d.Aircraft.Call != "":
+		return d.Aircraft.Call
+	case d.Aircraft.Reg != "":
+		return d.Aircraft.Reg
+	default:
+		return d.Aircraft.Icao
+	}
+}
+
+// Category describes the aircraft's wake category, engine type and species, e.g. "Heavy Jet LandPlane".
+// Attributes VRS does not know are left out.
+func (d *Detection) Category() string {
+	var parts []string
+	if d.Aircraft.WTC != aircraft.WTCNone {
+		parts = append(parts, d.Aircraft.WTC.String())
+	}
+	if d.Aircraft.EngType != aircraft.EngTypeNone {
+		parts = append(parts, d.Aircraft.EngType.String())
+	}
+	if d.Aircraft.Species != aircraft.SpeciesNone {
+		parts = append(parts, d.Aircraft.Species.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Emergency reports whether the aircraft is squawking an emergency code
+func (d *Detection) Emergency() bool {
+	return slices.Contains(EmergencySquawks, int(d.Aircraft.Sqk))
+}
+
+// Summarise collapses several detections into a copy of the nearest one, carrying all of them in Burst
+func Summarise(detections []*Detection) *Detection {
+	burst := slices.Clone(detections)
+	slices.SortStableFunc(burst, func(a, b *Detection) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+
+	summary := *burst[0]
+	summary.Burst = burst
+	return &summary
+}
+
+// Summary describes a collapsed burst, e.g. "7 aircraft in range, nearest BAW12 3.0 km".
+// It is empty for a single detection.
+func (d *Detection) Summary() string {
+	if len(d.Burst) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d aircraft in range, nearest %s %.1f km", len(d.Burst), d.Burst[0].Callsign(), d.Burst[0].Distance)
+}
+
+// HeadsUp describes a predicted passage, e.g. "BAW12 overhead in 2 min, look NE" or "BAW12 4.5 km away in 2 min, look NE".
+// It is empty without a passage.
+func (d *Detection) HeadsUp() string {
+	if d.Passage == nil {
+		return ""
+	}
+	where := "overhead"
+	if !d.Passage.Overhead {
+		where = fmt.Sprintf("%.1f km away", d.Passage.Distance)
+	}
+	return fmt.Sprintf("%s %s in %s, look %s", d.Callsign(), where, geo.FormatTimeToClosest(d.Passage.Time.Sub(d.Time)), d.Passage.Direction)
+}
+
+// Transit describes the soonest predicted transit, e.g. "BAW12 crosses the sun in 1m 23s".
+// It is empty without a transit.
+func (d *Detection) Transit() string {
+	if len(d.Transits) == 0 {
+		return ""
+	}
+	t := d.Transits[0]
+	verb := "crosses"
+	if !t.OnDisk() {
+		verb = "passes close to"
+	}
+	return fmt.Sprintf("%s %s the %s in %s", d.Callsign(), verb, t.Body, geo.FormatCountdown(t.Time.Sub(d.Time)))
+}
@@ -0,0 +1,108 @@
+package detection_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/detection"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Detection", func() {
+	observer := detection.Observer{Latitude: 51.0, Longitude: 0.0}
+
+	Describe("New", func() {
+		It("should compute the observer geometry", func() {
+			// Aircraft ~55.6 km due north, heading south towards the observer
+			ac := aircraft.Aircraft{Call: "TEST1", Lat: 51.5, Long: 0.001, Alt: 35000, Trak: 180, Spd: 450}
+			d := detection.New(ac, observer)
+
+			Expect(d.Distance).To(BeNumerically("~", 55.6, 0.1))
+			Expect(d.Bearing).To(BeNumerically("~", 0.0, 0.1))
+			Expect(d.Direction).To(Equal("N"))
+			Expect(d.ClockPosition).To(Equal(12))
+			Expect(d.BRAA.Aspect).To(Equal("Hot"))
+			Expect(d.BRAA.String()).To(Equal("000/30/35000/Hot"))
+			// 35,000 ft is 10.7 km, of which 0.2 km is hidden by the curvature of the earth
+			Expect(d.Elevation).To(BeNumerically("~", 10.6, 0.1))
+			Expect(d.SlantRange).To(BeNumerically("~", 56.6, 0.1))
+			Expect(d.LookAngle()).To(Equal("N, 11° up"))
+			Expect(d.ClosestApproach).NotTo(BeNil())
+			Expect(d.ClosestApproach.WillApproach).To(BeTrue())
+		})
+
+		It("should leave distance and bearing at zero without an observer location", func() {
+			ac := aircraft.Aircraft{Lat: 51.5, Long: 0.0}
+			d := detection.New(ac, detection.Observer{})
+
+			Expect(d.Distance).To(Equal(0.0))
+			Expect(d.Bearing).To(Equal(0.0))
+		})
+
+		It("should not predict a closest approach without a track", func() {
+			ac := aircraft.Aircraft{Lat: 51.5, Long: 0.0, Spd: 0}
+			d := detection.New(ac, observer)
+
+			Expect(d.ClosestApproach).To(BeNil())
+		})
+	})
+
+	Describe("Callsign", func() {
+		It("should fall back to registration and ICAO", func() {
+			Expect(detection.New(aircraft.Aircraft{Call: "BAW12", Reg: "G-ABCD"}, observer).Callsign()).To(Equal("BAW12"))
+			Expect(detection.New(aircraft.Aircraft{Reg: "G-ABCD", Icao: "400123"}, observer).Callsign()).To(Equal("G-ABCD"))
+			Expect(detection.New(aircraft.Aircraft{Icao: "400123"}, observer).Callsign()).To(Equal("400123"))
+		})
+	})
+
+	Describe("Category", func() {
+		It("should describe the known attributes", func() {
+			ac := aircraft.Aircraft{WTC: aircraft.WTCHeavy, EngType: aircraft.EngTypeJet, Species: aircraft.SpeciesLandPlane}
+			Expect(detection.New(ac, observer).Category()).To(Equal("Heavy Jet LandPlane"))
+		})
+
+		It("should be empty when nothing is known", func() {
+			Expect(detection.New(aircraft.Aircraft{}, observer).Category()).To(BeEmpty())
+		})
+	})
+
+	It("should recognise emergency squawks", func() {
+		Expect(detection.New(aircraft.Aircraft{Sqk: 7700}, observer).Emergency()).To(BeTrue())
+		Expect(detection.New(aircraft.Aircraft{Sqk: 7000}, observer).Emergency()).To(BeFalse())
+	})
+
+	It("should describe a passage", func() {
+		at := time.Date(2025, 6, 1, 14, 3, 0, 0, time.Local)
+		Expect(detection.Passage{Time: at, Distance: 2.14, Direction: "NNE", Overhead: true}.String()).To(Equal("2.1 km overhead at 14:03"))
+		Expect(detection.Passage{Time: at, Distance: 4.5, Direction: "NNE"}.String()).To(Equal("4.5 km NNE at 14:03"))
+	})
+
+	It("should summarise a burst around the nearest aircraft", func() {
+		far := detection.New(aircraft.Aircraft{Call: "EZY1"}, observer)
+		far.Distance = 12
+		near := detection.New(aircraft.Aircraft{Call: "BAW12"}, observer)
+		near.Distance = 3
+
+		summary := detection.Summarise([]*detection.Detection{far, near})
+		Expect(summary.Callsign()).To(Equal("BAW12"))
+		Expect(summary.Burst).To(Equal([]*detection.Detection{near, far}))
+		Expect(summary.Summary()).To(Equal("2 aircraft in range, nearest BAW12 3.0 km"))
+		Expect(near.Summary()).To(BeEmpty())
+	})
+
+	It("should marshal to JSON with stable field names", func() {
+		ac := aircraft.Aircraft{Call: "TEST1", Lat: 51.5, Long: 0.001, Alt: 35000, Trak: 180, Spd: 450, WTC: aircraft.WTCHeavy}
+		data, err := json.Marshal(detection.New(ac, observer))
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded map[string]any
+		Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+		Expect(decoded).To(HaveKey("distance_km"))
+		Expect(decoded).To(HaveKey("braa"))
+		Expect(decoded).To(HaveKey("closest_approach"))
+		Expect(decoded["aircraft"]).To(HaveKeyWithValue("WTC", "Heavy"))
+	})
+})
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+)
+
+// Type identifies what happened to a tracked aircraft
+type Type int
+
+// Event types derived from per-aircraft track state
+const (
+	FirstSeen Type = iota + 1
+	Approaching
+	ClosestApproachReached
+	Overhead
+	Receding
+	Lost
+	SquawkChanged
+	AltitudeBandChanged
+)
+
+var typeNames = map[Type]string{
+	FirstSeen:              "FirstSeen",
+	Approaching:            "Approaching",
+	ClosestApproachReached: "ClosestApproachReached",
+	Overhead:               "Overhead",
+	Receding:               "Receding",
+	Lost:                   "Lost",
+	SquawkChanged:          "SquawkChanged",
+	AltitudeBandChanged:    "AltitudeBandChanged",
+}
+
+// String returns the name of the event type
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("Type(%d)", int(t))
+}
+
+// AltitudeBandSize is the height of each altitude band in feet
+const AltitudeBandSize = 5000
+
+// AltitudeBand returns the band index for an altitude in feet, 0 being below AltitudeBandSize
+func AltitudeBand(altitude int) int {
+	if altitude < 0 {
+		return 0
+	}
+	return altitude / AltitudeBandSize
+}
+
+// Event describes a change in the state of a tracked aircraft
+type Event struct {
+	Type       Type
+	Time       time.Time
+	AircraftID string            // ICAO or callsign used to key the track
+	Aircraft   aircraft.Aircraft // Last known state of the aircraft
+	Distance   float64           // Current distance from the observer in km
+	Bearing    float64           // Current bearing from the observer in degrees
+
+	PreviousDistance     float64           // Distance on the previous poll in km, 0 if unknown
+	MinDistance          float64           // Closest distance observed so far in km
+	PreviousSquawk       aircraft.SqkValue // Set for SquawkChanged
+	AltitudeBand         int               // Current altitude band, see AltitudeBand
+	PreviousAltitudeBand int               // Set for AltitudeBandChanged
+}
+
+// Handler consumes events published on a Bus
+type Handler func(Event)
+
+type subscription struct {
+	handler Handler
+	types   []Type
+}
+
+// Bus delivers events to any number of in-process subscribers.
+// Handlers are invoked synchronously, in subscription order, on the publishing goroutine.
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]subscription
+	order       []int
+
+	snapshotSubscribers map[int]SnapshotHandler
+	snapshotOrder       []int
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{
+		subscribers:         make(map[int]subscription),
+		snapshotSubscribers: make(map[int]SnapshotHandler),
+	}
+}
+
+// Subscribe registers a handler for the given event types, or for every event if none are given.
+// The returned function removes the subscription.
+func (b *Bus) Subscribe(handler Handler, types ...Type) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = subscription{handler: handler, types: types}
+	b.order = append(b.order, id)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, id)
+		b.order = slices.DeleteFunc(b.order, func(v int) bool { return v == id })
+	}
+}
+
+// Publish delivers an event to every matching subscriber
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.order))
+	for _, id := range b.order {
+		sub := b.subscribers[id]
+		if len(sub.types) == 0 || slices.Contains(sub.types, e.Type) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	// Handlers run outside the lock so they may subscribe or unsubscribe
+	for _, h := range handlers {
+		h(e)
+	}
+}
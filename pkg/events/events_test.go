@@ -0,0 +1,114 @@
+package events_test
+
+import (
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/events"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Events", func() {
+	Describe("Type", func() {
+		It("should have readable names", func() {
+			Expect(events.FirstSeen.String()).To(Equal("FirstSeen"))
+			Expect(events.ClosestApproachReached.String()).To(Equal("ClosestApproachReached"))
+			Expect(events.Type(99).String()).To(Equal("Type(99)"))
+		})
+	})
+
+	Describe("AltitudeBand", func() {
+		It("should bucket altitudes by AltitudeBandSize", func() {
+			Expect(events.AltitudeBand(0)).To(Equal(0))
+			Expect(events.AltitudeBand(4999)).To(Equal(0))
+			Expect(events.AltitudeBand(5000)).To(Equal(1))
+			Expect(events.AltitudeBand(35000)).To(Equal(7))
+			Expect(events.AltitudeBand(-100)).To(Equal(0))
+		})
+	})
+
+	Describe("Bus", func() {
+		var bus *events.Bus
+
+		BeforeEach(func() {
+			bus = events.NewBus()
+		})
+
+		It("should deliver events to every subscriber", func() {
+			var first, second []events.Event
+			bus.Subscribe(func(e events.Event) { first = append(first, e) })
+			bus.Subscribe(func(e events.Event) { second = append(second, e) })
+
+			bus.Publish(events.Event{Type: events.FirstSeen, AircraftID: "ABC123"})
+
+			Expect(first).To(HaveLen(1))
+			Expect(second).To(HaveLen(1))
+			Expect(first[0].AircraftID).To(Equal("ABC123"))
+		})
+
+		It("should only deliver subscribed types", func() {
+			var received []events.Type
+			bus.Subscribe(func(e events.Event) { received = append(received, e.Type) }, events.Lost, events.Overhead)
+
+			bus.Publish(events.Event{Type: events.FirstSeen})
+			bus.Publish(events.Event{Type: events.Overhead})
+			bus.Publish(events.Event{Type: events.Lost})
+
+			Expect(received).To(Equal([]events.Type{events.Overhead, events.Lost}))
+		})
+
+		It("should stop delivering after unsubscribe", func() {
+			count := 0
+			unsubscribe := bus.Subscribe(func(events.Event) { count++ })
+
+			bus.Publish(events.Event{Type: events.FirstSeen})
+			unsubscribe()
+			bus.Publish(events.Event{Type: events.FirstSeen})
+
+			Expect(count).To(Equal(1))
+		})
+
+		It("should allow handlers to subscribe while publishing", func() {
+			count := 0
+			bus.Subscribe(func(events.Event) {
+				bus.Subscribe(func(events.Event) { count++ })
+			})
+
+			bus.Publish(events.Event{Type: events.FirstSeen})
+			bus.Publish(events.Event{Type: events.FirstSeen})
+
+			Expect(count).To(Equal(1))
+		})
+
+		It("should deliver snapshots to snapshot subscribers", func() {
+			var received []events.Snapshot
+			unsubscribe := bus.SubscribeSnapshots(func(s events.Snapshot) { received = append(received, s) })
+
+			bus.PublishSnapshot(events.Snapshot{})
+			unsubscribe()
+			bus.PublishSnapshot(events.Snapshot{})
+
+			Expect(received).To(HaveLen(1))
+		})
+	})
+
+	Describe("Snapshot", func() {
+		It("should order aircraft nearest first", func() {
+			s := events.NewSnapshot(time.Now(), []*detection.Detection{
+				{Aircraft: aircraft.Aircraft{Icao: "FAR"}, Distance: 40},
+				{Aircraft: aircraft.Aircraft{Icao: "NEAR"}, Distance: 2},
+				{Aircraft: aircraft.Aircraft{Icao: "MID"}, Distance: 10},
+			})
+
+			Expect(s.Nearest().Aircraft.Icao).To(Equal("NEAR"))
+			Expect(s.Aircraft[2].Aircraft.Icao).To(Equal("FAR"))
+		})
+
+		It("should have no nearest aircraft when empty", func() {
+			Expect(events.NewSnapshot(time.Now(), nil).Nearest()).To(BeNil())
+		})
+	})
+})
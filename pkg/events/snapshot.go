@@ -0,0 +1,74 @@
+package events
+
+import (
+	"slices"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/detection"
+)
+
+// Snapshot describes every aircraft currently tracked.
+// The monitor publishes one after each poll so outputs can maintain aggregate state.
+type Snapshot struct {
+	Time     time.Time
+	Aircraft []*detection.Detection // Nearest first
+}
+
+// NewSnapshot sorts the detections by distance from the observer
+func NewSnapshot(t time.Time, detections []*detection.Detection) Snapshot {
+	slices.SortStableFunc(detections, func(a, b *detection.Detection) int {
+		switch {
+		case a.Distance < b.Distance:
+			return -1
+		case a.Distance > b.Distance:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return Snapshot{Time: t, Aircraft: detections}
+}
+
+// Nearest returns the closest aircraft, or nil if nothing is being tracked
+func (s Snapshot) Nearest() *detection.Detection {
+	if len(s.Aircraft) == 0 {
+		return nil
+	}
+	return s.Aircraft[0]
+}
+
+// SnapshotHandler consumes snapshots published on a Bus
+type SnapshotHandler func(Snapshot)
+
+// SubscribeSnapshots registers a handler for every snapshot.
+// The returned function removes the subscription.
+func (b *Bus) SubscribeSnapshots(handler SnapshotHandler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.snapshotSubscribers[id] = handler
+	b.snapshotOrder = append(b.snapshotOrder, id)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.snapshotSubscribers, id)
+		b.snapshotOrder = slices.DeleteFunc(b.snapshotOrder, func(v int) bool { return v == id })
+	}
+}
+
+// PublishSnapshot delivers a snapshot to every snapshot subscriber
+func (b *Bus) PublishSnapshot(s Snapshot) {
+	b.mu.RLock()
+	handlers := make([]SnapshotHandler, 0, len(b.snapshotOrder))
+	for _, id := range b.snapshotOrder {
+		handlers = append(handlers, b.snapshotSubscribers[id])
+	}
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(s)
+	}
+}
@@ -0,0 +1,189 @@
+package geo
+
+import "math"
+
+// WGS84 ellipsoid, as used by GPS and ADS-B
+const (
+	wgs84A = 6378.137          // Semi-major axis in km
+	wgs84F = 1 / 298.257223563 // Flattening
+	wgs84B = wgs84A * (1 - wgs84F)
+)
+
+// Convergence of Vincenty's iterations, in radians of longitude on the auxiliary sphere and km along a geodesic
+const (
+	vincentyTolerance  = 1e-12
+	vincentyIterations = 200
+	interceptTolerance = 1e-6
+	interceptSteps     = 50
+)
+
+// Inverse solves the inverse geodesic problem on the WGS84 ellipsoid with Vincenty's method: the distance in km
+// between two points and the initial and final bearings in degrees of the shortest path between them.
+// Nearly antipodal points, where the method does not converge, fall back to the spherical great circle.
+func Inverse(lat1, lon1, lat2, lon2 float64) (distance, initialBearing, finalBearing float64) {
+	u1 := math.Atan((1 - wgs84F) * math.Tan(rad(lat1)))
+	u2 := math.Atan((1 - wgs84F) * math.Tan(rad(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+	l := rad(normalizeAngle(lon2 - lon1))
+
+	lambda := l
+	var sinSigma, cosSigma, sigma, cosSqAlpha, cos2SigmaM, sinLambda, cosLambda float64
+	converged := false
+	for range vincentyIterations {
+		sinLambda, cosLambda = math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, 0, 0 // Coincident points
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha // Zero on the equator
+		}
+		c := wgs84F / 16 * cosSqAlpha * (4 + wgs84F*(4-3*cosSqAlpha))
+		previous := lambda
+		lambda = l + (1-c)*wgs84F*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) < vincentyTolerance {
+			converged = true
+			break
+		}
+	}
+	if !converged {
+		return SphericalDistance(lat1, lon1, lat2, lon2), SphericalBearing(lat1, lon1, lat2, lon2),
+			math.Mod(SphericalBearing(lat2, lon2, lat1, lon1)+180, 360)
+	}
+
+	uSq := cosSqAlpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	a := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	b := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+
+	distance = wgs84B * a * (sigma - deltaSigma)
+	initialBearing = math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda) * 180 / math.Pi
+	finalBearing = math.Atan2(cosU1*sinLambda, -sinU1*cosU2+cosU1*sinU2*cosLambda) * 180 / math.Pi
+	return distance, math.Mod(initialBearing+360, 360), math.Mod(finalBearing+360, 360)
+}
+
+// Direct solves the direct geodesic problem on the WGS84 ellipsoid with Vincenty's method: the point reached by
+// travelling distance km from lat, lon along an initial bearing in degrees, and the bearing on arrival
+func Direct(lat, lon, bearing, distance float64) (lat2, lon2, finalBearing float64) {
+	sinAlpha1, cosAlpha1 := math.Sincos(rad(bearing))
+	tanU1 := (1 - wgs84F) * math.Tan(rad(lat))
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	sigma1 := math.Atan2(tanU1, cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cosSqAlpha := 1 - sinAlpha*sinAlpha
+	uSq := cosSqAlpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+	a := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	b := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+
+	sigma := distance / (wgs84B * a)
+	var sinSigma, cosSigma, cos2SigmaM float64
+	for range vincentyIterations {
+		cos2SigmaM = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sincos(sigma)
+		deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		previous := sigma
+		sigma = distance/(wgs84B*a) + deltaSigma
+		if math.Abs(sigma-previous) < vincentyTolerance {
+			break
+		}
+	}
+	sinSigma, cosSigma = math.Sincos(sigma)
+	cos2SigmaM = math.Cos(2*sigma1 + sigma)
+
+	x := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	lat2 = math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-wgs84F)*math.Hypot(sinAlpha, x))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	c := wgs84F / 16 * cosSqAlpha * (4 + wgs84F*(4-3*cosSqAlpha))
+	l := lambda - (1-c)*wgs84F*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+	finalBearing = math.Atan2(sinAlpha, -x) * 180 / math.Pi
+
+	return lat2 * 180 / math.Pi, math.Mod(lon+l*180/math.Pi+540, 360) - 180, math.Mod(finalBearing+360, 360)
+}
+
+// CrossTrackDistance returns how far in km a point is from the geodesic leaving lat, lon on an initial bearing,
+// positive to the right of the direction of travel
+func CrossTrackDistance(lat, lon, bearing, pointLat, pointLon float64) float64 {
+	_, cross := intercept(lat, lon, bearing, pointLat, pointLon)
+	return cross
+}
+
+// AlongTrackDistance returns how far in km along the geodesic leaving lat, lon on an initial bearing the point
+// closest to another point is, negative if it is behind the start
+func AlongTrackDistance(lat, lon, bearing, pointLat, pointLon float64) float64 {
+	along, _ := intercept(lat, lon, bearing, pointLat, pointLon)
+	return along
+}
+
+// intercept finds the foot of the perpendicular from a point to a geodesic by stepping along the geodesic, each step
+// solving the right spherical triangle between the current foot, the point and the geodesic as a correction
+// (Baselga and Martínez-Llario, 2018). It converges to well under a millimetre in a few steps.
+func intercept(lat, lon, bearing, pointLat, pointLon float64) (along, cross float64) {
+	const R = 6371 // Earth's radius in kilometers, only scaling the corrections
+	footLat, footLon, footBearing := lat, lon, bearing
+	for range interceptSteps {
+		distance, toPoint, _ := Inverse(footLat, footLon, pointLat, pointLon)
+		angle := rad(toPoint - footBearing)
+		cross = distance
+		if math.Sin(angle) < 0 {
+			cross = -distance
+		}
+		step := R * math.Atan(math.Tan(distance/R)*math.Cos(angle))
+		if distance/R >= math.Pi/2 {
+			step = distance * math.Cos(angle) // Beyond a quarter of the way round the tangent is no use
+		}
+		along += step
+		if math.Abs(step) < interceptTolerance {
+			break
+		}
+		footLat, footLon, footBearing = Direct(lat, lon, bearing, along)
+	}
+	return along, cross
+}
+
+// SphericalDistance is the fast path for CalculateDistance, using the Haversine formula on a sphere of radius 6371 km.
+// It is within half a percent of the ellipsoidal distance.
+func SphericalDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	const R = 6371 // Earth's radius in kilometers
+
+	la1 := rad(lat1)
+	la2 := rad(lat2)
+	dlat := la2 - la1
+	dlon := rad(lon2 - lon1)
+
+	a := math.Pow(math.Sin(dlat/2), 2) + math.Cos(la1)*math.Cos(la2)*math.Pow(math.Sin(dlon/2), 2)
+	return R * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// SphericalBearing is the fast path for CalculateBearing, the initial great circle bearing in degrees on a sphere
+func SphericalBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	la1 := rad(lat1)
+	la2 := rad(lat2)
+	dlon := rad(lon2 - lon1)
+
+	y := math.Sin(dlon) * math.Cos(la2)
+	x := math.Cos(la1)*math.Sin(la2) - math.Sin(la1)*math.Cos(la2)*math.Cos(dlon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// SphericalDestination is the fast path for Destination, following a great circle on a sphere of radius 6371 km
+func SphericalDestination(lat, lon, bearing, distance float64) (float64, float64) {
+	const R = 6371 // Earth's radius in kilometers
+
+	la1 := rad(lat)
+	brng := rad(bearing)
+	d := distance / R
+
+	la2 := math.Asin(math.Sin(la1)*math.Cos(d) + math.Cos(la1)*math.Sin(d)*math.Cos(brng))
+	lo2 := rad(lon) + math.Atan2(math.Sin(brng)*math.Sin(d)*math.Cos(la1), math.Cos(d)-math.Sin(la1)*math.Sin(la2))
+
+	return la2 * 180 / math.Pi, math.Mod(lo2*180/math.Pi+540, 360) - 180
+}
@@ -0,0 +1,94 @@
+package geo_test
+
+import (
+	"github.com/lyarwood/godar/pkg/geo"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ellipsoid", func() {
+	// dms converts degrees, minutes and seconds to degrees, taking the sign from the degrees
+	dms := func(d, m, s float64) float64 {
+		if d < 0 {
+			return d - m/60 - s/3600
+		}
+		return d + m/60 + s/3600
+	}
+
+	// Geoscience Australia's worked example on GRS80, which agrees with WGS84 to well under a millimetre here
+	// The bearing on arrival is published as the reverse azimuth, 127°10′25.07″
+	flindersLat, flindersLon := dms(-37, 57, 3.72030), dms(144, 25, 29.52440)
+	buninyongLat, buninyongLon := dms(-37, 39, 10.15610), dms(143, 55, 35.38390)
+
+	Describe("Inverse", func() {
+		It("should match the Flinders Peak to Buninyong reference line", func() {
+			distance, initial, final := geo.Inverse(flindersLat, flindersLon, buninyongLat, buninyongLon)
+			Expect(distance).To(BeNumerically("~", 54.972271, 1e-6))
+			Expect(initial).To(BeNumerically("~", dms(306, 52, 5.37), 0.01/3600))
+			Expect(final).To(BeNumerically("~", dms(127, 10, 25.07)+180, 0.01/3600))
+		})
+
+		It("should measure the WGS84 quarter meridian and degree of longitude on the equator", func() {
+			distance, _, _ := geo.Inverse(0, 0, 90, 0)
+			Expect(distance).To(BeNumerically("~", 10001.965729, 1e-6))
+			distance, bearing, _ := geo.Inverse(0, 0, 0, 1)
+			Expect(distance).To(BeNumerically("~", 111.319491, 1e-6))
+			Expect(bearing).To(BeNumerically("~", 90, 1e-9))
+		})
+
+		It("should fall back to the sphere for nearly antipodal points", func() {
+			// Karney (2013) gives 19989.832828 km, a line Vincenty's method cannot solve
+			distance, _, _ := geo.Inverse(-30, 0, 29.9, 179.8)
+			Expect(distance).To(BeNumerically("~", 19989.83, 0.005*19989.83))
+		})
+	})
+
+	Describe("Direct", func() {
+		It("should match the Flinders Peak to Buninyong reference line", func() {
+			lat, lon, final := geo.Direct(flindersLat, flindersLon, dms(306, 52, 5.37), 54.972271)
+			Expect(lat).To(BeNumerically("~", buninyongLat, 0.0001/3600))
+			Expect(lon).To(BeNumerically("~", buninyongLon, 0.0001/3600))
+			Expect(final).To(BeNumerically("~", dms(127, 10, 25.07)+180, 0.01/3600))
+		})
+
+		It("should match Karney's 10,000 km reference line", func() {
+			// Karney, Algorithms for geodesics (2013), table 2
+			lat, lon, final := geo.Direct(40, 0, 30, 10000)
+			Expect(lat).To(BeNumerically("~", 41.79331020506, 1e-9))
+			Expect(lon).To(BeNumerically("~", 137.84490004377, 1e-9))
+			Expect(final).To(BeNumerically("~", 149.09016931807, 1e-9))
+		})
+	})
+
+	Describe("CrossTrackDistance and AlongTrackDistance", func() {
+		It("should drop a meridian onto the equator", func() {
+			// Meridians cross the equator at right angles, so the foot is straight south of the point
+			meridian, _, _ := geo.Inverse(0, 0.5, 1, 0.5)
+			Expect(geo.CrossTrackDistance(0, 0, 90, 1, 0.5)).To(BeNumerically("~", -meridian, 1e-6))
+			Expect(geo.CrossTrackDistance(0, 0, 90, -1, 0.5)).To(BeNumerically("~", meridian, 1e-6))
+			Expect(meridian).To(BeNumerically("~", 110.574, 0.001))
+
+			half, _, _ := geo.Inverse(0, 0, 0, 0.5)
+			Expect(geo.AlongTrackDistance(0, 0, 90, 1, 0.5)).To(BeNumerically("~", half, 1e-6))
+			Expect(geo.AlongTrackDistance(0, 0, 90, 1, -0.5)).To(BeNumerically("~", -half, 1e-6))
+		})
+
+		It("should find the foot of the perpendicular on an oblique geodesic", func() {
+			// From Flinders Peak towards Buninyong, with a point 5 km to the right of the line half way along
+			midLat, midLon, midBearing := geo.Direct(flindersLat, flindersLon, dms(306, 52, 5.37), 27.5)
+			pointLat, pointLon := geo.Destination(midLat, midLon, midBearing+90, 5)
+
+			Expect(geo.CrossTrackDistance(flindersLat, flindersLon, dms(306, 52, 5.37), pointLat, pointLon)).To(BeNumerically("~", 5, 1e-5))
+			Expect(geo.AlongTrackDistance(flindersLat, flindersLon, dms(306, 52, 5.37), pointLat, pointLon)).To(BeNumerically("~", 27.5, 1e-5))
+		})
+	})
+
+	It("should keep the spherical fast path within half a percent", func() {
+		spherical := geo.SphericalDistance(flindersLat, flindersLon, buninyongLat, buninyongLon)
+		Expect(spherical).To(BeNumerically("~", 54.972271, 0.005*54.972271))
+		lat, lon := geo.SphericalDestination(flindersLat, flindersLon, geo.SphericalBearing(flindersLat, flindersLon, buninyongLat, buninyongLon), spherical)
+		Expect(lat).To(BeNumerically("~", buninyongLat, 1e-9))
+		Expect(lon).To(BeNumerically("~", buninyongLon, 1e-9))
+	})
+})
@@ -0,0 +1,145 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// Body is a celestial body an aircraft can be seen crossing
+type Body int
+
+// Bodies whose positions are calculated locally
+const (
+	Sun Body = iota + 1
+	Moon
+)
+
+var bodyNames = map[Body]string{
+	Sun:  "sun",
+	Moon: "moon",
+}
+
+// String returns the configuration name of the body
+func (b Body) String() string {
+	return bodyNames[b]
+}
+
+// ParseBody parses a body name, returning false if it is not known
+func ParseBody(s string) (Body, bool) {
+	for b, name := range bodyNames {
+		if s == name {
+			return b, true
+		}
+	}
+	return 0, false
+}
+
+// SkyPosition is where a body appears to an observer
+type SkyPosition struct {
+	Azimuth   float64 // Degrees clockwise from north
+	Elevation float64 // Degrees above the horizon, without atmospheric refraction
+	Radius    float64 // Apparent radius of the disk in degrees
+}
+
+// At returns the position of the body in the sky of an observer at the given time.
+// Low precision algorithms are used, accurate to about an arc-minute for the sun and a few for the moon.
+func (b Body) At(t time.Time, lat, lon float64) SkyPosition {
+	if b == Moon {
+		return moonPosition(t, lat, lon)
+	}
+	return sunPosition(t, lat, lon)
+}
+
+// daysSinceJ2000 returns the days elapsed since 2000-01-01 12:00 UTC
+func daysSinceJ2000(t time.Time) float64 {
+	return float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5 - 2451545.0
+}
+
+// sunPosition uses the low precision formulae of the Astronomical Almanac
+func sunPosition(t time.Time, lat, lon float64) SkyPosition {
+	n := daysSinceJ2000(t)
+	meanLongitude := 280.460 + 0.9856474*n
+	g := rad(357.528 + 0.9856003*n)
+	lambda := rad(meanLongitude + 1.915*math.Sin(g) + 0.020*math.Sin(2*g))
+	obliquity := rad(23.439 - 0.0000004*n)
+	distance := 1.00014 - 0.01671*math.Cos(g) - 0.00014*math.Cos(2*g) // AU
+
+	ra := math.Atan2(math.Cos(obliquity)*math.Sin(lambda), math.Cos(lambda))
+	dec := math.Asin(math.Sin(obliquity) * math.Sin(lambda))
+	azimuth, elevation := horizontal(n, ra, dec, lat, lon)
+
+	return SkyPosition{Azimuth: azimuth, Elevation: elevation, Radius: 0.2666 / distance}
+}
+
+// moonPosition uses Paul Schlyter's orbital elements and main perturbation terms, corrected for parallax
+func moonPosition(t time.Time, lat, lon float64) SkyPosition {
+	d := daysSinceJ2000(t) + 1.5 // Schlyter counts from 1999-12-31 00:00 UTC
+
+	node := rad(125.1228 - 0.0529538083*d)
+	inclination := rad(5.1454)
+	perigee := rad(318.0634 + 0.1643573223*d)
+	const a, e = 60.2666, 0.054900 // Semi-major axis in earth radii and eccentricity
+	M := rad(115.3654 + 13.0649929509*d)
+
+	E := M + e*math.Sin(M)*(1+e*math.Cos(M))
+	for range 5 {
+		E -= (E - e*math.Sin(E) - M) / (1 - e*math.Cos(E))
+	}
+	xv := a * (math.Cos(E) - e)
+	yv := a * math.Sqrt(1-e*e) * math.Sin(E)
+	v := math.Atan2(yv, xv)
+	r := math.Hypot(xv, yv)
+
+	xh := r * (math.Cos(node)*math.Cos(v+perigee) - math.Sin(node)*math.Sin(v+perigee)*math.Cos(inclination))
+	yh := r * (math.Sin(node)*math.Cos(v+perigee) + math.Cos(node)*math.Sin(v+perigee)*math.Cos(inclination))
+	zh := r * math.Sin(v+perigee) * math.Sin(inclination)
+	eclLon := math.Atan2(yh, xh)
+	eclLat := math.Atan2(zh, math.Hypot(xh, yh))
+
+	sunM := rad(356.0470 + 0.9856002585*d)
+	sunL := rad(282.9404+4.70935e-5*d) + sunM
+	moonL := node + perigee + M
+	D := moonL - sunL
+	F := moonL - node
+
+	eclLon += rad(-1.274*math.Sin(M-2*D) + 0.658*math.Sin(2*D) - 0.186*math.Sin(sunM) -
+		0.059*math.Sin(2*M-2*D) - 0.057*math.Sin(M-2*D+sunM) + 0.053*math.Sin(M+2*D) +
+		0.046*math.Sin(2*D-sunM) + 0.041*math.Sin(M-sunM) - 0.035*math.Sin(D) -
+		0.031*math.Sin(M+sunM) - 0.015*math.Sin(2*F-2*D) + 0.011*math.Sin(M-4*D))
+	eclLat += rad(-0.173*math.Sin(F-2*D) - 0.055*math.Sin(M-F-2*D) - 0.046*math.Sin(M+F-2*D) +
+		0.033*math.Sin(F+2*D) + 0.017*math.Sin(2*M+F))
+	r += -0.58*math.Cos(M-2*D) - 0.46*math.Cos(2*D)
+
+	obliquity := rad(23.4393 - 3.563e-7*d)
+	xe := math.Cos(eclLon) * math.Cos(eclLat)
+	ye := math.Sin(eclLon)*math.Cos(eclLat)*math.Cos(obliquity) - math.Sin(eclLat)*math.Sin(obliquity)
+	ze := math.Sin(eclLon)*math.Cos(eclLat)*math.Sin(obliquity) + math.Sin(eclLat)*math.Cos(obliquity)
+	ra := math.Atan2(ye, xe)
+	dec := math.Atan2(ze, math.Hypot(xe, ye))
+
+	azimuth, elevation := horizontal(d-1.5, ra, dec, lat, lon)
+
+	// The moon is close enough for the observer's place on the earth to lower it by up to a degree
+	parallax := math.Asin(1 / r)
+	elevation -= parallax * math.Cos(rad(elevation)) * 180 / math.Pi
+
+	const moonRadius = 1737.4 / 6378.14 // In earth radii
+	return SkyPosition{Azimuth: azimuth, Elevation: elevation, Radius: math.Asin(moonRadius/r) * 180 / math.Pi}
+}
+
+// horizontal converts right ascension and declination in radians to azimuth and elevation in degrees
+func horizontal(n, ra, dec, lat, lon float64) (azimuth, elevation float64) {
+	siderealTime := rad(280.46061837 + 360.98564736629*n + lon)
+	hourAngle := siderealTime - ra
+	phi := rad(lat)
+
+	elevation = math.Asin(math.Sin(phi)*math.Sin(dec) + math.Cos(phi)*math.Cos(dec)*math.Cos(hourAngle))
+	azimuth = math.Atan2(-math.Cos(dec)*math.Sin(hourAngle),
+		math.Sin(dec)*math.Cos(phi)-math.Cos(dec)*math.Cos(hourAngle)*math.Sin(phi))
+	return math.Mod(azimuth*180/math.Pi+360, 360), elevation * 180 / math.Pi
+}
+
+// rad converts degrees to radians
+func rad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -2,54 +2,31 @@ package geo
 
 import "math"
 
-// CalculateDistance uses the Haversine formula to calculate the distance between two points on Earth.
+// CalculateDistance calculates the distance between two points on the WGS84 ellipsoid, see Inverse.
 // lat1, lon1 are the coordinates of the first point (user's location)
 // lat2, lon2 are the coordinates of the second point (aircraft's location)
 // Returns distance in kilometers
 func CalculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
-	const R = 6371 // Earth's radius in kilometers
-
-	// Convert degrees to radians
-	la1 := lat1 * math.Pi / 180
-	lo1 := lon1 * math.Pi / 180
-	la2 := lat2 * math.Pi / 180
-	lo2 := lon2 * math.Pi / 180
-
-	dlat := la2 - la1
-	dlon := lo2 - lo1
-
-	a := math.Pow(math.Sin(dlat/2), 2) + math.Cos(la1)*math.Cos(la2)*math.Pow(math.Sin(dlon/2), 2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-
-	return R * c
+	distance, _, _ := Inverse(lat1, lon1, lat2, lon2)
+	return distance
 }
 
-// CalculateBearing calculates the bearing from point 1 to point 2
+// CalculateBearing calculates the initial bearing of the shortest path from point 1 to point 2 on the WGS84 ellipsoid
 // lat1, lon1 are the coordinates of the first point (user's location)
 // lat2, lon2 are the coordinates of the second point (aircraft's location)
 // Returns bearing in degrees (0-360, where 0 is North, 90 is East, etc.)
 func CalculateBearing(lat1, lon1, lat2, lon2 float64) float64 {
-	// Convert degrees to radians
-	la1 := lat1 * math.Pi / 180
-	lo1 := lon1 * math.Pi / 180
-	la2 := lat2 * math.Pi / 180
-	lo2 := lon2 * math.Pi / 180
-
-	dlon := lo2 - lo1
-
-	y := math.Sin(dlon) * math.Cos(la2)
-	x := math.Cos(la1)*math.Sin(la2) - math.Sin(la1)*math.Cos(la2)*math.Cos(dlon)
-
-	bearing := math.Atan2(y, x) * 180 / math.Pi
-
-	// Convert to 0-360 range
-	if bearing < 0 {
-		bearing += 360
-	}
-
+	_, bearing, _ := Inverse(lat1, lon1, lat2, lon2)
 	return bearing
 }
 
+// Destination returns the point reached by travelling distance km from lat, lon along an initial bearing in degrees
+// on the WGS84 ellipsoid, see Direct
+func Destination(lat, lon, bearing, distance float64) (float64, float64) {
+	lat2, lon2, _ := Direct(lat, lon, bearing, distance)
+	return lat2, lon2
+}
+
 // BearingToDirection converts a bearing in degrees to a cardinal direction
 // Returns a string like "N", "NE", "E", "SE", "S", "SW", "W", "NW", "NNE", etc.
 func BearingToDirection(bearing float64) string {
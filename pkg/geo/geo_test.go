@@ -42,13 +42,15 @@ var _ = Describe("Geo Utils", func() {
 		It("should calculate distance between poles", func() {
 			// North pole to south pole
 			distance := geo.CalculateDistance(90.0, 0.0, -90.0, 0.0)
-			Expect(distance).To(BeNumerically("~", 20015.0, 10.0)) // Approximately 20,015 km (half Earth's circumference)
+			Expect(distance).To(BeNumerically("~", 20003.93, 0.01)) // Half a meridian, shorter than half the equator
+			Expect(geo.SphericalDistance(90.0, 0.0, -90.0, 0.0)).To(BeNumerically("~", 20015.0, 10.0))
 		})
 
 		It("should handle negative coordinates", func() {
 			// New York (40.7128, -74.0060) to London (51.5074, -0.1278)
 			distance := geo.CalculateDistance(40.7128, -74.0060, 51.5074, -0.1278)
-			Expect(distance).To(BeNumerically("~", 5570.0, 10.0)) // Approximately 5,570 km
+			Expect(distance).To(BeNumerically("~", 5585.2, 0.1)) // 5,585.23 km on the WGS84 ellipsoid
+			Expect(geo.SphericalDistance(40.7128, -74.0060, 51.5074, -0.1278)).To(BeNumerically("~", 5570.0, 10.0))
 		})
 	})
 
@@ -147,6 +149,30 @@ var _ = Describe("Geo Utils", func() {
 		})
 	})
 
+	Describe("Destination", func() {
+		It("should travel along the bearing", func() {
+			lat, lon := geo.Destination(51.5, 0.0, 90.0, 10.0)
+			Expect(geo.CalculateDistance(51.5, 0.0, lat, lon)).To(BeNumerically("~", 10.0, 0.01))
+			Expect(geo.CalculateBearing(51.5, 0.0, lat, lon)).To(BeNumerically("~", 90.0, 0.1))
+		})
+
+		It("should wrap across the international date line", func() {
+			_, lon := geo.Destination(0.0, 179.9, 90.0, 50.0)
+			Expect(lon).To(BeNumerically("~", -179.65, 0.01))
+		})
+	})
+
+	Describe("CalculateClosestApproach", func() {
+		It("should give the bearing to the closest point", func() {
+			// Flying north along a track 5 km east of the observer
+			lat, lon := geo.Destination(51.4, 0.0, 90.0, 5.0)
+			ca := geo.CalculateClosestApproach(51.5, 0.0, lat, lon, 0.0, 300.0)
+			Expect(ca.WillApproach).To(BeTrue())
+			Expect(ca.Distance).To(BeNumerically("~", 5.0, 0.1))
+			Expect(ca.Bearing).To(BeNumerically("~", 90.0, 1.0))
+		})
+	})
+
 	Describe("BearingToDirection", func() {
 		It("should convert north bearing to N", func() {
 			Expect(geo.BearingToDirection(0.0)).To(Equal("N"))
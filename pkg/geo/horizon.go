@@ -0,0 +1,155 @@
+package geo
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// visibilityStep is how far apart the projected positions of an aircraft are checked for coming into view
+const visibilityStep = 5 * time.Second
+
+// Reasons an aircraft cannot be seen, as returned by View.Hidden
+const (
+	HiddenByHorizon     = "below the horizon"
+	HiddenByFieldOfView = "outside the field of view"
+)
+
+// HorizonPoint is the lowest elevation that can be seen in one direction
+type HorizonPoint struct {
+	Azimuth   float64 // Degrees clockwise from north
+	Elevation float64 // Degrees above the horizontal
+}
+
+// Horizon is the observer's skyline of buildings, trees and hills.
+// It is interpolated linearly between points and wraps around through north.
+type Horizon []HorizonPoint
+
+// NewHorizon sorts the points of a skyline by azimuth
+func NewHorizon(points []HorizonPoint) Horizon {
+	h := slices.Clone(Horizon(points))
+	for i := range h {
+		h[i].Azimuth = math.Mod(h[i].Azimuth+360, 360)
+	}
+	slices.SortFunc(h, func(a, b HorizonPoint) int {
+		return cmp.Compare(a.Azimuth, b.Azimuth)
+	})
+	return h
+}
+
+// Elevation returns the lowest visible elevation at an azimuth, -90 without any points so nothing is hidden
+func (h Horizon) Elevation(azimuth float64) float64 {
+	switch len(h) {
+	case 0:
+		return -90
+	case 1:
+		return h[0].Elevation
+	}
+
+	azimuth = math.Mod(azimuth+360, 360)
+	i, found := slices.BinarySearchFunc(h, azimuth, func(p HorizonPoint, az float64) int {
+		return cmp.Compare(p.Azimuth, az)
+	})
+	if found {
+		return h[i].Elevation
+	}
+
+	// Interpolate from the point before to the one after, wrapping around north
+	before, after := h[(i-1+len(h))%len(h)], h[i%len(h)]
+	span := math.Mod(after.Azimuth-before.Azimuth+360, 360)
+	if span == 0 {
+		return before.Elevation
+	}
+	fraction := math.Mod(azimuth-before.Azimuth+360, 360) / span
+	return before.Elevation + fraction*(after.Elevation-before.Elevation)
+}
+
+// ReadHorizonCSV reads azimuth,elevation pairs in degrees, one per line, as exported by horizon survey apps.
+// A header line and lines starting with # are skipped, and any columns after the first two are ignored.
+func ReadHorizonCSV(r io.Reader) ([]HorizonPoint, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var points []HorizonPoint
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return points, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected azimuth,elevation", line)
+		}
+		azimuth, errAz := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		elevation, errEl := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if errAz != nil || errEl != nil {
+			if line == 1 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("line %d: expected azimuth,elevation in degrees, got %q", line, strings.Join(record, ","))
+		}
+		points = append(points, HorizonPoint{Azimuth: azimuth, Elevation: elevation})
+	}
+}
+
+// LoadHorizon reads a horizon survey CSV file, see ReadHorizonCSV
+func LoadHorizon(path string) ([]HorizonPoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	points, err := ReadHorizonCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return points, nil
+}
+
+// View is the part of the sky an observer can see: above their horizon and within their field of view
+type View struct {
+	Horizon     Horizon
+	Heading     float64 // Direction the observer is facing in degrees
+	FieldOfView float64 // Width of the view centred on Heading in degrees, 0 for all round
+}
+
+// IsSet reports whether the view is restricted at all
+func (v View) IsSet() bool {
+	return len(v.Horizon) > 0 || (v.FieldOfView > 0 && v.FieldOfView < 360)
+}
+
+// Hidden returns why a direction in the sky cannot be seen, or an empty string if it can
+func (v View) Hidden(azimuth, elevation float64) string {
+	if v.FieldOfView > 0 && v.FieldOfView < 360 && math.Abs(normalizeAngle(azimuth-v.Heading)) > v.FieldOfView/2 {
+		return HiddenByFieldOfView
+	}
+	if elevation < v.Horizon.Elevation(azimuth) {
+		return HiddenByHorizon
+	}
+	return ""
+}
+
+// TimeToVisible projects an aircraft along its track and returns how long until it comes into view,
+// and false if it stays hidden for the whole window
+func (v View) TimeToVisible(observer, aircraft Position, motion Motion, window time.Duration) (time.Duration, bool) {
+	for elapsed := time.Duration(0); elapsed <= window; elapsed += visibilityStep {
+		azimuth, elevation, _ := LookAngle(observer, motion.at(aircraft, elapsed))
+		if v.Hidden(azimuth, elevation) == "" {
+			return elapsed, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,211 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// Kalman filter tuning, distances in km and speeds in km/s
+const (
+	positionNoise     = 0.03  // Standard deviation of an ADS-B position
+	mlatNoise         = 0.15  // Standard deviation of a multilaterated position
+	speedNoise        = 0.002 // Standard deviation of a reported ground speed, about 4 knots
+	trackNoise        = 2.0   // Standard deviation of a reported track in degrees
+	accelerationNoise = 1e-5  // Spectral density of unmodelled acceleration in km²/s³, a few m/s² over a poll
+	unknownSpeed      = 0.3   // Standard deviation of the velocity before one is reported or measured, about 600 knots
+)
+
+// filterTimeout is the gap between fixes after which a filter starts again rather than coast across it
+const filterTimeout = 2 * time.Minute
+
+// Fix is a reported position of an aircraft
+type Fix struct {
+	Time      time.Time
+	Latitude  float64
+	Longitude float64
+	Speed     float64 // Ground speed in knots, 0 if unknown
+	Track     float64 // Degrees clockwise from north, 0 if unknown
+	MLAT      bool    // Multilaterated, so the position is noisier
+}
+
+// Estimate is the filtered state of an aircraft at a point in time
+type Estimate struct {
+	Time          time.Time
+	Latitude      float64
+	Longitude     float64
+	Speed         float64 // Ground speed in knots
+	Track         float64 // Degrees clockwise from north
+	PositionError float64 // Standard deviation of the position in km
+	SpeedError    float64 // Standard deviation of the velocity in knots
+}
+
+// KalmanFilter smooths the horizontal positions and velocity of one aircraft.
+// The state is a position and an east and north velocity, moving at constant velocity or turning at a given rate
+// between fixes. It is kept on a flat plane centred on the latest estimate, which is close enough for the few km an
+// aircraft moves between fixes.
+type KalmanFilter struct {
+	time     time.Time
+	lat, lon float64
+	x        [4]float64 // East and north offset from lat, lon and east and north velocity
+	p        [4][4]float64
+}
+
+// NewKalmanFilter starts a filter at a fix
+func NewKalmanFilter(fix Fix) *KalmanFilter {
+	k := &KalmanFilter{time: fix.Time, lat: fix.Latitude, lon: fix.Longitude}
+	k.p[0][0], k.p[1][1] = fixNoise(fix), fixNoise(fix)
+	k.p[2][2], k.p[3][3] = unknownSpeed*unknownSpeed, unknownSpeed*unknownSpeed
+	k.updateVelocity(fix)
+	return k
+}
+
+// Update advances the filter to a fix and corrects it with the reported position, speed and track.
+// A fix older than the current state is ignored, and the filter restarts after a long gap.
+func (k *KalmanFilter) Update(fix Fix, turnRate float64) {
+	if !fix.Time.After(k.time) {
+		return
+	}
+	if fix.Time.Sub(k.time) > filterTimeout {
+		*k = *NewKalmanFilter(fix)
+		return
+	}
+	k.x, k.p = k.predict(fix.Time.Sub(k.time), turnRate)
+	k.time = fix.Time
+
+	// Centre the plane on the predicted position to measure the fix from, then on the corrected position
+	k.lat, k.lon = k.offset(k.x[0], k.x[1])
+	k.x[0], k.x[1] = 0, 0
+	distance := CalculateDistance(k.lat, k.lon, fix.Latitude, fix.Longitude)
+	bearing := rad(CalculateBearing(k.lat, k.lon, fix.Latitude, fix.Longitude))
+	noise := fixNoise(fix)
+	k.update(0, distance*math.Sin(bearing), distance*math.Cos(bearing), [2][2]float64{{noise, 0}, {0, noise}})
+	k.updateVelocity(fix)
+	k.lat, k.lon = k.offset(k.x[0], k.x[1])
+	k.x[0], k.x[1] = 0, 0
+}
+
+// At extrapolates the filtered state to t without changing the filter
+func (k *KalmanFilter) At(t time.Time, turnRate float64) Estimate {
+	x, p := k.predict(max(t.Sub(k.time), 0), turnRate)
+	lat, lon := k.offset(x[0], x[1])
+	return Estimate{
+		Time:          t,
+		Latitude:      lat,
+		Longitude:     lon,
+		Speed:         math.Hypot(x[2], x[3]) * 3600 / 1.852,
+		Track:         math.Mod(math.Atan2(x[2], x[3])*180/math.Pi+360, 360),
+		PositionError: math.Sqrt((p[0][0] + p[1][1]) / 2),
+		SpeedError:    math.Sqrt((p[2][2]+p[3][3])/2) * 3600 / 1.852,
+	}
+}
+
+// offset returns the point east and north km from the centre of the plane
+func (k *KalmanFilter) offset(east, north float64) (float64, float64) {
+	return Destination(k.lat, k.lon, math.Atan2(east, north)*180/math.Pi, math.Hypot(east, north))
+}
+
+// predict returns the state and covariance after elapsed, turning at turnRate degrees per second
+func (k *KalmanFilter) predict(elapsed time.Duration, turnRate float64) ([4]float64, [4][4]float64) {
+	dt := elapsed.Seconds()
+
+	// Flying a circle the velocity rotates clockwise and the position follows its integral
+	f := [4][4]float64{{1, 0, dt, 0}, {0, 1, 0, dt}, {0, 0, 1, 0}, {0, 0, 0, 1}}
+	if omega := rad(turnRate); omega != 0 {
+		s, c := math.Sin(omega*dt), math.Cos(omega*dt)
+		f = [4][4]float64{
+			{1, 0, s / omega, (1 - c) / omega},
+			{0, 1, -(1 - c) / omega, s / omega},
+			{0, 0, c, s},
+			{0, 0, -s, c},
+		}
+	}
+
+	var x [4]float64
+	for i := range 4 {
+		for j := range 4 {
+			x[i] += f[i][j] * k.x[j]
+		}
+	}
+
+	var fp, p [4][4]float64
+	for i := range 4 {
+		for j := range 4 {
+			for l := range 4 {
+				fp[i][j] += f[i][l] * k.p[l][j]
+			}
+		}
+	}
+	for i := range 4 {
+		for j := range 4 {
+			for l := range 4 {
+				p[i][j] += fp[i][l] * f[j][l]
+			}
+		}
+	}
+
+	// White noise acceleration on each axis
+	q := accelerationNoise
+	for axis := range 2 {
+		p[axis][axis] += q * dt * dt * dt / 3
+		p[axis][axis+2] += q * dt * dt / 2
+		p[axis+2][axis] += q * dt * dt / 2
+		p[axis+2][axis+2] += q * dt
+	}
+	return x, p
+}
+
+// updateVelocity corrects the velocity with the reported speed and track, when both are known.
+// The speed is more precise along the track than the track is across it.
+func (k *KalmanFilter) updateVelocity(fix Fix) {
+	if fix.Speed <= 0 || fix.Track <= 0 {
+		return
+	}
+	speed := fix.Speed * 1.852 / 3600
+	track := rad(fix.Track)
+	sin, cos := math.Sin(track), math.Cos(track)
+	along, across := speedNoise*speedNoise, math.Pow(speed*rad(trackNoise), 2)
+	r := [2][2]float64{
+		{along*sin*sin + across*cos*cos, (along - across) * sin * cos},
+		{(along - across) * sin * cos, along*cos*cos + across*sin*sin},
+	}
+	k.update(2, speed*sin, speed*cos, r)
+}
+
+// update corrects the pair of state components starting at i with a measurement z0, z1 of covariance r
+func (k *KalmanFilter) update(i int, z0, z1 float64, r [2][2]float64) {
+	y := [2]float64{z0 - k.x[i], z1 - k.x[i+1]}
+	s := [2][2]float64{
+		{k.p[i][i] + r[0][0], k.p[i][i+1] + r[0][1]},
+		{k.p[i+1][i] + r[1][0], k.p[i+1][i+1] + r[1][1]},
+	}
+	det := s[0][0]*s[1][1] - s[0][1]*s[1][0]
+	if det == 0 {
+		return
+	}
+	inverse := [2][2]float64{{s[1][1] / det, -s[0][1] / det}, {-s[1][0] / det, s[0][0] / det}}
+
+	var gain [4][2]float64
+	for row := range 4 {
+		for col := range 2 {
+			gain[row][col] = k.p[row][i]*inverse[0][col] + k.p[row][i+1]*inverse[1][col]
+		}
+	}
+	for row := range 4 {
+		k.x[row] += gain[row][0]*y[0] + gain[row][1]*y[1]
+	}
+	var p [4][4]float64
+	for row := range 4 {
+		for col := range 4 {
+			p[row][col] = k.p[row][col] - gain[row][0]*k.p[i][col] - gain[row][1]*k.p[i+1][col]
+		}
+	}
+	k.p = p
+}
+
+// fixNoise returns the variance of a reported position in km²
+func fixNoise(fix Fix) float64 {
+	if fix.MLAT {
+		return mlatNoise * mlatNoise
+	}
+	return positionNoise * positionNoise
+}
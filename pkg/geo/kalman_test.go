@@ -0,0 +1,110 @@
+package geo_test
+
+import (
+	"math"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/geo"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KalmanFilter", func() {
+	start := time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC)
+	// MLAT positions scatter by a few hundred metres, alternating either side of the truth
+	jitter := []float64{0.3, -0.2, 0.25, -0.3, 0.2, -0.25}
+
+	// flyEast returns the true position t seconds after passing 51.5N 0.2W flying east at speed knots
+	flyEast := func(t, speed float64) (float64, float64) {
+		return geo.Destination(51.5, -0.2, 90, speed*1.852/3600*t)
+	}
+
+	It("should smooth jittery MLAT positions and measure the velocity", func() {
+		var k *geo.KalmanFilter
+		for i := range 12 {
+			t := float64(i * 10)
+			lat, lon := flyEast(t, 400)
+			lat, lon = geo.Destination(lat, lon, 0, jitter[i%len(jitter)])
+			fix := geo.Fix{Time: start.Add(time.Duration(t) * time.Second), Latitude: lat, Longitude: lon, MLAT: true}
+			if k == nil {
+				k = geo.NewKalmanFilter(fix)
+			} else {
+				k.Update(fix, 0)
+			}
+		}
+
+		// The last fix is 250 m off
+		estimate := k.At(start.Add(110*time.Second), 0)
+		lat, lon := flyEast(110, 400)
+		Expect(geo.CalculateDistance(lat, lon, estimate.Latitude, estimate.Longitude)).To(BeNumerically("<", 0.15))
+		Expect(estimate.Speed).To(BeNumerically("~", 400, 20))
+		Expect(estimate.Track).To(BeNumerically("~", 90, 3))
+		Expect(estimate.PositionError).To(BeNumerically("<", 0.15))
+	})
+
+	It("should keep the distance trend of an approaching aircraft steady", func() {
+		// A helicopter at 60 knots heading for the observer, whose raw distance goes up every other poll
+		observerLat, observerLon := flyEast(600, 60)
+		var k *geo.KalmanFilter
+		rawIncreases, previous, smoothed := 0, math.Inf(1), math.Inf(1)
+		for i := range 12 {
+			t := float64(i * 10)
+			lat, lon := flyEast(t, 60)
+			lat, lon = geo.Destination(lat, lon, 90, jitter[i%len(jitter)])
+			fix := geo.Fix{Time: start.Add(time.Duration(t) * time.Second), Latitude: lat, Longitude: lon, Speed: 60, Track: 90, MLAT: true}
+			if k == nil {
+				k = geo.NewKalmanFilter(fix)
+			} else {
+				k.Update(fix, 0)
+			}
+
+			raw := geo.CalculateDistance(observerLat, observerLon, lat, lon)
+			if raw > previous {
+				rawIncreases++
+			}
+			previous = raw
+
+			estimate := k.At(fix.Time, 0)
+			distance := geo.CalculateDistance(observerLat, observerLon, estimate.Latitude, estimate.Longitude)
+			Expect(distance).To(BeNumerically("<", smoothed), "poll %d", i)
+			smoothed = distance
+		}
+		Expect(rawIncreases).To(BeNumerically(">", 3))
+	})
+
+	It("should dead reckon from an old position to now", func() {
+		lat, lon := flyEast(0, 400)
+		k := geo.NewKalmanFilter(geo.Fix{Time: start, Latitude: lat, Longitude: lon, Speed: 400, Track: 90})
+
+		estimate := k.At(start.Add(30*time.Second), 0)
+		lat, lon = flyEast(30, 400)
+		Expect(geo.CalculateDistance(lat, lon, estimate.Latitude, estimate.Longitude)).To(BeNumerically("<", 0.01))
+		Expect(estimate.PositionError).To(BeNumerically(">", k.At(start, 0).PositionError))
+	})
+
+	It("should follow a turn at the given rate", func() {
+		// Half a minute into a rate one right turn from north, around a fix 1.86 km to the east
+		radius := 210 * 1.852 / 3600 / (3 * math.Pi / 180)
+		fixLat, fixLon := geo.Destination(51.5, 0, 90, radius)
+		k := geo.NewKalmanFilter(geo.Fix{Time: start, Latitude: 51.5, Longitude: 0, Speed: 210, Track: 360})
+
+		lat, lon := geo.Destination(fixLat, fixLon, 3*30-90, radius)
+		turning := k.At(start.Add(30*time.Second), 3)
+		straight := k.At(start.Add(30*time.Second), 0)
+		Expect(geo.CalculateDistance(lat, lon, turning.Latitude, turning.Longitude)).To(BeNumerically("<", 0.05))
+		Expect(geo.CalculateDistance(lat, lon, straight.Latitude, straight.Longitude)).To(BeNumerically(">", 1))
+		Expect(turning.Track).To(BeNumerically("~", 90, 1))
+	})
+
+	It("should ignore repeated fixes and restart after a long gap", func() {
+		k := geo.NewKalmanFilter(geo.Fix{Time: start, Latitude: 51.5, Longitude: 0, Speed: 400, Track: 90})
+		k.Update(geo.Fix{Time: start, Latitude: 52, Longitude: 0}, 0)
+		Expect(k.At(start, 0).Latitude).To(BeNumerically("~", 51.5, 1e-9))
+
+		k.Update(geo.Fix{Time: start.Add(10 * time.Minute), Latitude: 52, Longitude: 1}, 0)
+		estimate := k.At(start.Add(10*time.Minute), 0)
+		Expect(estimate.Latitude).To(BeNumerically("~", 52, 1e-9))
+		Expect(estimate.Longitude).To(BeNumerically("~", 1, 1e-9))
+	})
+})
@@ -0,0 +1,263 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// manoeuvreWindow is how much of an aircraft's recent track is used to estimate how it is manoeuvring
+const manoeuvreWindow = 2 * time.Minute
+
+// predictionStep is how far apart the points of a predicted path are checked before the closest is refined
+const predictionStep = 2 * time.Second
+
+// minTurnRate is the slowest turn in degrees per second told apart from noise on a straight track, a rate one turn is 3
+const minTurnRate = 0.5
+
+// Motion is how an aircraft is moving, for projecting where it will be.
+// Turns are flown at a constant rate until TargetTrack, straight legs follow great circles.
+type Motion struct {
+	Track          float64 // Degrees clockwise from north
+	Speed          float64 // Ground speed in knots
+	VerticalRate   float64 // Feet per minute, positive when climbing
+	TurnRate       float64 // Degrees per second, positive turning right
+	TargetTrack    float64 // Track the turn rolls out on, 0 to keep turning
+	TargetAltitude float64 // Altitude in feet the climb or descent levels off at, 0 if unknown
+}
+
+// at returns where an aircraft starting at p will be after elapsed
+func (m Motion) at(p Position, elapsed time.Duration) Position {
+	position, _ := m.fly(p, elapsed)
+	return position
+}
+
+// fly returns where an aircraft starting at p will be after elapsed and its track there
+func (m Motion) fly(p Position, elapsed time.Duration) (Position, float64) {
+	speed := m.Speed * 1.852 / 3600 // km/s
+	seconds := elapsed.Seconds()
+	lat, lon, track := p.Latitude, p.Longitude, m.Track
+
+	if m.TurnRate != 0 && seconds > 0 {
+		turning := seconds
+		if m.TargetTrack > 0 {
+			turning = min(turning, m.rollOut())
+		}
+		// A constant rate turn is a circle, small enough to fly on a flat earth
+		omega := rad(m.TurnRate)
+		from := rad(track)
+		to := from + omega*turning
+		east := speed / omega * (math.Cos(from) - math.Cos(to))
+		north := speed / omega * (math.Sin(to) - math.Sin(from))
+		lat, lon = Destination(lat, lon, math.Atan2(east, north)*180/math.Pi, math.Hypot(east, north))
+		track = math.Mod(to*180/math.Pi+360, 360)
+		seconds -= turning
+	}
+	if seconds > 0 {
+		// The track changes along a geodesic, and Direct gives it at the end for free
+		lat, lon, track = Direct(lat, lon, track, speed*seconds)
+	}
+
+	return Position{Latitude: lat, Longitude: lon, Altitude: m.altitude(p.Altitude, elapsed)}, track
+}
+
+// rollOut returns the seconds until the turn reaches TargetTrack
+func (m Motion) rollOut() float64 {
+	turn := m.TargetTrack - m.Track
+	if m.TurnRate < 0 {
+		turn = -turn
+	}
+	return math.Mod(turn+360, 360) / math.Abs(m.TurnRate)
+}
+
+// altitude returns the altitude in metres after climbing or descending from start for elapsed
+func (m Motion) altitude(start float64, elapsed time.Duration) float64 {
+	alt := start + FeetToMetres(m.VerticalRate*elapsed.Minutes())
+	if m.TargetAltitude > 0 {
+		target := FeetToMetres(m.TargetAltitude)
+		if (start <= target && alt > target) || (start >= target && alt < target) {
+			alt = target
+		}
+	}
+	return max(alt, 0)
+}
+
+// TrackSample is an observed position of an aircraft
+type TrackSample struct {
+	Time     time.Time
+	Position Position
+	Track    float64 // Reported track in degrees, 0 if unknown
+}
+
+// Manoeuvre is how an aircraft has been turning and climbing over its recent track
+type Manoeuvre struct {
+	TurnRate      float64 // Degrees per second, positive turning right
+	TurnRateError float64 // Standard error of TurnRate
+	VerticalRate  float64 // Feet per minute, positive when climbing
+}
+
+// EstimateManoeuvre fits the turn and vertical rates to the last couple of minutes of an aircraft's track, oldest first.
+// Reported tracks are used where known, otherwise the course between successive positions.
+// With only two courses the turn rate is as uncertain as it is large, without them the aircraft is assumed to fly straight.
+// Turns slower than half a degree a second are taken as noise on a straight track, but still widen the error.
+func EstimateManoeuvre(samples []TrackSample) Manoeuvre {
+	var m Manoeuvre
+	if len(samples) == 0 {
+		return m
+	}
+	latest := samples[len(samples)-1].Time
+	for len(samples) > 1 && latest.Sub(samples[0].Time) > manoeuvreWindow {
+		samples = samples[1:]
+	}
+
+	var times, courses, altitudes, altitudeTimes []float64
+	for i, s := range samples {
+		at := s.Time.Sub(latest).Seconds()
+		altitudes = append(altitudes, s.Position.Altitude)
+		altitudeTimes = append(altitudeTimes, at)
+
+		course := s.Track
+		if course <= 0 {
+			if i == 0 || CalculateDistance(samples[i-1].Position.Latitude, samples[i-1].Position.Longitude,
+				s.Position.Latitude, s.Position.Longitude) < 0.05 {
+				continue
+			}
+			prev := samples[i-1]
+			course = CalculateBearing(prev.Position.Latitude, prev.Position.Longitude, s.Position.Latitude, s.Position.Longitude)
+			at = (prev.Time.Sub(latest).Seconds() + at) / 2 // A chord of a steady turn points along the track at its middle
+		}
+		// Unwrap so a turn through north keeps counting up or down
+		if n := len(courses); n > 0 {
+			course = courses[n-1] + normalizeAngle(course-courses[n-1])
+		}
+		times = append(times, at)
+		courses = append(courses, course)
+	}
+
+	switch {
+	case len(courses) == 2:
+		m.TurnRate, _ = fitLine(times, courses)
+		m.TurnRateError = math.Abs(m.TurnRate)
+	case len(courses) > 2:
+		m.TurnRate, m.TurnRateError = fitLine(times, courses)
+	}
+	if math.Abs(m.TurnRate) < minTurnRate {
+		m.TurnRate = 0
+	}
+	if len(altitudes) > 1 {
+		climb, _ := fitLine(altitudeTimes, altitudes)
+		m.VerticalRate = climb / 0.3048 * 60
+	}
+	return m
+}
+
+// fitLine returns the least squares slope of ys against xs and its standard error
+func fitLine(xs, ys []float64) (slope, slopeError float64) {
+	n := float64(len(xs))
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i] / n
+		meanY += ys[i] / n
+	}
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sxx == 0 {
+		return 0, 0
+	}
+	slope = sxy / sxx
+	if len(xs) > 2 {
+		var residuals float64
+		for i := range xs {
+			r := ys[i] - meanY - slope*(xs[i]-meanX)
+			residuals += r * r
+		}
+		slopeError = math.Sqrt(residuals / (n - 2) / sxx)
+	}
+	return slope, slopeError
+}
+
+// PredictClosestApproach flies an aircraft along its turning, climbing path for up to window and returns where it
+// passes closest to the observer. The bounds cover turn rates within two standard errors, turnRateError, of
+// motion.TurnRate, roughly a 95% confidence interval.
+func PredictClosestApproach(observer, aircraft Position, motion Motion, turnRateError float64, window time.Duration) *ClosestApproach {
+	approach := closestAlong(observer, aircraft, motion, window)
+	approach.MinDistance, approach.MaxDistance = approach.Distance, approach.Distance
+	approach.Earliest, approach.Latest = approach.TimeToClosest, approach.TimeToClosest
+
+	if turnRateError > 0 {
+		for _, rate := range []float64{motion.TurnRate - 2*turnRateError, motion.TurnRate + 2*turnRateError} {
+			bound := motion
+			bound.TurnRate = rate
+			b := closestAlong(observer, aircraft, bound, window)
+			approach.MinDistance = min(approach.MinDistance, b.Distance)
+			approach.MaxDistance = max(approach.MaxDistance, b.Distance)
+			approach.Earliest = min(approach.Earliest, b.TimeToClosest)
+			approach.Latest = max(approach.Latest, b.TimeToClosest)
+		}
+	}
+	return approach
+}
+
+// closestAlong steps along an aircraft's predicted path and refines the point closest to the observer.
+// The steps use the spherical fast path, the refinement the ellipsoid.
+func closestAlong(observer, aircraft Position, motion Motion, window time.Duration) *ClosestApproach {
+	distance := func(elapsed time.Duration) float64 {
+		p := motion.at(aircraft, elapsed)
+		return CalculateDistance(observer.Latitude, observer.Longitude, p.Latitude, p.Longitude)
+	}
+	approximate := func(elapsed time.Duration) float64 {
+		p := motion.at(aircraft, elapsed)
+		return SphericalDistance(observer.Latitude, observer.Longitude, p.Latitude, p.Longitude)
+	}
+
+	closest, best := time.Duration(0), approximate(0)
+	for elapsed := predictionStep; elapsed <= window; elapsed += predictionStep {
+		if d := approximate(elapsed); d < best {
+			closest, best = elapsed, d
+		}
+	}
+	if closest > 0 && closest+predictionStep <= window {
+		closest = refine(distance, closest, predictionStep, window)
+	}
+
+	p, track := motion.fly(aircraft, closest)
+	approach := &ClosestApproach{
+		Distance:      distance(closest),
+		TimeToClosest: closest,
+		WillApproach:  closest > 0,
+		Bearing:       CalculateBearing(observer.Latitude, observer.Longitude, p.Latitude, p.Longitude),
+		TurnRate:      motion.TurnRate,
+	}
+
+	// Still closing at the end of the window, so carry on along the straight leg the aircraft is flying by then
+	if closest > 0 && closest+predictionStep > window {
+		beyond := CalculateClosestApproach(observer.Latitude, observer.Longitude, p.Latitude, p.Longitude, track, motion.Speed)
+		if beyond.WillApproach {
+			approach.Distance = beyond.Distance
+			approach.TimeToClosest += beyond.TimeToClosest
+			approach.Bearing = beyond.Bearing
+			p.Altitude = motion.altitude(aircraft.Altitude, approach.TimeToClosest)
+		}
+	}
+	approach.Altitude = int(math.Round(p.Altitude / 0.3048))
+	return approach
+}
+
+// refine narrows a minimum of f found by stepping down to a millisecond with a golden section search
+func refine(f func(time.Duration) float64, around, step, window time.Duration) time.Duration {
+	lo := max(around-step, 0)
+	hi := min(around+step, window)
+	const ratio = 0.6180339887
+	for hi-lo > time.Millisecond {
+		a := hi - time.Duration(float64(hi-lo)*ratio)
+		b := lo + time.Duration(float64(hi-lo)*ratio)
+		if f(a) < f(b) {
+			hi = b
+		} else {
+			lo = a
+		}
+	}
+	return (lo + hi) / 2
+}
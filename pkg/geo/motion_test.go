@@ -0,0 +1,243 @@
+package geo_test
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/geo"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Motion", func() {
+	// A right hand holding pattern at 210 knots: rate one turns of 3°/s, radius 1.86 km, around a fix at 51.5N 0.5W
+	const (
+		speed    = 210.0
+		rate     = 3.0
+		altitude = 2133.6 // 7000 ft
+	)
+	radius := speed * 1.852 / 3600 / (rate * math.Pi / 180)
+	start := time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	// inTurn returns where the aircraft is t seconds into the inbound turn, having started it heading north
+	inTurn := func(t float64) (lat, lon, track float64) {
+		track = math.Mod(rate*t, 360)
+		lat, lon = geo.Destination(51.5, -0.5, track-90, radius)
+		return lat, lon, track
+	}
+	samples := func(times []float64, withTracks bool, jitter float64) []geo.TrackSample {
+		var s []geo.TrackSample
+		for i, t := range times {
+			lat, lon, track := inTurn(t)
+			if jitter > 0 {
+				// MLAT positions wander by tens of metres, jitter is in km
+				lat, lon = geo.Destination(lat, lon, float64(i*137%360), jitter*float64(i%3-1))
+			}
+			if !withTracks {
+				track = 0
+			}
+			s = append(s, geo.TrackSample{
+				Time:     start.Add(time.Duration(t * float64(time.Second))),
+				Position: geo.Position{Latitude: lat, Longitude: lon, Altitude: altitude},
+				Track:    track,
+			})
+		}
+		return s
+	}
+
+	Describe("EstimateManoeuvre", func() {
+		It("should measure a rate one turn from reported tracks", func() {
+			m := geo.EstimateManoeuvre(samples([]float64{0, 10, 20, 30, 40}, true, 0))
+			Expect(m.TurnRate).To(BeNumerically("~", rate, 0.01))
+			Expect(m.TurnRateError).To(BeNumerically("<", 0.01))
+			Expect(m.VerticalRate).To(BeNumerically("~", 0, 1))
+		})
+
+		It("should measure a turn through north from positions alone", func() {
+			// From 100° to 190° back through north, on the second half of the turn
+			m := geo.EstimateManoeuvre(samples([]float64{90, 100, 110, 120, 130, 140}, false, 0))
+			Expect(m.TurnRate).To(BeNumerically("~", rate, 0.05))
+		})
+
+		It("should widen the error for jittery MLAT positions", func() {
+			m := geo.EstimateManoeuvre(samples([]float64{0, 10, 20, 30, 40, 50, 60}, false, 0.04))
+			Expect(m.TurnRate).To(BeNumerically("~", rate, 0.5))
+			Expect(m.TurnRateError).To(BeNumerically(">", 0.01))
+		})
+
+		It("should fit the vertical rate to the altitudes", func() {
+			s := samples([]float64{0, 10, 20}, true, 0)
+			for i := range s {
+				s[i].Position.Altitude = geo.FeetToMetres(7000 - 150*float64(i)) // 900 ft/min down
+			}
+			Expect(geo.EstimateManoeuvre(s).VerticalRate).To(BeNumerically("~", -900, 1))
+		})
+
+		It("should fly straight through a wandering track", func() {
+			s := samples([]float64{0, 10, 20}, true, 0)
+			s[0].Track, s[1].Track, s[2].Track = 90, 91, 92
+			m := geo.EstimateManoeuvre(s)
+			Expect(m.TurnRate).To(BeZero())
+			Expect(m.TurnRateError).To(BeNumerically("<", 0.1))
+		})
+
+		It("should only use the last couple of minutes", func() {
+			s := samples([]float64{0, 10, 20}, true, 0)
+			s[0].Time = s[0].Time.Add(-10 * time.Minute)
+			s[0].Track = 270
+			Expect(geo.EstimateManoeuvre(s).TurnRate).To(BeNumerically("~", rate, 0.01))
+		})
+	})
+
+	Describe("a hold in a VRS feed", func() {
+		// hold_testdata.json is one aircraft polled from a VRS AircraftList.json feed every four to seven seconds,
+		// flying a straight leg, a 180° right turn and the leg back, with TTrk set during the turn. It was
+		// synthesised, not recorded: positions are rounded to five decimals with a few metres of noise, Trak to a
+		// tenth of a degree and PosTime lags each poll by up to 200 ms, so the expectations below are read from
+		// the samples themselves.
+		var (
+			polls  []aircraft.Aircraft
+			sample func(i int) geo.TrackSample
+		)
+
+		BeforeEach(func() {
+			data, err := os.ReadFile("hold_testdata.json")
+			Expect(err).NotTo(HaveOccurred())
+			var acList aircraft.AircraftList
+			Expect(json.Unmarshal(data, &acList)).To(Succeed())
+			polls = acList.Aircraft
+			Expect(polls).To(HaveLen(32))
+
+			sample = func(i int) geo.TrackSample {
+				ac := polls[i]
+				return geo.TrackSample{
+					Time:     time.UnixMilli(ac.PosTime),
+					Position: geo.Position{Latitude: ac.Lat, Longitude: ac.Long, Altitude: geo.FeetToMetres(float64(ac.Alt))},
+					Track:    ac.Trak,
+				}
+			}
+		})
+
+		// turned returns the rate the aircraft turned at between two polls, from their reported tracks
+		turned := func(from, to int) float64 {
+			seconds := float64(polls[to].PosTime-polls[from].PosTime) / 1000
+			return math.Mod(polls[to].Trak-polls[from].Trak+360, 360) / seconds
+		}
+
+		It("should measure the turn rate shown by the reported tracks", func() {
+			// Polls 6 to 17 are all in the turn
+			var s []geo.TrackSample
+			for i := 6; i <= 17; i++ {
+				s = append(s, sample(i))
+			}
+			m := geo.EstimateManoeuvre(s)
+			Expect(m.TurnRate).To(BeNumerically("~", turned(6, 17), 0.05))
+			Expect(m.TurnRateError).To(BeNumerically("<", 0.05))
+			Expect(m.VerticalRate).To(BeNumerically("~", 0, 1))
+
+			// From positions alone the course between polls is noisier but still follows the turn
+			for i := range s {
+				s[i].Track = 0
+			}
+			Expect(geo.EstimateManoeuvre(s).TurnRate).To(BeNumerically("~", turned(6, 17), 0.3))
+		})
+
+		DescribeTable("should predict when the aircraft passes an observer under a later poll",
+			func(later int) {
+				// Half way round the turn at poll 9. Only polls from the turn are fitted, the straight leg
+				// before it would pull the fitted rate down.
+				var s []geo.TrackSample
+				for i := 6; i <= 9; i++ {
+					s = append(s, sample(i))
+				}
+				m := geo.EstimateManoeuvre(s)
+				now, then := sample(9), sample(later)
+				observer := geo.Position{Latitude: then.Position.Latitude, Longitude: then.Position.Longitude}
+
+				straight := geo.CalculateClosestApproach(observer.Latitude, observer.Longitude, now.Position.Latitude, now.Position.Longitude, now.Track, polls[9].Spd)
+				Expect(straight.Distance).To(BeNumerically(">", 1))
+
+				motion := geo.Motion{Track: now.Track, Speed: polls[9].Spd, TurnRate: m.TurnRate, TargetTrack: polls[9].TTrk}
+				approach := geo.PredictClosestApproach(observer, now.Position, motion, m.TurnRateError, 30*time.Minute)
+				Expect(approach.WillApproach).To(BeTrue())
+				Expect(approach.TimeToClosest).To(BeNumerically("~", then.Time.Sub(now.Time), time.Second))
+				Expect(approach.Distance).To(BeNumerically("<", 0.05))
+				Expect(approach.Altitude).To(Equal(polls[later].Alt))
+			},
+			Entry("later in the turn", 14),
+			Entry("on the leg back", 26),
+		)
+	})
+
+	Describe("PredictClosestApproach", func() {
+		It("should follow the turn instead of flying straight on", func() {
+			// Standing under the holding pattern, a quarter of the turn ahead of the aircraft
+			obsLat, obsLon, _ := inTurn(90 / rate)
+			observer := geo.Position{Latitude: obsLat, Longitude: obsLon}
+			lat, lon, track := inTurn(0)
+			aircraft := geo.Position{Latitude: lat, Longitude: lon, Altitude: altitude}
+
+			straight := geo.CalculateClosestApproach(obsLat, obsLon, lat, lon, track+360, speed)
+			Expect(straight.Distance).To(BeNumerically(">", 1.5))
+
+			m := geo.EstimateManoeuvre(samples([]float64{-40, -30, -20, -10, 0}, true, 0))
+			motion := geo.Motion{Track: track, Speed: speed, TurnRate: m.TurnRate}
+			approach := geo.PredictClosestApproach(observer, aircraft, motion, m.TurnRateError, 30*time.Minute)
+			Expect(approach.WillApproach).To(BeTrue())
+			Expect(approach.Distance).To(BeNumerically("<", 0.05))
+			Expect(approach.TimeToClosest).To(BeNumerically("~", 30*time.Second, time.Second))
+			Expect(approach.Altitude).To(Equal(7000))
+			Expect(approach.MinDistance).To(BeNumerically("<=", approach.Distance))
+			Expect(approach.MaxDistance).To(BeNumerically(">=", approach.Distance))
+		})
+
+		It("should roll out on the target track", func() {
+			// The inbound turn ends heading south, the observer is 5 km down the outbound leg
+			lat, lon, track := inTurn(0)
+			endLat, endLon, _ := inTurn(180 / rate)
+			obsLat, obsLon := geo.Destination(endLat, endLon, 180, 5)
+			observer := geo.Position{Latitude: obsLat, Longitude: obsLon}
+			aircraft := geo.Position{Latitude: lat, Longitude: lon, Altitude: altitude}
+
+			motion := geo.Motion{Track: track, Speed: speed, TurnRate: rate, TargetTrack: 180}
+			approach := geo.PredictClosestApproach(observer, aircraft, motion, 0, 30*time.Minute)
+			Expect(approach.Distance).To(BeNumerically("<", 0.05))
+			outbound := 5 / (speed * 1.852) * float64(time.Hour)
+			Expect(approach.TimeToClosest).To(BeNumerically("~", time.Minute+time.Duration(outbound), time.Second))
+			Expect(approach.Earliest).To(Equal(approach.TimeToClosest))
+		})
+
+		It("should level off at the target altitude", func() {
+			observer := geo.Position{Latitude: 51.5, Longitude: 0}
+			lat, lon := geo.Destination(51.5, 0, 180, 20)
+			aircraft := geo.Position{Latitude: lat, Longitude: lon, Altitude: geo.FeetToMetres(3000)}
+
+			motion := geo.Motion{Track: 0.01, Speed: 300, VerticalRate: 2000, TargetAltitude: 5000}
+			approach := geo.PredictClosestApproach(observer, aircraft, motion, 0, 30*time.Minute)
+			Expect(approach.TimeToClosest).To(BeNumerically("~", 130*time.Second, 2*time.Second))
+			Expect(approach.Altitude).To(Equal(5000))
+		})
+
+		It("should carry on along the straight leg past the window", func() {
+			observer := geo.Position{Latitude: 51.5, Longitude: 0}
+			lat, lon := geo.Destination(51.5, 0, 270, 100)
+			aircraft := geo.Position{Latitude: lat, Longitude: lon}
+			approach := geo.PredictClosestApproach(observer, aircraft, geo.Motion{Track: geo.CalculateBearing(lat, lon, 51.5, 0), Speed: 300}, 0, 5*time.Minute)
+			Expect(approach.WillApproach).To(BeTrue())
+			Expect(approach.Distance).To(BeNumerically("<", 0.1))
+			Expect(approach.TimeToClosest).To(BeNumerically("~", 100/(300*1.852)*float64(time.Hour), 5*time.Second))
+		})
+
+		It("should report an aircraft flying away as not approaching", func() {
+			observer := geo.Position{Latitude: 51.5, Longitude: 0}
+			lat, lon := geo.Destination(51.5, 0, 0, 20)
+			approach := geo.PredictClosestApproach(observer, geo.Position{Latitude: lat, Longitude: lon}, geo.Motion{Track: 10, Speed: 300}, 0, 30*time.Minute)
+			Expect(approach.WillApproach).To(BeFalse())
+			Expect(approach.Distance).To(BeNumerically("~", 20, 0.01))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package geo
+
+import "math"
+
+// Position is a point above the earth's surface
+type Position struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64 // Metres above sea level
+}
+
+// FeetToMetres converts an altitude in feet to metres
+func FeetToMetres(feet float64) float64 {
+	return feet * 0.3048
+}
+
+// LookAngle returns where an observer has to look to see a target: the azimuth in degrees clockwise from north,
+// the elevation in degrees above the horizon and the slant range in km. The curvature of the earth is taken into
+// account, so a distant aircraft sits lower in the sky than its altitude alone suggests.
+func LookAngle(observer, target Position) (azimuth, elevation, slantRange float64) {
+	e, n, u := enu(observer, target)
+	azimuth = math.Mod(math.Atan2(e, n)*180/math.Pi+360, 360)
+	elevation = math.Atan2(u, math.Hypot(e, n)) * 180 / math.Pi
+	slantRange = math.Sqrt(e*e + n*n + u*u)
+	return azimuth, elevation, slantRange
+}
+
+// AngularSeparation returns the angle in degrees between two directions in the sky given as azimuth and elevation
+func AngularSeparation(az1, el1, az2, el2 float64) float64 {
+	const rad = math.Pi / 180
+	dAz := (az2 - az1) * rad
+	dEl := (el2 - el1) * rad
+	a := math.Sin(dEl/2)*math.Sin(dEl/2) +
+		math.Cos(el1*rad)*math.Cos(el2*rad)*math.Sin(dAz/2)*math.Sin(dAz/2)
+	return 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a)) / rad
+}
+
+// enu returns the target's offset from the observer in km along the observer's local east, north and up axes.
+// Up is along the normal to the ellipsoid, the direction of a plumb line.
+func enu(observer, target Position) (e, n, u float64) {
+	ox, oy, oz := ecef(observer)
+	tx, ty, tz := ecef(target)
+	dx, dy, dz := tx-ox, ty-oy, tz-oz
+
+	lat := observer.Latitude * math.Pi / 180
+	lon := observer.Longitude * math.Pi / 180
+	e = -math.Sin(lon)*dx + math.Cos(lon)*dy
+	n = -math.Sin(lat)*math.Cos(lon)*dx - math.Sin(lat)*math.Sin(lon)*dy + math.Cos(lat)*dz
+	u = math.Cos(lat)*math.Cos(lon)*dx + math.Cos(lat)*math.Sin(lon)*dy + math.Sin(lat)*dz
+	return e, n, u
+}
+
+// ecef converts a position to earth-centred cartesian coordinates in km on the WGS84 ellipsoid
+func ecef(p Position) (x, y, z float64) {
+	e2 := wgs84F * (2 - wgs84F)
+	sinLat, cosLat := math.Sincos(p.Latitude * math.Pi / 180)
+	sinLon, cosLon := math.Sincos(p.Longitude * math.Pi / 180)
+	n := wgs84A / math.Sqrt(1-e2*sinLat*sinLat) // Radius of curvature in the prime vertical
+	h := p.Altitude / 1000
+	return (n + h) * cosLat * cosLon, (n + h) * cosLat * sinLon, (n*(1-e2) + h) * sinLat
+}
+
+// direction returns the unit vector along the observer's east, north and up axes pointing at an azimuth and elevation
+func direction(azimuth, elevation float64) (e, n, u float64) {
+	az := azimuth * math.Pi / 180
+	el := elevation * math.Pi / 180
+	return math.Cos(el) * math.Sin(az), math.Cos(el) * math.Cos(az), math.Sin(el)
+}
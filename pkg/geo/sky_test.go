@@ -0,0 +1,144 @@
+package geo_test
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/geo"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sky", func() {
+	Describe("LookAngle", func() {
+		It("should look straight up at an aircraft overhead", func() {
+			observer := geo.Position{Latitude: 51.5, Longitude: 0}
+			_, elevation, slantRange := geo.LookAngle(observer, geo.Position{Latitude: 51.5, Longitude: 0, Altitude: 10000})
+			Expect(elevation).To(BeNumerically("~", 90, 0.01))
+			Expect(slantRange).To(BeNumerically("~", 10, 0.001))
+		})
+
+		It("should lower distant aircraft for the curvature of the earth", func() {
+			observer := geo.Position{Latitude: 51.5, Longitude: 0, Altitude: 100}
+			lat, lon := geo.Destination(51.5, 0, 90, 200)
+			azimuth, elevation, _ := geo.LookAngle(observer, geo.Position{Latitude: lat, Longitude: lon, Altitude: 10000})
+			Expect(azimuth).To(BeNumerically("~", 90, 1))
+			// 2.8° on a flat earth, but the surface falls 3.1 km below the horizontal over 200 km
+			Expect(elevation).To(BeNumerically("~", 1.9, 0.1))
+		})
+	})
+
+	Describe("ephemeris", func() {
+		It("should place the sun at the solstice noon elevation", func() {
+			sun := geo.Sun.At(time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC), 51.48, 0)
+			Expect(sun.Azimuth).To(BeNumerically("~", 180, 1))
+			Expect(sun.Elevation).To(BeNumerically("~", 90-51.48+23.44, 0.1))
+			Expect(sun.Radius * 60).To(BeNumerically("~", 15.7, 0.1))
+		})
+
+		It("should put the moon over the sun during a total eclipse", func() {
+			// Greatest eclipse in Dallas on 8 April 2024
+			at := time.Date(2024, 4, 8, 18, 42, 0, 0, time.UTC)
+			sun := geo.Sun.At(at, 32.78, -96.80)
+			moon := geo.Moon.At(at, 32.78, -96.80)
+			separation := geo.AngularSeparation(sun.Azimuth, sun.Elevation, moon.Azimuth, moon.Elevation) * 60
+			Expect(separation).To(BeNumerically("<", 5))
+			Expect(moon.Radius).To(BeNumerically(">", sun.Radius))
+		})
+	})
+
+	Describe("PredictTransit", func() {
+		start := time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC)
+		var aircraft geo.Position
+		var motion geo.Motion
+
+		BeforeEach(func() {
+			// Flying east at 10,000 m through the line of sight to the sun a minute from now
+			sun := geo.Sun.At(start.Add(time.Minute), 51.5, 0)
+			lat, lon := geo.Destination(51.5, 0, sun.Azimuth, 10/math.Tan(sun.Elevation*math.Pi/180))
+			startLat, startLon := geo.Destination(lat, lon, 270, 450*1.852/60)
+			aircraft = geo.Position{Latitude: startLat, Longitude: startLon, Altitude: 10000}
+			motion = geo.Motion{Track: geo.CalculateBearing(startLat, startLon, lat, lon), Speed: 450}
+		})
+
+		It("should predict a transit across the disk", func() {
+			transit := geo.PredictTransit(geo.Sun, geo.Position{Latitude: 51.5, Longitude: 0}, aircraft, motion, start, 5*time.Minute, 0)
+			Expect(transit).ToNot(BeNil())
+			Expect(transit.Body).To(Equal("sun"))
+			Expect(transit.Time.Sub(start)).To(BeNumerically("~", time.Minute, time.Second))
+			Expect(transit.OnDisk()).To(BeTrue())
+			Expect(transit.Offset).To(BeNumerically("<", 0.05))
+			Expect(transit.String(start)).To(HavePrefix("in 1m 00s, "))
+		})
+
+		It("should label transits with or without a body", func() {
+			Expect(geo.Transit{Body: "moon"}.Label()).To(Equal("Moon transit"))
+			Expect(geo.Transit{}.Label()).To(Equal("Transit"))
+		})
+
+		It("should tell an observer off the centre line which way to move", func() {
+			lat, lon := geo.Destination(51.5, 0, 0, 0.1)
+			observer := geo.Position{Latitude: lat, Longitude: lon}
+			Expect(geo.PredictTransit(geo.Sun, observer, aircraft, motion, start, 5*time.Minute, 0)).To(BeNil())
+
+			transit := geo.PredictTransit(geo.Sun, observer, aircraft, motion, start, 5*time.Minute, 60)
+			Expect(transit).ToNot(BeNil())
+			Expect(transit.OnDisk()).To(BeFalse())
+			Expect(transit.Offset).To(BeNumerically("~", 0.1, 0.01))
+			Expect(geo.BearingToDirection(transit.OffsetBearing)).To(Equal("S"))
+		})
+
+		It("should not predict a transit of a body below the horizon", func() {
+			Expect(geo.PredictTransit(geo.Sun, geo.Position{Latitude: 51.5, Longitude: 180}, aircraft, motion, start, 5*time.Minute, 600)).To(BeNil())
+		})
+	})
+
+	Describe("View", func() {
+		horizon := geo.NewHorizon([]geo.HorizonPoint{{Azimuth: 90, Elevation: 20}, {Azimuth: 350, Elevation: 10}, {Azimuth: 10, Elevation: 0}})
+
+		It("should interpolate the horizon around north", func() {
+			Expect(horizon.Elevation(90)).To(Equal(20.0))
+			Expect(horizon.Elevation(50)).To(BeNumerically("~", 10, 0.001))
+			Expect(horizon.Elevation(0)).To(BeNumerically("~", 5, 0.001))
+			Expect(horizon.Elevation(220)).To(BeNumerically("~", 15, 0.001))
+		})
+
+		It("should hide aircraft below the horizon or outside the field of view", func() {
+			view := geo.View{Horizon: horizon, Heading: 90, FieldOfView: 90}
+			Expect(view.Hidden(90, 25)).To(BeEmpty())
+			Expect(view.Hidden(90, 15)).To(Equal(geo.HiddenByHorizon))
+			Expect(view.Hidden(150, 60)).To(Equal(geo.HiddenByFieldOfView))
+			Expect(geo.View{FieldOfView: 90}.Hidden(315, 0)).To(BeEmpty())
+		})
+
+		It("should predict when an aircraft comes into view", func() {
+			// 20 km south heading north at 360 knots, only visible to the north
+			view := geo.View{FieldOfView: 90}
+			lat, lon := geo.Destination(51.5, 0, 180, 20)
+			aircraft := geo.Position{Latitude: lat, Longitude: lon, Altitude: 3000}
+			in, visible := view.TimeToVisible(geo.Position{Latitude: 51.5, Longitude: 0}, aircraft, geo.Motion{Track: 0, Speed: 360}, 10*time.Minute)
+			Expect(visible).To(BeTrue())
+			Expect(in).To(BeNumerically("~", 110*time.Second, 5*time.Second))
+
+			_, visible = view.TimeToVisible(geo.Position{Latitude: 51.5, Longitude: 0}, aircraft, geo.Motion{Track: 180, Speed: 360}, 10*time.Minute)
+			Expect(visible).To(BeFalse())
+		})
+
+		It("should read a horizon survey", func() {
+			points, err := geo.ReadHorizonCSV(strings.NewReader("azimuth,elevation\n# Surveyed from the garden\n0,5.5\n 90, 12,chimney\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(points).To(Equal([]geo.HorizonPoint{{Azimuth: 0, Elevation: 5.5}, {Azimuth: 90, Elevation: 12}}))
+
+			_, err = geo.ReadHorizonCSV(strings.NewReader("0,5\nnorth,5\n"))
+			Expect(err).To(MatchError(ContainSubstring("line 2")))
+		})
+	})
+
+	It("should format a countdown to the second", func() {
+		Expect(geo.FormatCountdown(45 * time.Second)).To(Equal("45s"))
+		Expect(geo.FormatCountdown(83 * time.Second)).To(Equal("1m 23s"))
+		Expect(geo.FormatCountdown(62 * time.Minute)).To(Equal("1h 02m"))
+	})
+})
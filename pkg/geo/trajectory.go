@@ -8,17 +8,31 @@ import (
 
 // ClosestApproach represents the predicted closest approach of an aircraft
 type ClosestApproach struct {
-	Distance      float64       // Distance at closest approach in km
-	TimeToClosest time.Duration // Time until closest approach
-	WillApproach  bool          // True if aircraft is getting closer
+	Distance      float64       `json:"distance_km"`     // Distance at closest approach in km
+	TimeToClosest time.Duration `json:"time_to_closest"` // Time until closest approach
+	WillApproach  bool          `json:"will_approach"`   // True if aircraft is getting closer
+	Bearing       float64       `json:"bearing"`         // Bearing from the observer to the closest point in degrees
+	// Set by PredictClosestApproach
+	Altitude    int           `json:"altitude,omitempty"`        // Predicted altitude in feet at the closest point
+	TurnRate    float64       `json:"turn_rate,omitempty"`       // Degrees per second the prediction assumed, positive turning right
+	MinDistance float64       `json:"min_distance_km,omitempty"` // Confidence bounds on Distance
+	MaxDistance float64       `json:"max_distance_km,omitempty"`
+	Earliest    time.Duration `json:"earliest,omitempty"` // Confidence bounds on TimeToClosest
+	Latest      time.Duration `json:"latest,omitempty"`
+	// Set by the caller when the observer's view is restricted, see View.TimeToVisible
+	WillBeVisible bool          `json:"will_be_visible,omitempty"` // The aircraft is or will be in view within the prediction window
+	TimeToVisible time.Duration `json:"time_to_visible,omitempty"` // Time until it comes into view, 0 if it already is
 }
 
 // CalculateClosestApproach predicts when an aircraft will be closest to a location
-// based on current position, heading, and speed.
+// based on current position, heading, and speed, flying straight along a geodesic.
 func CalculateClosestApproach(observerLat, observerLon, aircraftLat, aircraftLon, heading, speedKnots float64) *ClosestApproach {
 	// Convert speed from knots to km/h
 	speedKmh := speedKnots * 1.852
 
+	// Without an approach the closest point is where the aircraft is now
+	currentBearing := CalculateBearing(observerLat, observerLon, aircraftLat, aircraftLon)
+
 	// If speed is negligible, aircraft is not moving
 	if speedKmh < 1.0 {
 		currentDist := CalculateDistance(observerLat, observerLon, aircraftLat, aircraftLon)
@@ -26,65 +40,33 @@ func CalculateClosestApproach(observerLat, observerLon, aircraftLat, aircraftLon
 			Distance:      currentDist,
 			TimeToClosest: 0,
 			WillApproach:  false,
+			Bearing:       currentBearing,
 		}
 	}
 
-	// Calculate current distance and bearing from aircraft to observer
+	// Find the point on the aircraft's geodesic nearest the observer
 	currentDistance := CalculateDistance(observerLat, observerLon, aircraftLat, aircraftLon)
-	bearingToObserver := CalculateBearing(aircraftLat, aircraftLon, observerLat, observerLon)
-
-	// Calculate the angle between aircraft heading and bearing to observer
-	// This tells us if the aircraft is flying towards or away from the observer
-	angleDiff := math.Abs(normalizeAngle(bearingToObserver - heading))
-
-	// If angle is > 90 degrees, aircraft is flying away
-	if angleDiff > 90 {
-		return &ClosestApproach{
-			Distance:      currentDistance,
-			TimeToClosest: 0,
-			WillApproach:  false,
-		}
-	}
+	distanceAlongPath, crossTrack := intercept(aircraftLat, aircraftLon, heading, observerLat, observerLon)
 
-	// Calculate closest approach using vector math
-	// Convert heading to radians
-	headingRad := heading * math.Pi / 180.0
-
-	// Project the observer's position onto the aircraft's flight path
-	// Using the cosine of the angle between the current position vector and heading
-	angleToObserver := bearingToObserver * math.Pi / 180.0
-
-	// Distance along flight path to closest point
-	distanceAlongPath := currentDistance * math.Cos((angleToObserver-headingRad))
-
-	// If negative, we've already passed the closest point
+	// If negative, we've already passed the closest point and the aircraft is flying away
 	if distanceAlongPath < 0 {
 		return &ClosestApproach{
 			Distance:      currentDistance,
 			TimeToClosest: 0,
 			WillApproach:  false,
+			Bearing:       currentBearing,
 		}
 	}
 
-	// Perpendicular distance from flight path to observer (closest approach distance)
-	closestDistance := currentDistance * math.Abs(math.Sin(angleToObserver-headingRad))
-
 	// Time to closest approach
-	if speedKmh > 0 {
-		timeHours := distanceAlongPath / speedKmh
-		timeToClosest := time.Duration(timeHours * float64(time.Hour))
-
-		return &ClosestApproach{
-			Distance:      closestDistance,
-			TimeToClosest: timeToClosest,
-			WillApproach:  true,
-		}
-	}
+	timeHours := distanceAlongPath / speedKmh
+	closestLat, closestLon := Destination(aircraftLat, aircraftLon, heading, distanceAlongPath)
 
 	return &ClosestApproach{
-		Distance:      currentDistance,
-		TimeToClosest: 0,
-		WillApproach:  false,
+		Distance:      math.Abs(crossTrack),
+		TimeToClosest: time.Duration(timeHours * float64(time.Hour)),
+		WillApproach:  true,
+		Bearing:       CalculateBearing(observerLat, observerLon, closestLat, closestLon),
 	}
 }
 
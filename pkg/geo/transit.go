@@ -0,0 +1,131 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// transitStep is how far apart the projected positions of an aircraft are checked before the closest is refined
+const transitStep = time.Second
+
+// Transit is a predicted pass of an aircraft in front of the sun or moon
+type Transit struct {
+	Body          string    `json:"body"`
+	Time          time.Time `json:"time"`           // When the aircraft is closest to the centre of the disk
+	Separation    float64   `json:"separation"`     // Arc-minutes between the aircraft and the centre of the disk
+	Radius        float64   `json:"radius"`         // Apparent radius of the disk in arc-minutes
+	Azimuth       float64   `json:"azimuth"`        // Where to look, in degrees clockwise from north
+	Elevation     float64   `json:"elevation"`      // Where to look, in degrees above the horizon
+	Offset        float64   `json:"offset_km"`      // How far to move for the aircraft to cross the centre of the disk
+	OffsetBearing float64   `json:"offset_bearing"` // Which way to move in degrees
+}
+
+// OnDisk reports whether the aircraft is predicted to cross the disk rather than pass close to it
+func (t Transit) OnDisk() bool {
+	return t.Separation <= t.Radius
+}
+
+// String describes the transit relative to now, e.g. "in 1m 23s, 4.2′ from centre, look SW 35° up"
+func (t Transit) String(now time.Time) string {
+	s := fmt.Sprintf("in %s, %.1f′ from centre, look %s %.0f° up",
+		FormatCountdown(t.Time.Sub(now)), t.Separation, BearingToDirection(t.Azimuth), t.Elevation)
+	if t.Offset >= 0.05 {
+		s += fmt.Sprintf(", move %.1f km %s for a central transit", t.Offset, BearingToDirection(t.OffsetBearing))
+	}
+	return s
+}
+
+// Label names the transit for display, e.g. "Sun transit", or just "Transit" when the body is unknown
+func (t Transit) Label() string {
+	if t.Body == "" {
+		return "Transit"
+	}
+	first, size := utf8.DecodeRuneInString(t.Body)
+	return string(unicode.ToUpper(first)) + t.Body[size:] + " transit"
+}
+
+// PredictTransit projects an aircraft along its track for window and returns its closest pass in front of body.
+// It returns nil if the body is below the horizon throughout or the aircraft never comes within maxSeparation
+// arc-minutes of the centre of its disk.
+//
+// The best observer offset is the nearest point to the observer on the line traced over the ground by the
+// aircraft's shadow against the body, from where the aircraft crosses the centre of the disk.
+func PredictTransit(body Body, observer, aircraft Position, motion Motion, start time.Time, window time.Duration, maxSeparation float64) *Transit {
+	separation := func(elapsed time.Duration) float64 {
+		sky := body.At(start.Add(elapsed), observer.Latitude, observer.Longitude)
+		az, el, _ := LookAngle(observer, motion.at(aircraft, elapsed))
+		return AngularSeparation(az, el, sky.Azimuth, sky.Elevation) * 60
+	}
+	offset := func(elapsed time.Duration) float64 {
+		e, n := centreLine(body, observer, motion.at(aircraft, elapsed), start.Add(elapsed))
+		return math.Hypot(e, n)
+	}
+
+	closest, nearest := time.Duration(-1), time.Duration(-1)
+	bestSeparation, bestOffset := math.Inf(1), math.Inf(1)
+	for elapsed := time.Duration(0); elapsed <= window; elapsed += transitStep {
+		sky := body.At(start.Add(elapsed), observer.Latitude, observer.Longitude)
+		if sky.Elevation <= 0 {
+			continue
+		}
+		if s := separation(elapsed); s < bestSeparation {
+			closest, bestSeparation = elapsed, s
+		}
+		if o := offset(elapsed); o < bestOffset {
+			nearest, bestOffset = elapsed, o
+		}
+	}
+	if closest < 0 {
+		return nil
+	}
+
+	closest = refine(separation, closest, transitStep, window)
+	at := start.Add(closest)
+	sky := body.At(at, observer.Latitude, observer.Longitude)
+	t := &Transit{
+		Body:       body.String(),
+		Time:       at,
+		Separation: separation(closest),
+		Radius:     sky.Radius * 60,
+		Azimuth:    sky.Azimuth,
+		Elevation:  sky.Elevation,
+	}
+	if t.Separation > max(maxSeparation, t.Radius) {
+		return nil
+	}
+
+	nearest = refine(offset, nearest, transitStep, window)
+	e, n := centreLine(body, observer, motion.at(aircraft, nearest), start.Add(nearest))
+	t.Offset = math.Hypot(e, n)
+	t.OffsetBearing = math.Mod(math.Atan2(e, n)*180/math.Pi+360, 360)
+	return t
+}
+
+// centreLine returns the east and north offset in km from the observer to the point on the ground from where the
+// aircraft appears in front of the centre of the body's disk
+func centreLine(body Body, observer, aircraft Position, t time.Time) (e, n float64) {
+	sky := body.At(t, observer.Latitude, observer.Longitude)
+	ae, an, au := enu(observer, aircraft)
+	be, bn, bu := direction(sky.Azimuth, sky.Elevation)
+	if bu <= 0 {
+		return math.Inf(1), math.Inf(1)
+	}
+	s := au / bu
+	return ae - s*be, an - s*bn
+}
+
+// FormatCountdown formats a duration to the second, e.g. "45s", "1m 23s" or "1h 02m"
+func FormatCountdown(d time.Duration) string {
+	d = max(d.Round(time.Second), 0)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/geo"
+	"go.uber.org/zap"
+)
+
+// defaultViewableDistance is used when notification.viewable_distance is not set
+const defaultViewableDistance = 15.0
+
+// headsUp is an alert scheduled ahead of an aircraft's predicted closest approach
+type headsUp struct {
+	timer *time.Timer
+	d     *detection.Detection // Latest detection, refreshed every poll
+	fired bool
+}
+
+// scheduleHeadsUp schedules, moves or cancels the heads-up alert of an aircraft from its latest prediction.
+// Each pass raises at most one alert. It is cancelled if the aircraft turns away or will no longer pass
+// within the viewable distance.
+func (m *Monitor) scheduleHeadsUp(aircraftID string, d *detection.Detection) {
+	approach := d.ClosestApproach
+	passing := approach != nil && approach.WillApproach && approach.TimeToClosest > 0 &&
+		approach.Distance <= m.viewableDistance()
+
+	m.headsUpMutex.Lock()
+	defer m.headsUpMutex.Unlock()
+
+	h, exists := m.headsUps[aircraftID]
+	if !passing {
+		if exists {
+			m.cancelHeadsUp(aircraftID, h)
+		}
+		return
+	}
+	if exists && h.fired {
+		return
+	}
+
+	due := time.Until(d.Time.Add(approach.TimeToClosest - m.config.Notification.HeadsUp.LeadTime))
+	if !exists {
+		h = &headsUp{d: d}
+		h.timer = time.AfterFunc(due, func() { m.fireHeadsUp(aircraftID, h) })
+		m.headsUps[aircraftID] = h
+		m.logger.Info("Heads-up scheduled",
+			zap.String("callsign", d.Callsign()),
+			zap.Duration("in", max(due, 0)),
+			zap.Float64("closest_km", approach.Distance))
+		return
+	}
+	h.d = d
+	h.timer.Reset(due)
+}
+
+// cancelHeadsUp forgets an aircraft's heads-up, stopping its timer if it has not fired yet.
+// It is called with headsUpMutex held.
+func (m *Monitor) cancelHeadsUp(aircraftID string, h *headsUp) {
+	delete(m.headsUps, aircraftID)
+	if !h.fired {
+		h.timer.Stop()
+		m.logger.Info("Heads-up cancelled", zap.String("callsign", h.d.Callsign()))
+	}
+}
+
+// cancelHeadsUps cancels the heads-up of every aircraft in ids
+func (m *Monitor) cancelHeadsUps(ids []string) {
+	m.headsUpMutex.Lock()
+	defer m.headsUpMutex.Unlock()
+
+	for _, id := range ids {
+		if h, exists := m.headsUps[id]; exists {
+			m.cancelHeadsUp(id, h)
+		}
+	}
+}
+
+// stopHeadsUps cancels every pending heads-up and waits for any being sent
+func (m *Monitor) stopHeadsUps() {
+	m.headsUpMutex.Lock()
+	for id, h := range m.headsUps {
+		m.cancelHeadsUp(id, h)
+	}
+	m.headsUpMutex.Unlock()
+
+	m.headsUpWG.Wait()
+}
+
+// fireHeadsUp sends the alert with the prediction brought forward to now
+func (m *Monitor) fireHeadsUp(aircraftID string, h *headsUp) {
+	m.headsUpMutex.Lock()
+	if m.headsUps[aircraftID] != h || h.fired {
+		// Cancelled or rescheduled after the timer had already fired
+		m.headsUpMutex.Unlock()
+		return
+	}
+	h.fired = true
+	latest := h.d
+	m.headsUpWG.Add(1)
+	m.headsUpMutex.Unlock()
+	defer m.headsUpWG.Done()
+
+	approach := *latest.ClosestApproach
+	closestAt := latest.Time.Add(approach.TimeToClosest)
+	approach.TimeToClosest = max(time.Until(closestAt), 0)
+
+	d := *latest
+	d.Time = time.Now()
+	d.ClosestApproach = &approach
+	d.Alert = detection.AlertHeadsUp
+	d.Band = ""
+	d.Passage = &detection.Passage{
+		Time:      closestAt,
+		Distance:  approach.Distance,
+		Direction: geo.BearingToDirection(approach.Bearing),
+		Altitude:  d.Aircraft.Alt,
+		Overhead:  approach.Distance <= m.overheadDistance(),
+	}
+
+	m.logger.Info("Heads-up", zap.String("callsign", d.Callsign()), zap.String("alert", d.HeadsUp()))
+	if err := m.notifier.Send(&d); err != nil {
+		m.logger.Error("Failed to send heads-up notification", zap.String("callsign", d.Callsign()), zap.Error(err))
+	}
+}
+
+// viewableDistance returns the distance in km within which a predicted closest approach is worth a heads-up
+func (m *Monitor) viewableDistance() float64 {
+	if m.config.Notification.ViewableDistance > 0 {
+		return m.config.Notification.ViewableDistance
+	}
+	return defaultViewableDistance
+}
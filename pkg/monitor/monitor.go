@@ -1,15 +1,21 @@
 package monitor
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"math"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/lyarwood/godar/pkg/aircraft"
 	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/events"
 	"github.com/lyarwood/godar/pkg/fetch"
 	"github.com/lyarwood/godar/pkg/geo"
+	"github.com/lyarwood/godar/pkg/mqtt"
 	"github.com/lyarwood/godar/pkg/notification"
 
 	"go.uber.org/zap"
@@ -28,14 +34,43 @@ type Fetcher interface {
 
 // Notifier defines the interface for sending notifications
 type Notifier interface {
-	Send(callsign, aircraftType string, altitude int, speed float64, distance float64, direction string, heading float64, aircraftLat, aircraftLon, observerLat, observerLon, userHeading float64, previousDistance ...float64) error
+	Send(d *detection.Detection) error
 }
 
-// AircraftTracker tracks aircraft distance history
+// flusher is implemented by notifiers that hold a poll's notifications back, e.g. to summarise bursts
+type flusher interface {
+	Flush() error
+}
+
+// defaultOverheadDistance is used when notification.overhead_distance is not set
+const defaultOverheadDistance = 2.0
+
+// maxTrackPoints is the number of recent positions kept per aircraft
+const maxTrackPoints = 20
+
+// AircraftTracker tracks per-aircraft state from which events are derived
 type AircraftTracker struct {
+	Aircraft     aircraft.Aircraft // Last reported state
 	LastDistance float64
+	MinDistance  float64
 	LastSeen     time.Time
+	PreviousSeen time.Time // When the aircraft was seen on the poll before LastSeen
+	LastNotified time.Time
 	Notified     bool
+	Approaching  bool // Distance decreased on the last poll
+	Overhead     bool // Within the overhead distance
+	Lost         bool // Missing from the most recent fetch
+	Squawk       aircraft.SqkValue
+	AltitudeBand int
+	Track        []detection.TrackPoint // Recent positions, oldest first
+	// Closest approach mode, see passAlert
+	Passing  bool              // The approaching alert was sent and the closest point has not been passed yet
+	Farthest float64           // Farthest distance since the last pass
+	Closest  detection.Passage // Closest point of the current pass
+	// Distance bands, see bandAlert
+	Bands int // Number of bands entered, counting inwards from the outermost
+	// Transit prediction, see transitAlert
+	Transits map[string]time.Time // Latest predicted transit time, by body
 }
 
 // Monitor represents the aircraft monitoring service
@@ -50,12 +85,30 @@ type Monitor struct {
 	stopChan        chan struct{}
 	aircraftHistory map[string]*AircraftTracker // Key: ICAO or callsign
 	historyMutex    sync.RWMutex
+	categoryFilter  *aircraft.CategoryFilter
+	view            geo.View                     // Part of the sky the observer can see
+	bands           []config.BandConfig          // Outermost first
+	filters         map[string]*geo.KalmanFilter // Key: ICAO or callsign, guarded by historyMutex
+	headsUps        map[string]*headsUp          // Key: ICAO or callsign
+	headsUpMutex    sync.Mutex
+	headsUpWG       sync.WaitGroup // Heads-up alerts being sent
+	bus             *events.Bus
+	closers         []func() // Release outputs once monitoring has stopped
 }
 
 // NewMonitorWithDeps creates a new monitoring service with injected dependencies
 func NewMonitorWithDeps(cfg *config.Config, logger *zap.Logger, fetcher Fetcher, notifier Notifier) (*Monitor, error) {
+	categoryFilter, err := aircraft.NewCategoryFilter(cfg.Filters.Species, cfg.Filters.WTC, cfg.Filters.EngineType)
+	if err != nil {
+		return nil, err
+	}
+	view, err := newView(cfg.Location)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Monitor{
+	m := &Monitor{
 		config:          cfg,
 		fetcher:         fetcher,
 		notifier:        notifier,
@@ -64,8 +117,25 @@ func NewMonitorWithDeps(cfg *config.Config, logger *zap.Logger, fetcher Fetcher,
 		cancel:          cancel,
 		stopChan:        make(chan struct{}),
 		aircraftHistory: make(map[string]*AircraftTracker),
+		filters:         make(map[string]*geo.KalmanFilter),
 		historyMutex:    sync.RWMutex{},
-	}, nil
+		categoryFilter:  categoryFilter,
+		view:            view,
+		bands:           slices.Clone(cfg.Notification.Bands),
+		headsUps:        make(map[string]*headsUp),
+		bus:             events.NewBus(),
+	}
+	slices.SortFunc(m.bands, func(a, b config.BandConfig) int {
+		return cmp.Compare(b.Distance, a.Distance)
+	})
+	m.bus.Subscribe(m.logEvent)
+
+	return m, nil
+}
+
+// Events returns the bus on which aircraft events are published
+func (m *Monitor) Events() *events.Bus {
+	return m.bus
 }
 
 // NewMonitor creates a new monitoring service
@@ -95,9 +165,34 @@ func NewMonitor(cfg *config.Config, logger *zap.Logger) (*Monitor, error) {
 		fetcher.SetAuth(cfg.Server.Username, cfg.Server.Password)
 	}
 
-	notifier := notification.NewNotifier(cfg.Notification.Enabled, cfg.Notification.Duration, logger, cfg.Notification.ViewableDistance, cfg.Notification.PredictionWindow)
+	notifier, err := notification.NewFromConfig(cfg.Notification, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create notifiers: %w", err)
+	}
+
+	var publisher *mqtt.Publisher
+	if cfg.MQTT.Enabled {
+		publisher, err = mqtt.NewPublisher(cfg.MQTT, logger)
+		if err != nil {
+			return nil, err
+		}
+		notifier.Add(notification.BackendMQTT, publisher)
+	}
+
+	m, err := NewMonitorWithDeps(cfg, logger, fetcher, notifier)
+	if err != nil {
+		notifier.Close()
+		return nil, err
+	}
+
+	if publisher != nil {
+		m.bus.Subscribe(publisher.HandleEvent)
+		m.bus.SubscribeSnapshots(publisher.HandleSnapshot)
+	}
+	// Closing the notifiers also disconnects the MQTT publisher
+	m.closers = append(m.closers, notifier.Close)
 
-	return NewMonitorWithDeps(cfg, logger, fetcher, notifier)
+	return m, nil
 }
 
 // Start begins the monitoring process
@@ -124,6 +219,11 @@ func (m *Monitor) Stop() {
 
 	// Wait for goroutines to finish
 	m.wg.Wait()
+	m.stopHeadsUps()
+
+	for _, closeOutput := range m.closers {
+		closeOutput()
+	}
 
 	m.logger.Info("Aircraft monitoring stopped")
 }
@@ -177,7 +277,12 @@ func (m *Monitor) fetchAndProcess() error {
 		zap.Int("filtered_aircraft", len(acList.Aircraft)))
 
 	// Process each aircraft
+	seen := make(map[string]bool, len(acList.Aircraft))
 	for _, ac := range acList.Aircraft {
+		if !m.categoryFilter.Matches(ac) || !m.inView(ac) {
+			continue
+		}
+		seen[m.getAircraftIdentifier(ac)] = true
 		if err := m.processAircraft(ac); err != nil {
 			m.logger.Error("Failed to process aircraft",
 				zap.String("callsign", ac.Call),
@@ -185,44 +290,91 @@ func (m *Monitor) fetchAndProcess() error {
 		}
 	}
 
+	if f, ok := m.notifier.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			m.logger.Error("Failed to send notifications", zap.Error(err))
+		}
+	}
+
+	m.markLostAircraft(seen)
+	m.publishSnapshot()
+
 	return nil
 }
 
 // processAircraft processes a single aircraft
 func (m *Monitor) processAircraft(ac aircraft.Aircraft) error {
-	distance := m.calculateDistance(ac.Lat, ac.Long)
-	bearing := m.calculateBearing(ac.Lat, ac.Long)
-	direction := geo.BearingToDirection(bearing)
-
 	// Get aircraft identifier (prefer ICAO, fallback to callsign)
 	aircraftID := m.getAircraftIdentifier(ac)
 
-	// Get previous distance before updating tracker
-	var previousDistance float64
-	m.historyMutex.RLock()
-	if tracker, exists := m.aircraftHistory[aircraftID]; exists {
-		previousDistance = tracker.LastDistance
+	smoothed, reported := m.smooth(aircraftID, ac, time.Now())
+	d := detection.New(smoothed, m.observer())
+	d.Reported = reported
+
+	// Update the track and publish whatever changed
+	trackEvents, previousDistance := m.updateTrack(aircraftID, d)
+	for _, e := range trackEvents {
+		m.bus.Publish(e)
+	}
+	d.PreviousDistance = previousDistance
+	d.Track = m.trackHistory(aircraftID)
+	m.predictApproach(d)
+	m.markHidden(d)
+
+	// Check if we should notify based on the derived events, or on the progress of the pass
+	var shouldNotify bool
+	switch {
+	case m.config.Notification.Mode == config.NotifyModeClosestApproach:
+		d.Alert, d.Passage = m.passAlert(aircraftID, d)
+		shouldNotify = d.Alert != ""
+	case len(m.bands) > 0:
+		d.Band = m.bandAlert(aircraftID, d.Distance)
+		shouldNotify = d.Band != ""
+	default:
+		shouldNotify = m.shouldNotifyAircraft(aircraftID, trackEvents)
 	}
-	m.historyMutex.RUnlock()
 
-	// Check if we should notify based on distance tracking
-	shouldNotify := m.shouldNotifyAircraft(aircraftID, distance)
+	if m.config.Notification.Transit.Enabled {
+		d.Transits = m.predictTransits(d)
+		if m.transitAlert(aircraftID, d) {
+			d.Alert = detection.AlertTransit
+			shouldNotify = true
+		}
+	}
 
-	m.logger.Info("Aircraft detected",
+	fields := []zap.Field{
 		zap.String("callsign", ac.Call),
 		zap.String("icao", ac.Icao),
 		zap.String("type", ac.Type),
+		zap.Stringer("species", ac.Species),
+		zap.Stringer("wtc", ac.WTC),
 		zap.Int("altitude", ac.Alt),
-		zap.Float64("distance_km", distance),
-		zap.Float64("bearing_degrees", bearing),
-		zap.String("direction", direction),
+		zap.Float64("distance_km", d.Distance),
+		zap.Float64("bearing_degrees", d.Bearing),
+		zap.String("direction", d.Direction),
 		zap.Float64("previous_distance_km", previousDistance),
 		zap.Bool("military", ac.Mil),
-		zap.Bool("notifying", shouldNotify))
+		zap.Bool("notifying", shouldNotify),
+		zap.String("alert", d.Alert),
+		zap.String("band", d.Band),
+		zap.Float64("latitude", d.Aircraft.Lat),
+		zap.Float64("longitude", d.Aircraft.Long),
+	}
+	if d.Reported != nil {
+		fields = append(fields,
+			zap.Float64("raw_latitude", d.Reported.Latitude),
+			zap.Float64("raw_longitude", d.Reported.Longitude),
+			zap.Duration("position_age", d.Reported.Age))
+	}
+	m.logger.Info("Aircraft detected", fields...)
+
+	if m.config.Notification.Enabled && m.config.Notification.HeadsUp.Enabled {
+		m.scheduleHeadsUp(aircraftID, d)
+	}
 
 	// Send notification if enabled and aircraft is getting closer
 	if m.config.Notification.Enabled && shouldNotify {
-		if err := m.notifier.Send(ac.Call, ac.Type, ac.Alt, ac.Spd, distance, direction, ac.Trak, ac.Lat, ac.Long, m.config.Location.Latitude, m.config.Location.Longitude, m.config.Location.Heading, previousDistance); err != nil {
+		if err := m.notifier.Send(d); err != nil {
 			return fmt.Errorf("failed to send notification: %w", err)
 		}
 	}
@@ -230,6 +382,39 @@ func (m *Monitor) processAircraft(ac aircraft.Aircraft) error {
 	return nil
 }
 
+// observer returns the configured observer location and heading
+func (m *Monitor) observer() detection.Observer {
+	return detection.Observer{
+		Latitude:  m.config.Location.Latitude,
+		Longitude: m.config.Location.Longitude,
+		Heading:   m.config.Location.Heading,
+		Elevation: m.config.Location.Elevation,
+	}
+}
+
+// inView reports whether an aircraft clears filters.min_elevation, is within filters.max_slant_range and,
+// with filters.visible_only, is within the observer's view. Aircraft out of view are skipped as if they were
+// missing from the feed.
+func (m *Monitor) inView(ac aircraft.Aircraft) bool {
+	filters := m.config.Filters
+	observer := m.observer()
+	visibleOnly := filters.VisibleOnly && m.view.IsSet()
+	if !observer.IsSet() || (filters.MinElevation == 0 && filters.MaxSlantRange == 0 && !visibleOnly) {
+		return true
+	}
+
+	azimuth, elevation, slantRange := geo.LookAngle(observer.Position(), detection.AircraftPosition(ac))
+	switch {
+	case filters.MinElevation != 0 && elevation < filters.MinElevation:
+		return false
+	case filters.MaxSlantRange != 0 && slantRange > filters.MaxSlantRange:
+		return false
+	case visibleOnly && m.view.Hidden(azimuth, elevation) != "":
+		return false
+	}
+	return true
+}
+
 // getAircraftIdentifier returns a unique identifier for the aircraft
 func (m *Monitor) getAircraftIdentifier(ac aircraft.Aircraft) string {
 	if ac.Icao != "" {
@@ -242,69 +427,327 @@ func (m *Monitor) getAircraftIdentifier(ac aircraft.Aircraft) string {
 	return fmt.Sprintf("%s_%d", ac.Type, ac.Alt)
 }
 
-// shouldNotifyAircraft determines if we should notify about this aircraft
-func (m *Monitor) shouldNotifyAircraft(aircraftID string, currentDistance float64) bool {
+// updateTrack updates the tracker for an aircraft and returns the events derived from the change,
+// along with the distance recorded on the previous poll
+func (m *Monitor) updateTrack(aircraftID string, d *detection.Detection) ([]events.Event, float64) {
 	m.historyMutex.Lock()
 	defer m.historyMutex.Unlock()
 
-	tracker, exists := m.aircraftHistory[aircraftID]
 	now := time.Now()
+	ac, distance, bearing := d.Aircraft, d.Distance, d.Bearing
+	band := events.AltitudeBand(ac.Alt)
+	overhead := distance <= m.overheadDistance()
+
+	newEvent := func(t events.Type) events.Event {
+		return events.Event{
+			Type:         t,
+			Time:         now,
+			AircraftID:   aircraftID,
+			Aircraft:     ac,
+			Distance:     distance,
+			Bearing:      bearing,
+			AltitudeBand: band,
+		}
+	}
 
+	point := detection.TrackPoint{
+		Time:      now,
+		Latitude:  ac.Lat,
+		Longitude: ac.Long,
+		Altitude:  ac.Alt,
+		Track:     ac.Trak,
+		Distance:  distance,
+	}
+	if r := d.Reported; r != nil {
+		// The history keeps what was observed, when it was observed
+		point.Time = now.Add(-r.Age)
+		point.Latitude, point.Longitude, point.Track = r.Latitude, r.Longitude, r.Track
+	}
+
+	tracker, exists := m.aircraftHistory[aircraftID]
 	if !exists {
-		// First time seeing this aircraft - always notify
 		m.aircraftHistory[aircraftID] = &AircraftTracker{
-			LastDistance: currentDistance,
+			Aircraft:     ac,
+			LastDistance: distance,
+			MinDistance:  distance,
 			LastSeen:     now,
-			Notified:     true,
+			Overhead:     overhead,
+			Squawk:       ac.Sqk,
+			AltitudeBand: band,
+			Track:        []detection.TrackPoint{point},
 		}
-		return true
+		trackEvents := []events.Event{newEvent(events.FirstSeen)}
+		if overhead {
+			trackEvents = append(trackEvents, newEvent(events.Overhead))
+		}
+		return trackEvents, 0
 	}
 
-	// Check if aircraft is getting closer (distance is decreasing)
-	isGettingCloser := currentDistance < tracker.LastDistance
+	previousDistance := tracker.LastDistance
+	var trackEvents []events.Event
+	withHistory := func(t events.Type) events.Event {
+		e := newEvent(t)
+		e.PreviousDistance = previousDistance
+		e.MinDistance = tracker.MinDistance
+		return e
+	}
 
-	// Check if we should re-notify based on time interval
-	shouldNotifyByTime := false
-	if m.config.Notification.ReNotifyAfter > 0 {
-		timeSinceLastSeen := now.Sub(tracker.LastSeen)
-		shouldNotifyByTime = timeSinceLastSeen > m.config.Notification.ReNotifyAfter
+	switch {
+	case distance < previousDistance:
+		tracker.Approaching = true
+		trackEvents = append(trackEvents, withHistory(events.Approaching))
+	case distance > previousDistance:
+		if tracker.Approaching {
+			// The previous poll was the closest point of this pass
+			trackEvents = append(trackEvents, withHistory(events.ClosestApproachReached))
+		}
+		tracker.Approaching = false
+		trackEvents = append(trackEvents, withHistory(events.Receding))
+	}
+
+	if overhead && !tracker.Overhead {
+		trackEvents = append(trackEvents, withHistory(events.Overhead))
+	}
+	tracker.Overhead = overhead
+
+	if ac.Sqk != tracker.Squawk {
+		e := withHistory(events.SquawkChanged)
+		e.PreviousSquawk = tracker.Squawk
+		trackEvents = append(trackEvents, e)
+		tracker.Squawk = ac.Sqk
+	}
+
+	if band != tracker.AltitudeBand {
+		e := withHistory(events.AltitudeBandChanged)
+		e.PreviousAltitudeBand = tracker.AltitudeBand
+		trackEvents = append(trackEvents, e)
+		tracker.AltitudeBand = band
 	}
 
 	// Update tracker
-	tracker.LastDistance = currentDistance
+	tracker.Aircraft = ac
+	tracker.LastDistance = distance
+	tracker.MinDistance = math.Min(tracker.MinDistance, distance)
+	tracker.PreviousSeen = tracker.LastSeen
 	tracker.LastSeen = now
+	tracker.Lost = false
+	tracker.Track = append(tracker.Track, point)
+	if len(tracker.Track) > maxTrackPoints {
+		tracker.Track = tracker.Track[len(tracker.Track)-maxTrackPoints:]
+	}
+
+	return trackEvents, previousDistance
+}
 
-	// Determine if we should notify based on configuration
-	if m.config.Notification.NotifyOnCloserOnly {
-		// Only notify if getting closer or if re-notify time has passed
-		if isGettingCloser || shouldNotifyByTime {
-			tracker.Notified = true
-			return true
+// trackHistory returns a copy of the recent positions of an aircraft
+func (m *Monitor) trackHistory(aircraftID string) []detection.TrackPoint {
+	m.historyMutex.RLock()
+	defer m.historyMutex.RUnlock()
+
+	tracker, exists := m.aircraftHistory[aircraftID]
+	if !exists {
+		return nil
+	}
+	return slices.Clone(tracker.Track)
+}
+
+// shouldNotifyAircraft determines if we should notify about this aircraft given the events from its latest update
+func (m *Monitor) shouldNotifyAircraft(aircraftID string, trackEvents []events.Event) bool {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	tracker, exists := m.aircraftHistory[aircraftID]
+	if !exists {
+		return false
+	}
+	now := time.Now()
+
+	// First sighting always notifies; otherwise only approaches do when notify_on_closer_only is set
+	shouldNotify := !m.config.Notification.NotifyOnCloserOnly
+	for _, e := range trackEvents {
+		if e.Type == events.FirstSeen || e.Type == events.Approaching {
+			shouldNotify = true
 		}
-	} else {
-		// Original behavior: always notify
+	}
+
+	// Check if we should re-notify based on time interval
+	if !shouldNotify && m.config.Notification.ReNotifyAfter > 0 {
+		shouldNotify = tracker.LastSeen.Sub(tracker.PreviousSeen) > m.config.Notification.ReNotifyAfter
+	}
+
+	if shouldNotify {
 		tracker.Notified = true
-		return true
+		tracker.LastNotified = now
+	}
+
+	return shouldNotify
+}
+
+// passAlert follows an aircraft through a pass of the observer and returns the alert due on this poll, if any.
+// An approach starts once the aircraft has closed in by the hysteresis from the farthest distance seen, or as it is
+// first seen if it is predicted to approach, and the closest point is reported once it has moved away from it by the
+// hysteresis, so jittery positions raise neither alert twice.
+func (m *Monitor) passAlert(aircraftID string, d *detection.Detection) (string, *detection.Passage) {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	tracker, exists := m.aircraftHistory[aircraftID]
+	if !exists {
+		return "", nil
+	}
+	hysteresis := m.config.Notification.Hysteresis
+
+	if !tracker.Passing {
+		tracker.Farthest = math.Max(tracker.Farthest, d.Distance)
+		// Without a farthest point to close in from, a first sighting goes by the prediction
+		closing := d.ClosestApproach != nil && d.ClosestApproach.WillApproach && len(tracker.Track) == 1
+		if !closing && (d.Distance >= tracker.Farthest || tracker.Farthest-d.Distance < hysteresis) {
+			return "", nil
+		}
+		tracker.Passing = true
+		tracker.Closest = m.passage(d)
+		return detection.AlertApproaching, nil
+	}
+
+	if d.Distance < tracker.Closest.Distance {
+		tracker.Closest = m.passage(d)
+	}
+	if d.Distance <= tracker.Closest.Distance || d.Distance-tracker.Closest.Distance < hysteresis {
+		return "", nil
+	}
+	tracker.Passing = false
+	tracker.Farthest = d.Distance
+	passage := tracker.Closest
+	return detection.AlertClosestApproach, &passage
+}
+
+// bandAlert returns the label of the band an aircraft has just crossed into, or "" if it has not entered a closer band.
+// A band is only left once the aircraft is the hysteresis beyond it, so positions jittering across its edge
+// do not notify again. An aircraft crossing several bands in one poll is notified once, for the innermost.
+func (m *Monitor) bandAlert(aircraftID string, distance float64) string {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	tracker, exists := m.aircraftHistory[aircraftID]
+	if !exists {
+		return ""
+	}
+
+	for tracker.Bands > 0 && distance > m.bands[tracker.Bands-1].Distance+m.config.Notification.Hysteresis {
+		tracker.Bands--
+	}
+	entered := tracker.Bands
+	for entered < len(m.bands) && distance <= m.bands[entered].Distance {
+		entered++
+	}
+	if entered <= tracker.Bands {
+		return ""
 	}
 
-	// Aircraft is moving away or staying at same distance
-	return false
+	tracker.Bands = entered
+	tracker.Notified = true
+	tracker.LastNotified = time.Now()
+	return m.bands[entered-1].Label()
 }
 
-// calculateDistance calculates the distance between user location and aircraft
-func (m *Monitor) calculateDistance(acLat, acLong float64) float64 {
-	if m.config.Location.Latitude == 0.0 && m.config.Location.Longitude == 0.0 {
-		return 0.0
+// passage records where an aircraft is as a candidate for the closest point of its pass
+func (m *Monitor) passage(d *detection.Detection) detection.Passage {
+	return detection.Passage{
+		Time:      d.Time,
+		Distance:  d.Distance,
+		Direction: d.Direction,
+		Altitude:  d.Aircraft.Alt,
+		Overhead:  d.Distance <= m.overheadDistance(),
+	}
+}
+
+// markLostAircraft publishes a Lost event for each tracked aircraft missing from the latest fetch
+func (m *Monitor) markLostAircraft(seen map[string]bool) {
+	m.historyMutex.Lock()
+	now := time.Now()
+	var lost []events.Event
+	for aircraftID, tracker := range m.aircraftHistory {
+		if seen[aircraftID] || tracker.Lost {
+			continue
+		}
+		tracker.Lost = true
+		tracker.Approaching = false
+		tracker.Passing = false
+		tracker.Farthest = 0
+		tracker.Bands = 0
+		tracker.Transits = nil
+		lost = append(lost, events.Event{
+			Type:             events.Lost,
+			Time:             now,
+			AircraftID:       aircraftID,
+			Aircraft:         tracker.Aircraft,
+			Distance:         tracker.LastDistance,
+			PreviousDistance: tracker.LastDistance,
+			MinDistance:      tracker.MinDistance,
+			AltitudeBand:     tracker.AltitudeBand,
+		})
+	}
+	m.historyMutex.Unlock()
+
+	lostIDs := make([]string, len(lost))
+	for i, e := range lost {
+		lostIDs[i] = e.AircraftID
+	}
+	m.cancelHeadsUps(lostIDs)
+
+	for _, e := range lost {
+		m.bus.Publish(e)
+	}
+}
+
+// publishSnapshot publishes the current state of every aircraft that has not been lost
+func (m *Monitor) publishSnapshot() {
+	observer := m.observer()
+
+	m.historyMutex.RLock()
+	detections := make([]*detection.Detection, 0, len(m.aircraftHistory))
+	for _, tracker := range m.aircraftHistory {
+		if tracker.Lost {
+			continue
+		}
+		d := detection.New(tracker.Aircraft, observer)
+		d.Time = tracker.LastSeen
+		d.Track = slices.Clone(tracker.Track)
+		detections = append(detections, d)
+	}
+	m.historyMutex.RUnlock()
+
+	m.bus.PublishSnapshot(events.NewSnapshot(time.Now(), detections))
+}
+
+// logEvent records every published event, keeping the per-poll trend events at debug level
+func (m *Monitor) logEvent(e events.Event) {
+	fields := []zap.Field{
+		zap.Stringer("event", e.Type),
+		zap.String("aircraft_id", e.AircraftID),
+		zap.String("callsign", e.Aircraft.Call),
+		zap.Float64("distance_km", e.Distance),
+	}
+	switch e.Type {
+	case events.Approaching, events.Receding:
+		m.logger.Debug("Aircraft event", fields...)
+	case events.ClosestApproachReached:
+		m.logger.Info("Aircraft event", append(fields, zap.Float64("closest_km", e.PreviousDistance))...)
+	case events.SquawkChanged:
+		m.logger.Info("Aircraft event", append(fields, zap.Int("squawk", int(e.Aircraft.Sqk)), zap.Int("previous_squawk", int(e.PreviousSquawk)))...)
+	case events.AltitudeBandChanged:
+		m.logger.Info("Aircraft event", append(fields, zap.Int("altitude_band", e.AltitudeBand), zap.Int("previous_altitude_band", e.PreviousAltitudeBand))...)
+	default:
+		m.logger.Info("Aircraft event", fields...)
 	}
-	return geo.CalculateDistance(m.config.Location.Latitude, m.config.Location.Longitude, acLat, acLong)
 }
 
-// calculateBearing calculates the bearing between user location and aircraft
-func (m *Monitor) calculateBearing(acLat, acLong float64) float64 {
-	if m.config.Location.Latitude == 0.0 && m.config.Location.Longitude == 0.0 {
-		return 0.0
+// overheadDistance returns the distance in km within which an aircraft counts as overhead
+func (m *Monitor) overheadDistance() float64 {
+	if m.config.Notification.OverheadDistance > 0 {
+		return m.config.Notification.OverheadDistance
 	}
-	return geo.CalculateBearing(m.config.Location.Latitude, m.config.Location.Longitude, acLat, acLong)
+	return defaultOverheadDistance
 }
 
 // cleanupAircraftHistory removes aircraft that haven't been seen recently
@@ -320,6 +763,7 @@ func (m *Monitor) cleanupAircraftHistory() {
 	for aircraftID, tracker := range m.aircraftHistory {
 		if tracker.LastSeen.Before(cutoffTime) {
 			delete(m.aircraftHistory, aircraftID)
+			delete(m.filters, aircraftID)
 		}
 	}
 
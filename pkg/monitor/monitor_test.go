@@ -1,10 +1,15 @@
 package monitor
 
 import (
+	"math"
+	"strings"
 	"time"
 
 	"github.com/lyarwood/godar/pkg/aircraft"
 	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/events"
+	"github.com/lyarwood/godar/pkg/geo"
 	"github.com/lyarwood/godar/pkg/notification"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -68,6 +73,344 @@ var _ = Describe("Monitor", func() {
 		Expect(calls[0].Title).To(ContainSubstring("Aircraft Detected: TEST1"))
 	})
 
+	It("should skip aircraft outside the category filters", func() {
+		cfg.Filters.Species = []string{"Helicopter"}
+		heli := aircraft.Aircraft{Call: "HELI1", Lat: 51.6, Long: 0.1, Alt: 1000, Species: aircraft.SpeciesHelicopter}
+		plane := aircraft.Aircraft{Call: "PLANE1", Lat: 51.6, Long: 0.1, Alt: 10000, Species: aircraft.SpeciesLandPlane}
+		fetcher := &mockFetcher{acList: &aircraft.AircraftList{Aircraft: []aircraft.Aircraft{heli, plane}}}
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, err := NewMonitorWithDeps(cfg, logger, fetcher, notifier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mon.fetchAndProcess()).To(Succeed())
+		calls := n.GetNotifications()
+		Expect(calls).To(HaveLen(1))
+		Expect(calls[0].Title).To(ContainSubstring("HELI1"))
+	})
+
+	It("should skip aircraft below the minimum elevation", func() {
+		cfg.Filters.MinElevation = 10
+		low := aircraft.Aircraft{Call: "LOW1", Lat: 51.6, Long: 0.1, Alt: 3000}
+		high := aircraft.Aircraft{Call: "HIGH1", Lat: 51.6, Long: 0.1, Alt: 30000}
+		fetcher := &mockFetcher{acList: &aircraft.AircraftList{Aircraft: []aircraft.Aircraft{low, high}}}
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, err := NewMonitorWithDeps(cfg, logger, fetcher, notifier)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(mon.fetchAndProcess()).To(Succeed())
+		calls := n.GetNotifications()
+		Expect(calls).To(HaveLen(1))
+		Expect(calls[0].Title).To(ContainSubstring("HIGH1"))
+		Expect(calls[0].Message).To(MatchRegexp(`Look: NNE, 3\d° up`))
+	})
+
+	Describe("field of view", func() {
+		// 20 km south heading north, the view is ±45° around north
+		behind := aircraft.Aircraft{Call: "BEHIND1", Lat: 51.32, Long: 0.001, Alt: 10000, Trak: 0.1, Spd: 360}
+
+		BeforeEach(func() {
+			cfg.Location.FieldOfView = 90
+			cfg.Notification.PredictionWindow = 30 * time.Minute
+		})
+
+		It("should mark aircraft outside the view with when they come into it", func() {
+			n := notification.NewMockNotificationSender()
+			notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+			mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notifier)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mon.processAircraft(behind)).To(Succeed())
+			calls := n.GetNotifications()
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Message).To(ContainSubstring("Hidden: outside the field of view, in view in 1 min"))
+		})
+
+		It("should skip aircraft outside the view when only visible aircraft are wanted", func() {
+			cfg.Filters.VisibleOnly = true
+			ahead := behind
+			ahead.Call, ahead.Lat = "AHEAD1", 51.68
+			fetcher := &mockFetcher{acList: &aircraft.AircraftList{Aircraft: []aircraft.Aircraft{behind, ahead}}}
+			n := notification.NewMockNotificationSender()
+			notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+			mon, err := NewMonitorWithDeps(cfg, logger, fetcher, notifier)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(mon.fetchAndProcess()).To(Succeed())
+			calls := n.GetNotifications()
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Title).To(ContainSubstring("AHEAD1"))
+			Expect(calls[0].Message).NotTo(ContainSubstring("Hidden"))
+		})
+
+		It("should fail to start with an unreadable horizon survey", func() {
+			cfg.Location.HorizonFile = "/nonexistent/horizon.csv"
+			_, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notification.NewMultiNotifier())
+			Expect(err).To(MatchError(ContainSubstring("failed to load horizon")))
+		})
+	})
+
+	It("should predict the closest approach around a holding pattern turn", func() {
+		// A rate one right turn at 210 knots, due to pass overhead 30 seconds from now
+		radius := 210 * 1.852 / 3600 / (3 * math.Pi / 180)
+		fixLat, fixLon := geo.Destination(cfg.Location.Latitude, cfg.Location.Longitude, 190, radius)
+		now := time.Now()
+		var track []detection.TrackPoint
+		var ac aircraft.Aircraft
+		for t := -40; t <= 0; t += 10 {
+			heading := 10 + 3*float64(t)
+			lat, lon := geo.Destination(fixLat, fixLon, heading-90, radius)
+			track = append(track, detection.TrackPoint{Time: now.Add(time.Duration(t) * time.Second),
+				Latitude: lat, Longitude: lon, Altitude: 7000, Track: math.Mod(heading+360, 360)})
+			ac = aircraft.Aircraft{Call: "HOLD1", Lat: lat, Long: lon, Alt: 7000, Trak: heading, Spd: 210}
+		}
+
+		mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notification.NewMultiNotifier())
+		Expect(err).ToNot(HaveOccurred())
+		d := detection.New(ac, mon.observer())
+		Expect(d.ClosestApproach.Distance).To(BeNumerically(">", 1.5))
+
+		d.Track = track
+		mon.predictApproach(d)
+		Expect(d.ClosestApproach.Distance).To(BeNumerically("<", 0.05))
+		Expect(d.ClosestApproach.TimeToClosest).To(BeNumerically("~", 30*time.Second, time.Second))
+		Expect(d.ClosestApproach.TurnRate).To(BeNumerically("~", 3, 0.01))
+		Expect(d.ClosestApproach.Altitude).To(Equal(7000))
+	})
+
+	Describe("smoothing", func() {
+		BeforeEach(func() {
+			cfg.Monitoring.Smoothing = true
+		})
+
+		It("should keep the distance of a receding MLAT helicopter increasing through jitter", func() {
+			mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notification.NewMultiNotifier())
+			Expect(err).ToNot(HaveOccurred())
+
+			// 60 knots north, positions received 3 s before each poll and scattered along the track
+			jitter := []float64{0.3, -0.2, 0.25, -0.3, 0.2, -0.25}
+			start := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+			rawCloser, previous, smoothed := 0, 0.0, 0.0
+			for i := range 12 {
+				lat, lon := geo.Destination(51.55, 0, 0, float64(i)*60*1.852/360+jitter[i%len(jitter)])
+				fixed := start.Add(time.Duration(i) * 10 * time.Second)
+				ac := aircraft.Aircraft{Icao: "43C001", Lat: lat, Long: lon, Spd: 60, Trak: 0.1, Mlat: true, PosTime: fixed.UnixMilli()}
+
+				got, reported := mon.smooth("43C001", ac, fixed.Add(3*time.Second))
+				Expect(reported.Latitude).To(Equal(lat))
+				Expect(reported.Age).To(Equal(3 * time.Second))
+
+				distance := geo.CalculateDistance(51.5, 0, got.Lat, got.Long)
+				Expect(distance).To(BeNumerically(">", smoothed), "poll %d", i)
+				smoothed = distance
+				raw := geo.CalculateDistance(51.5, 0, lat, lon)
+				if raw < previous {
+					rawCloser++
+				}
+				previous = raw
+			}
+			Expect(rawCloser).To(BeNumerically(">", 3))
+		})
+
+		It("should keep the reported position in the track history", func() {
+			mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notification.NewMultiNotifier())
+			Expect(err).ToNot(HaveOccurred())
+
+			// Reported 30 s ago flying east at 400 knots, so now 6 km further on
+			ac := aircraft.Aircraft{Icao: "400A0B", Lat: 51.6, Long: 0.1, Alt: 10000, Spd: 400, Trak: 90,
+				PosTime: time.Now().Add(-30 * time.Second).UnixMilli()}
+			Expect(mon.processAircraft(ac)).To(Succeed())
+
+			track := mon.trackHistory("400A0B")
+			Expect(track).To(HaveLen(1))
+			Expect(track[0].Latitude).To(Equal(51.6))
+			Expect(track[0].Time).To(BeTemporally("~", time.Now().Add(-30*time.Second), time.Second))
+			lat, lon := geo.Destination(51.6, 0.1, 90, 400*1.852/120)
+			Expect(mon.aircraftHistory["400A0B"].LastDistance).To(BeNumerically("~", geo.CalculateDistance(51.5, 0, lat, lon), 0.05))
+		})
+	})
+
+	It("should flush the notifier once a poll is processed", func() {
+		first := aircraft.Aircraft{Call: "FIRST1", Lat: 51.6, Long: 0.1, Alt: 10000}
+		second := aircraft.Aircraft{Call: "SECOND1", Lat: 51.7, Long: 0.1, Alt: 10000}
+		fetcher := &mockFetcher{acList: &aircraft.AircraftList{Aircraft: []aircraft.Aircraft{first, second}}}
+		n := notification.NewMockNotificationSender()
+		router, err := notification.NewRouter(config.NotificationConfig{Burst: config.BurstConfig{Threshold: 2}}, logger)
+		Expect(err).ToNot(HaveOccurred())
+		router.Add(notification.BackendDesktop, notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute))
+		mon, err := NewMonitorWithDeps(cfg, logger, fetcher, router)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(mon.fetchAndProcess()).To(Succeed())
+		calls := n.GetNotifications()
+		Expect(calls).To(HaveLen(1))
+		Expect(calls[0].Title).To(HavePrefix("2 aircraft in range, nearest FIRST1"))
+	})
+
+	It("should notify once approaching and once at the closest approach", func() {
+		cfg.Notification.Mode = config.NotifyModeClosestApproach
+		cfg.Notification.Hysteresis = 0.5
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notifier)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Heading south over the observer, with a jittery position close in
+		for _, lat := range []float64{51.6, 51.58, 51.56, 51.54, 51.52, 51.502, 51.503, 51.501, 51.4996, 51.49, 51.47, 51.45} {
+			Expect(mon.processAircraft(aircraft.Aircraft{Call: "PASS1", Lat: lat, Long: 0.0, Alt: 3000})).To(Succeed())
+		}
+
+		calls := n.GetNotifications()
+		Expect(calls).To(HaveLen(2))
+		Expect(calls[0].Title).To(Equal("Aircraft Approaching: PASS1"))
+		Expect(calls[1].Title).To(Equal("Closest Approach: PASS1"))
+		Expect(calls[1].Message).To(HavePrefix("Closest approach: 0.0 km overhead at "))
+	})
+
+	It("should notify an aircraft already closing in when first seen", func() {
+		cfg.Notification.Mode = config.NotifyModeClosestApproach
+		cfg.Notification.Hysteresis = 0.5
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notifier)
+		Expect(err).ToNot(HaveOccurred())
+
+		// First seen heading south 300 m short of the observer, so it never closes in by the hysteresis
+		for _, lat := range []float64{51.5027, 51.4973, 51.489, 51.48} {
+			Expect(mon.processAircraft(aircraft.Aircraft{Call: "FAST1", Lat: lat, Long: 0.001, Alt: 3000, Trak: 180, Spd: 350})).To(Succeed())
+		}
+
+		calls := n.GetNotifications()
+		Expect(calls).To(HaveLen(2))
+		Expect(calls[0].Title).To(Equal("Aircraft Approaching: FAST1"))
+		Expect(calls[1].Title).To(Equal("Closest Approach: FAST1"))
+	})
+
+	It("should notify as aircraft cross into closer distance bands", func() {
+		cfg.Notification.Hysteresis = 0.5
+		cfg.Notification.Bands = []config.BandConfig{{Distance: 5}, {Distance: 50}, {Distance: 1, Name: "overhead"}, {Distance: 20}}
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notifier)
+		Expect(err).ToNot(HaveOccurred())
+
+		// Jitters across the 20 km edge, then leaves the inner bands and comes back
+		for _, km := range []float64{60, 45, 30, 19, 20.3, 19.5, 3, 0.5, 4, 6, 4} {
+			Expect(mon.processAircraft(aircraft.Aircraft{Call: "BAND1", Lat: 51.5 + km/111.19, Long: 0.0})).To(Succeed())
+		}
+
+		var entered []string
+		for _, call := range n.GetNotifications() {
+			band, _, _ := strings.Cut(call.Message, "\n")
+			entered = append(entered, band)
+		}
+		Expect(entered).To(Equal([]string{
+			"Entered: 50 km", "Entered: 20 km", "Entered: 5 km", "Entered: overhead", "Entered: 5 km",
+		}))
+	})
+
+	Describe("heads-up", func() {
+		var (
+			mon *Monitor
+			n   *notification.MockNotificationSender
+		)
+
+		BeforeEach(func() {
+			cfg.Notification.HeadsUp = config.HeadsUpConfig{Enabled: true, LeadTime: 2 * time.Minute}
+			n = notification.NewMockNotificationSender()
+			notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+			var err error
+			mon, err = NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notifier)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(mon.stopHeadsUps)
+		})
+
+		headsUps := func() []string {
+			var titles []string
+			for _, call := range n.GetNotifications() {
+				if strings.HasPrefix(call.Title, "Heads Up: ") {
+					titles = append(titles, call.Title)
+				}
+			}
+			return titles
+		}
+
+		It("should alert once when the closest approach is within the lead time", func() {
+			// 10 km north heading south at 300 knots, about a minute out
+			ac := aircraft.Aircraft{Call: "HEAD1", Lat: 51.59, Long: 0.001, Alt: 3000, Trak: 180, Spd: 300}
+			Expect(mon.processAircraft(ac)).To(Succeed())
+			Eventually(headsUps).Should(ConsistOf(HavePrefix("Heads Up: HEAD1 overhead in 1 min, look ")))
+
+			ac.Lat = 51.58
+			Expect(mon.processAircraft(ac)).To(Succeed())
+			Consistently(headsUps, 100*time.Millisecond).Should(HaveLen(1))
+		})
+
+		It("should cancel the alert when the aircraft turns away", func() {
+			// 40 km north heading south, about four minutes out
+			ac := aircraft.Aircraft{Call: "HEAD2", Lat: 51.86, Long: 0.001, Alt: 3000, Trak: 180, Spd: 300}
+			Expect(mon.processAircraft(ac)).To(Succeed())
+			Expect(mon.headsUps).To(HaveKey("HEAD2"))
+
+			ac.Trak = 10
+			Expect(mon.processAircraft(ac)).To(Succeed())
+			Expect(mon.headsUps).To(BeEmpty())
+			Expect(headsUps()).To(BeEmpty())
+		})
+	})
+
+	It("should alert once with a countdown when an aircraft will cross the sun", func() {
+		// Stand where it is about noon so the sun is well above the horizon whenever the test runs
+		now := time.Now().UTC()
+		hours := float64(now.Hour()) + float64(now.Minute())/60
+		cfg.Location = config.LocationConfig{Latitude: 1, Longitude: (12 - hours) * 15, MaxDistance: 100}
+		cfg.Notification.Transit = config.TransitConfig{Enabled: true, Bodies: []string{"sun"}, Window: 5 * time.Minute}
+
+		// Put the aircraft a minute away from the line of sight to the sun at 10,000 m
+		sky := geo.Sun.At(now.Add(time.Minute), cfg.Location.Latitude, cfg.Location.Longitude)
+		ground := 10 / math.Tan(sky.Elevation*math.Pi/180)
+		lat, lon := geo.Destination(cfg.Location.Latitude, cfg.Location.Longitude, sky.Azimuth, ground)
+		startLat, startLon := geo.Destination(lat, lon, 270, 400*1.852/60)
+		ac := aircraft.Aircraft{Call: "TRAN1", Lat: startLat, Long: startLon, Alt: 32808, Spd: 400,
+			Trak: geo.CalculateBearing(startLat, startLon, lat, lon)}
+
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notifier)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(mon.processAircraft(ac)).To(Succeed())
+		Expect(mon.processAircraft(ac)).To(Succeed())
+
+		var transits []notification.NotificationCall
+		for _, call := range n.GetNotifications() {
+			if strings.HasPrefix(call.Title, "Transit: ") {
+				transits = append(transits, call)
+			}
+		}
+		Expect(transits).To(HaveLen(1))
+		Expect(transits[0].Title).To(HavePrefix("Transit: TRAN1 crosses the sun in "))
+		Expect(transits[0].Message).To(ContainSubstring("Sun transit: in "))
+	})
+
+	It("should alert once for a transit whose predicted time drifts", func() {
+		cfg.Notification.Transit = config.TransitConfig{Enabled: true, Window: 5 * time.Minute}
+		mon, err := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notification.NewMultiNotifier())
+		Expect(err).ToNot(HaveOccurred())
+		mon.aircraftHistory["TRAN2"] = &AircraftTracker{}
+
+		start := time.Now()
+		poll := func(after, transit time.Duration) bool {
+			d := &detection.Detection{Time: start.Add(after), Transits: []geo.Transit{{Body: "moon", Time: start.Add(transit)}}}
+			return mon.transitAlert("TRAN2", d)
+		}
+		Expect(poll(0, 4*time.Minute)).To(BeTrue())
+		// Slowing down pushes the transit back, past the window after the first prediction
+		Expect(poll(3*time.Minute, 9*time.Minute)).To(BeFalse())
+		Expect(poll(10*time.Minute, 12*time.Minute)).To(BeFalse())
+		// Coming round again long after the last prediction is another transit
+		Expect(poll(30*time.Minute, 32*time.Minute)).To(BeTrue())
+	})
+
 	It("should handle notifier error gracefully", func() {
 		ac := aircraft.Aircraft{Call: "ERR1", Lat: 51.6, Long: 0.1, Alt: 10000, Type: "A320", Spd: 400}
 		fetcher := &mockFetcher{}
@@ -80,6 +423,121 @@ var _ = Describe("Monitor", func() {
 		Expect(err.Error()).To(ContainSubstring("mock error: test error"))
 	})
 
+	Describe("events", func() {
+		var (
+			mon      *Monitor
+			fetcher  *mockFetcher
+			received []events.Type
+		)
+
+		BeforeEach(func() {
+			fetcher = &mockFetcher{acList: &aircraft.AircraftList{}}
+			notifier := notification.NewNotifierWithSender(false, time.Second, logger, notification.NewMockNotificationSender(), 15.0, 30*time.Minute)
+			mon, _ = NewMonitorWithDeps(cfg, logger, fetcher, notifier)
+			received = nil
+			mon.Events().Subscribe(func(e events.Event) { received = append(received, e.Type) })
+		})
+
+		poll := func(acs ...aircraft.Aircraft) {
+			received = nil
+			fetcher.acList = &aircraft.AircraftList{Aircraft: acs}
+			Expect(mon.fetchAndProcess()).To(Succeed())
+		}
+
+		It("should derive a pass from the distance trend", func() {
+			ac := aircraft.Aircraft{Icao: "ABC123", Call: "TEST1", Lat: 51.8, Long: 0.0, Alt: 10000}
+			poll(ac)
+			Expect(received).To(Equal([]events.Type{events.FirstSeen}))
+
+			ac.Lat = 51.51
+			poll(ac)
+			Expect(received).To(Equal([]events.Type{events.Approaching, events.Overhead}))
+
+			ac.Lat = 51.7
+			poll(ac)
+			Expect(received).To(Equal([]events.Type{events.ClosestApproachReached, events.Receding}))
+
+			ac.Lat = 51.9
+			poll(ac)
+			Expect(received).To(Equal([]events.Type{events.Receding}))
+
+			poll()
+			Expect(received).To(Equal([]events.Type{events.Lost}))
+
+			poll()
+			Expect(received).To(BeEmpty())
+		})
+
+		It("should publish squawk and altitude band changes", func() {
+			ac := aircraft.Aircraft{Icao: "ABC123", Lat: 51.8, Long: 0.0, Alt: 10000, Sqk: 2045}
+			poll(ac)
+
+			ac.Sqk = 7700
+			ac.Alt = 4000
+			poll(ac)
+			Expect(received).To(Equal([]events.Type{events.SquawkChanged, events.AltitudeBandChanged}))
+		})
+
+		It("should publish a snapshot of the aircraft in range after every poll", func() {
+			var snapshots []events.Snapshot
+			mon.Events().SubscribeSnapshots(func(s events.Snapshot) { snapshots = append(snapshots, s) })
+
+			far := aircraft.Aircraft{Icao: "FAR001", Lat: 51.9, Long: 0.0, Alt: 30000}
+			near := aircraft.Aircraft{Icao: "NEAR01", Lat: 51.6, Long: 0.0, Alt: 8000}
+			poll(far, near)
+			Expect(snapshots).To(HaveLen(1))
+			Expect(snapshots[0].Aircraft).To(HaveLen(2))
+			Expect(snapshots[0].Nearest().Aircraft.Icao).To(Equal("NEAR01"))
+
+			poll(far)
+			Expect(snapshots).To(HaveLen(2))
+			Expect(snapshots[1].Aircraft).To(HaveLen(1))
+			Expect(snapshots[1].Nearest().Aircraft.Icao).To(Equal("FAR001"))
+
+			poll()
+			Expect(snapshots[2].Nearest()).To(BeNil())
+		})
+	})
+
+	It("should only notify approaching aircraft when notify_on_closer_only is set", func() {
+		cfg.Notification.NotifyOnCloserOnly = true
+		ac := aircraft.Aircraft{Icao: "ABC123", Call: "TEST1", Lat: 51.8, Long: 0.0, Alt: 10000}
+		fetcher := &mockFetcher{}
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, _ := NewMonitorWithDeps(cfg, logger, fetcher, notifier)
+
+		Expect(mon.processAircraft(ac)).To(Succeed())
+		ac.Lat = 51.7
+		Expect(mon.processAircraft(ac)).To(Succeed())
+		ac.Lat = 51.9
+		Expect(mon.processAircraft(ac)).To(Succeed())
+
+		Expect(n.GetNotificationCount()).To(Equal(2))
+	})
+
+	It("should re-notify aircraft seen again after re_notify_after", func() {
+		cfg.Notification.NotifyOnCloserOnly = true
+		cfg.Notification.ReNotifyAfter = 5 * time.Minute
+		ac := aircraft.Aircraft{Icao: "ABC123", Call: "TEST1", Lat: 51.8, Long: 0.0, Alt: 10000}
+		n := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, time.Second, logger, n, 15.0, 30*time.Minute)
+		mon, _ := NewMonitorWithDeps(cfg, logger, &mockFetcher{}, notifier)
+
+		Expect(mon.processAircraft(ac)).To(Succeed())
+		Expect(n.GetNotificationCount()).To(Equal(1))
+
+		// Seen on every poll, however long ago the last notification was
+		mon.aircraftHistory["ABC123"].LastNotified = time.Now().Add(-time.Hour)
+		Expect(mon.processAircraft(ac)).To(Succeed())
+		Expect(n.GetNotificationCount()).To(Equal(1))
+
+		// Back after a gap longer than re_notify_after
+		mon.aircraftHistory["ABC123"].LastSeen = time.Now().Add(-10 * time.Minute)
+		Expect(mon.processAircraft(ac)).To(Succeed())
+		Expect(n.GetNotificationCount()).To(Equal(2))
+	})
+
 	It("should clean up aircraft history", func() {
 		fetcher := &mockFetcher{}
 		notifier := notification.NewNotifier(cfg.Notification.Enabled, cfg.Notification.Duration, logger, 15.0, 30*time.Minute)
@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/geo"
+)
+
+// maxSmoothedSpeedError is how uncertain a filtered velocity can be in knots before the reported one is kept
+const maxSmoothedSpeedError = 20.0
+
+// smooth feeds the aircraft's reported position to its Kalman filter and returns the aircraft with its filtered
+// position and velocity dead reckoned to now, along with the position as reported.
+// The aircraft is returned unchanged, and the reported position nil, when smoothing is disabled.
+func (m *Monitor) smooth(aircraftID string, ac aircraft.Aircraft, now time.Time) (aircraft.Aircraft, *detection.Fix) {
+	if !m.config.Monitoring.Smoothing || (ac.Lat == 0 && ac.Long == 0) {
+		return ac, nil
+	}
+
+	// PosTime is when the position was received, in milliseconds since the epoch
+	fix := geo.Fix{Time: now, Latitude: ac.Lat, Longitude: ac.Long, Speed: ac.Spd, Track: ac.Trak, MLAT: ac.Mlat}
+	if at := time.UnixMilli(ac.PosTime); ac.PosTime > 0 && at.Before(now) {
+		fix.Time = at
+	}
+
+	m.historyMutex.Lock()
+	var turnRate float64
+	if tracker, exists := m.aircraftHistory[aircraftID]; exists {
+		turnRate = geo.EstimateManoeuvre(trackSamples(tracker.Track)).TurnRate
+	}
+	filter, exists := m.filters[aircraftID]
+	if exists {
+		filter.Update(fix, turnRate)
+	} else {
+		filter = geo.NewKalmanFilter(fix)
+		m.filters[aircraftID] = filter
+	}
+	estimate := filter.At(now, turnRate)
+	m.historyMutex.Unlock()
+
+	smoothed := ac
+	smoothed.Lat, smoothed.Long = estimate.Latitude, estimate.Longitude
+	if estimate.SpeedError <= maxSmoothedSpeedError {
+		smoothed.Spd, smoothed.Trak = estimate.Speed, estimate.Track
+	}
+	return smoothed, &detection.Fix{Latitude: ac.Lat, Longitude: ac.Long, Track: ac.Trak, Age: now.Sub(fix.Time)}
+}
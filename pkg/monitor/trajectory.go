@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/geo"
+)
+
+// defaultPredictionWindow is used when notification.prediction_window is not set
+const defaultPredictionWindow = 30 * time.Minute
+
+// predictApproach replaces the straight line closest approach with one along the turning, climbing path
+// estimated from the aircraft's recent track
+func (m *Monitor) predictApproach(d *detection.Detection) {
+	if d.ClosestApproach == nil || !d.Observer.IsSet() {
+		return
+	}
+	motion, turnRateError := m.motion(d)
+	d.ClosestApproach = geo.PredictClosestApproach(d.Observer.Position(), detection.AircraftPosition(d.Aircraft),
+		motion, turnRateError, m.predictionWindow())
+}
+
+// motion returns how the aircraft is moving and the standard error of its turn rate.
+// The reported vertical rate and autopilot targets are preferred over those estimated from the track.
+func (m *Monitor) motion(d *detection.Detection) (geo.Motion, float64) {
+	manoeuvre := geo.EstimateManoeuvre(trackSamples(d.Track))
+
+	ac := d.Aircraft
+	motion := geo.Motion{
+		Track:          ac.Trak,
+		Speed:          ac.Spd,
+		VerticalRate:   manoeuvre.VerticalRate,
+		TurnRate:       manoeuvre.TurnRate,
+		TargetTrack:    ac.TTrk,
+		TargetAltitude: float64(ac.TAlt),
+	}
+	if ac.Vsi != 0 {
+		motion.VerticalRate = float64(ac.Vsi)
+	}
+	return motion, manoeuvre.TurnRateError
+}
+
+// predictionWindow returns how far ahead aircraft are projected along their paths
+func (m *Monitor) predictionWindow() time.Duration {
+	if m.config.Notification.PredictionWindow > 0 {
+		return m.config.Notification.PredictionWindow
+	}
+	return defaultPredictionWindow
+}
+
+// trackSamples converts an aircraft's recent positions for estimating how it is manoeuvring
+func trackSamples(track []detection.TrackPoint) []geo.TrackSample {
+	samples := make([]geo.TrackSample, 0, len(track))
+	for _, p := range track {
+		samples = append(samples, geo.TrackSample{
+			Time:     p.Time,
+			Position: geo.Position{Latitude: p.Latitude, Longitude: p.Longitude, Altitude: geo.FeetToMetres(float64(p.Altitude))},
+			Track:    p.Track,
+		})
+	}
+	return samples
+}
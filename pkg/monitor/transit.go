@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"slices"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/geo"
+	"go.uber.org/zap"
+)
+
+// defaultTransitWindow is used when notification.transit.window is not set
+const defaultTransitWindow = 5 * time.Minute
+
+// predictTransits returns the aircraft's predicted passes in front of the configured bodies, soonest first
+func (m *Monitor) predictTransits(d *detection.Detection) []geo.Transit {
+	ac := d.Aircraft
+	if !d.Observer.IsSet() || ac.Gnd || ac.Spd <= 1.0 || (ac.Lat == 0 && ac.Long == 0) {
+		return nil
+	}
+
+	position := detection.AircraftPosition(ac)
+	motion, _ := m.motion(d)
+
+	var transits []geo.Transit
+	for _, body := range m.transitBodies() {
+		t := geo.PredictTransit(body, d.Observer.Position(), position, motion, d.Time,
+			m.transitWindow(), m.config.Notification.Transit.MaxSeparation)
+		if t != nil {
+			transits = append(transits, *t)
+		}
+	}
+	slices.SortFunc(transits, func(a, b geo.Transit) int {
+		return a.Time.Compare(b.Time)
+	})
+	return transits
+}
+
+// transitAlert reports whether any of the predicted transits has not been alerted yet.
+// The latest prediction for each body is kept, not the one alerted, so a transit whose predicted time drifts from
+// poll to poll alerts once. Another transit of the same body alerts once the window after the last prediction has passed.
+func (m *Monitor) transitAlert(aircraftID string, d *detection.Detection) bool {
+	m.historyMutex.Lock()
+	defer m.historyMutex.Unlock()
+
+	tracker, exists := m.aircraftHistory[aircraftID]
+	if !exists {
+		return false
+	}
+	if tracker.Transits == nil {
+		tracker.Transits = make(map[string]time.Time)
+	}
+
+	alert := false
+	for _, t := range d.Transits {
+		previous, ok := tracker.Transits[t.Body]
+		tracker.Transits[t.Body] = t.Time
+		if ok && d.Time.Before(previous.Add(m.transitWindow())) {
+			continue
+		}
+		alert = true
+		m.logger.Info("Transit predicted",
+			zap.String("callsign", d.Callsign()),
+			zap.String("body", t.Body),
+			zap.Time("at", t.Time),
+			zap.Float64("separation_arcmin", t.Separation),
+			zap.Float64("offset_km", t.Offset))
+	}
+	return alert
+}
+
+// transitBodies returns the bodies to predict transits of, both when notification.transit.bodies is not set
+func (m *Monitor) transitBodies() []geo.Body {
+	names := m.config.Notification.Transit.Bodies
+	if len(names) == 0 {
+		return []geo.Body{geo.Sun, geo.Moon}
+	}
+	var bodies []geo.Body
+	for _, name := range names {
+		if body, ok := geo.ParseBody(name); ok {
+			bodies = append(bodies, body)
+		}
+	}
+	return bodies
+}
+
+// transitWindow returns how far ahead aircraft are projected looking for transits
+func (m *Monitor) transitWindow() time.Duration {
+	if m.config.Notification.Transit.Window > 0 {
+		return m.config.Notification.Transit.Window
+	}
+	return defaultTransitWindow
+}
@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/geo"
+)
+
+// newView builds the part of the sky the observer can see from the horizon points, the horizon survey file
+// and the field of view
+func newView(location config.LocationConfig) (geo.View, error) {
+	points := make([]geo.HorizonPoint, 0, len(location.Horizon))
+	for _, p := range location.Horizon {
+		points = append(points, geo.HorizonPoint{Azimuth: p.Azimuth, Elevation: p.Elevation})
+	}
+	if location.HorizonFile != "" {
+		surveyed, err := geo.LoadHorizon(location.HorizonFile)
+		if err != nil {
+			return geo.View{}, fmt.Errorf("failed to load horizon: %w", err)
+		}
+		points = append(points, surveyed...)
+	}
+
+	return geo.View{
+		Horizon:     geo.NewHorizon(points),
+		Heading:     location.Heading,
+		FieldOfView: location.FieldOfView,
+	}, nil
+}
+
+// markHidden records why an aircraft cannot be seen and predicts whether it will come into view
+// within the prediction window
+func (m *Monitor) markHidden(d *detection.Detection) {
+	if !m.view.IsSet() || !d.Observer.IsSet() {
+		return
+	}
+	d.Hidden = m.view.Hidden(d.Bearing, d.Elevation)
+
+	approach := d.ClosestApproach
+	if approach == nil {
+		return
+	}
+	if d.Hidden == "" {
+		approach.WillBeVisible = true
+		return
+	}
+	motion, _ := m.motion(d)
+	approach.TimeToVisible, approach.WillBeVisible = m.view.TimeToVisible(
+		d.Observer.Position(), detection.AircraftPosition(d.Aircraft), motion, m.predictionWindow())
+}
@@ -0,0 +1,257 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/events"
+	"go.uber.org/zap"
+)
+
+// HomeAssistantState is the retained payload every Home Assistant sensor reads from
+type HomeAssistantState struct {
+	AircraftCount int                    `json:"aircraft_count"`
+	Nearest       *HomeAssistantAircraft `json:"nearest"`    // nil when nothing is in range
+	LastAlert     *HomeAssistantAircraft `json:"last_alert"` // nil until the first notification
+	Time          time.Time              `json:"time"`
+}
+
+// HomeAssistantAircraft summarises an aircraft for Home Assistant sensors and attributes
+type HomeAssistantAircraft struct {
+	Callsign     string    `json:"callsign"`
+	Icao         string    `json:"icao,omitempty"`
+	Registration string    `json:"registration,omitempty"`
+	Type         string    `json:"type,omitempty"`
+	Category     string    `json:"category,omitempty"`
+	Distance     float64   `json:"distance_km"`
+	Altitude     int       `json:"altitude"`
+	Bearing      float64   `json:"bearing"`
+	Direction    string    `json:"direction"`
+	Elevation    float64   `json:"elevation"` // Degrees above the horizon
+	Military     bool      `json:"military"`
+	Time         time.Time `json:"time"`
+}
+
+// homeAssistantSensor describes one entity announced through discovery
+type homeAssistantSensor struct {
+	objectID      string
+	name          string
+	valueTemplate string
+	attributes    string // Key of the state object exposed as entity attributes
+	unit          string
+	deviceClass   string
+	stateClass    string
+	icon          string
+}
+
+var homeAssistantSensors = []homeAssistantSensor{
+	{
+		objectID:      "nearest_callsign",
+		name:          "Nearest aircraft",
+		valueTemplate: "{{ value_json.nearest.callsign if value_json.nearest else None }}",
+		attributes:    "nearest",
+		icon:          "mdi:airplane",
+	},
+	{
+		objectID:      "nearest_distance",
+		name:          "Nearest aircraft distance",
+		valueTemplate: "{{ value_json.nearest.distance_km if value_json.nearest else None }}",
+		unit:          "km",
+		deviceClass:   "distance",
+		stateClass:    "measurement",
+		icon:          "mdi:map-marker-distance",
+	},
+	{
+		objectID:      "nearest_altitude",
+		name:          "Nearest aircraft altitude",
+		valueTemplate: "{{ value_json.nearest.altitude if value_json.nearest else None }}",
+		unit:          "ft",
+		deviceClass:   "distance",
+		stateClass:    "measurement",
+		icon:          "mdi:airplane-takeoff",
+	},
+	{
+		objectID:      "nearest_bearing",
+		name:          "Nearest aircraft bearing",
+		valueTemplate: "{{ value_json.nearest.bearing if value_json.nearest else None }}",
+		unit:          "°",
+		stateClass:    "measurement",
+		icon:          "mdi:compass-outline",
+	},
+	{
+		objectID:      "aircraft_count",
+		name:          "Aircraft in range",
+		valueTemplate: "{{ value_json.aircraft_count }}",
+		stateClass:    "measurement",
+		icon:          "mdi:radar",
+	},
+	{
+		objectID:      "last_alert",
+		name:          "Last alert",
+		valueTemplate: "{{ value_json.last_alert.callsign if value_json.last_alert else None }}",
+		attributes:    "last_alert",
+		icon:          "mdi:bell-ring",
+	},
+}
+
+// homeAssistantDiscovery is the discovery config payload for an MQTT sensor
+type homeAssistantDiscovery struct {
+	Name                   string              `json:"name"`
+	UniqueID               string              `json:"unique_id"`
+	StateTopic             string              `json:"state_topic"`
+	ValueTemplate          string              `json:"value_template"`
+	JSONAttributesTopic    string              `json:"json_attributes_topic,omitempty"`
+	JSONAttributesTemplate string              `json:"json_attributes_template,omitempty"`
+	UnitOfMeasurement      string              `json:"unit_of_measurement,omitempty"`
+	DeviceClass            string              `json:"device_class,omitempty"`
+	StateClass             string              `json:"state_class,omitempty"`
+	Icon                   string              `json:"icon,omitempty"`
+	AvailabilityTopic      string              `json:"availability_topic"`
+	Device                 homeAssistantDevice `json:"device"`
+}
+
+// homeAssistantDevice ties every sensor to a single device in Home Assistant
+type homeAssistantDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+}
+
+// invalidNodeID matches characters Home Assistant does not allow in discovery topics
+var invalidNodeID = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// nodeID returns the discovery node ID derived from the MQTT client ID
+func (p *Publisher) nodeID() string {
+	return invalidNodeID.ReplaceAllString(p.clientID, "_")
+}
+
+// StateTopic returns the topic Home Assistant sensors read their state from
+func (p *Publisher) StateTopic() string {
+	return p.prefix + "/state"
+}
+
+// DiscoveryTopic returns the Home Assistant discovery config topic for a sensor
+func (p *Publisher) DiscoveryTopic(objectID string) string {
+	return p.homeAssistant.DiscoveryPrefix + "/sensor/" + p.nodeID() + "/" + objectID + "/config"
+}
+
+// homeAssistantStatusTopic is where Home Assistant announces it has (re)started
+func (p *Publisher) homeAssistantStatusTopic() string {
+	return p.homeAssistant.DiscoveryPrefix + "/status"
+}
+
+// onConnectHomeAssistant announces the sensors and re-announces them whenever Home Assistant restarts
+func (p *Publisher) onConnectHomeAssistant(c paho.Client) {
+	p.publishDiscovery(c)
+	c.Subscribe(p.homeAssistantStatusTopic(), p.qos, func(c paho.Client, msg paho.Message) {
+		if string(msg.Payload()) == Online {
+			p.publishDiscovery(c)
+			p.publishState()
+		}
+	})
+}
+
+// publishDiscovery publishes a retained discovery config for every sensor.
+// It does not wait for delivery as it runs from paho callbacks.
+func (p *Publisher) publishDiscovery(c paho.Client) {
+	device := homeAssistantDevice{
+		Identifiers:  []string{p.nodeID()},
+		Name:         p.homeAssistant.DeviceName,
+		Manufacturer: "godar",
+		Model:        "Virtual Radar Server monitor",
+	}
+
+	for _, sensor := range homeAssistantSensors {
+		config := homeAssistantDiscovery{
+			Name:              sensor.name,
+			UniqueID:          p.nodeID() + "_" + sensor.objectID,
+			StateTopic:        p.StateTopic(),
+			ValueTemplate:     sensor.valueTemplate,
+			UnitOfMeasurement: sensor.unit,
+			DeviceClass:       sensor.deviceClass,
+			StateClass:        sensor.stateClass,
+			Icon:              sensor.icon,
+			AvailabilityTopic: p.StatusTopic(),
+			Device:            device,
+		}
+		if sensor.attributes != "" {
+			config.JSONAttributesTopic = p.StateTopic()
+			config.JSONAttributesTemplate = "{{ value_json." + sensor.attributes + " | tojson }}"
+		}
+
+		payload, err := json.Marshal(config)
+		if err != nil {
+			p.logger.Error("Failed to encode Home Assistant discovery config", zap.Error(err))
+			continue
+		}
+		c.Publish(p.DiscoveryTopic(sensor.objectID), p.qos, true, payload)
+	}
+}
+
+// HandleSnapshot updates the Home Assistant sensors from the aircraft currently in range.
+// It is intended to be subscribed to the monitor's snapshots and does nothing unless discovery is enabled.
+func (p *Publisher) HandleSnapshot(s events.Snapshot) {
+	if !p.homeAssistant.Enabled {
+		return
+	}
+
+	p.mu.Lock()
+	p.state.AircraftCount = len(s.Aircraft)
+	p.state.Nearest = nil
+	if nearest := s.Nearest(); nearest != nil {
+		p.state.Nearest = newHomeAssistantAircraft(nearest)
+	}
+	p.state.Time = s.Time
+	p.mu.Unlock()
+
+	p.publishState()
+}
+
+// recordAlert makes a notified detection the last alert
+func (p *Publisher) recordAlert(d *detection.Detection) {
+	if !p.homeAssistant.Enabled {
+		return
+	}
+
+	p.mu.Lock()
+	p.state.LastAlert = newHomeAssistantAircraft(d)
+	p.state.Time = d.Time
+	p.mu.Unlock()
+
+	p.publishState()
+}
+
+// publishState publishes the retained sensor state
+func (p *Publisher) publishState() {
+	p.mu.Lock()
+	payload, err := json.Marshal(p.state)
+	p.mu.Unlock()
+	if err != nil {
+		p.logger.Error("Failed to encode Home Assistant state", zap.Error(err))
+		return
+	}
+	_ = p.publish(p.StateTopic(), true, payload)
+}
+
+// newHomeAssistantAircraft summarises a detection, rounding values for display
+func newHomeAssistantAircraft(d *detection.Detection) *HomeAssistantAircraft {
+	return &HomeAssistantAircraft{
+		Callsign:     d.Callsign(),
+		Icao:         d.Aircraft.Icao,
+		Registration: d.Aircraft.Reg,
+		Type:         d.Aircraft.Type,
+		Category:     d.Category(),
+		Distance:     math.Round(d.Distance*100) / 100,
+		Altitude:     d.Aircraft.Alt,
+		Bearing:      math.Round(d.Bearing),
+		Direction:    d.Direction,
+		Elevation:    math.Round(d.Elevation),
+		Military:     d.Aircraft.Mil,
+		Time:         d.Time,
+	}
+}
@@ -0,0 +1,109 @@
+package mqtt_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/events"
+	"github.com/lyarwood/godar/pkg/mqtt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Home Assistant discovery", func() {
+	var (
+		b *broker
+		p *mqtt.Publisher
+	)
+
+	observer := detection.Observer{Latitude: 51.0, Longitude: 0.0}
+
+	BeforeEach(func() {
+		b = newBroker(nil)
+		var err error
+		p, err = mqtt.NewPublisher(config.MQTTConfig{
+			Enabled:     true,
+			Broker:      b.address,
+			ClientID:    "godar-test",
+			TopicPrefix: "godar",
+			HomeAssistant: config.HomeAssistantConfig{
+				Enabled:         true,
+				DiscoveryPrefix: "homeassistant",
+				DeviceName:      "Godar",
+			},
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		p.Close()
+		_ = b.server.Close()
+	})
+
+	It("should announce every sensor on a shared device", func() {
+		for _, objectID := range []string{"nearest_callsign", "nearest_distance", "nearest_altitude", "nearest_bearing", "aircraft_count", "last_alert"} {
+			topic := "homeassistant/sensor/godar-test/" + objectID + "/config"
+			Eventually(b.last(topic)).ShouldNot(BeNil(), topic)
+			pk := b.last(topic)()
+			Expect(pk.FixedHeader.Retain).To(BeTrue())
+
+			var discovery map[string]any
+			Expect(json.Unmarshal(pk.Payload, &discovery)).To(Succeed())
+			Expect(discovery).To(HaveKeyWithValue("unique_id", "godar-test_"+objectID))
+			Expect(discovery).To(HaveKeyWithValue("state_topic", "godar/state"))
+			Expect(discovery).To(HaveKeyWithValue("availability_topic", "godar/status"))
+			Expect(discovery["device"]).To(HaveKeyWithValue("identifiers", ConsistOf("godar-test")))
+		}
+	})
+
+	It("should re-announce sensors when Home Assistant comes online", func() {
+		topic := "homeassistant/sensor/godar-test/aircraft_count/config"
+		Eventually(b.last(topic)).ShouldNot(BeNil())
+		announced := func() int {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			return len(b.messages[topic])
+		}
+		before := announced()
+
+		Expect(b.server.Publish("homeassistant/status", []byte("online"), false, 0)).To(Succeed())
+		Eventually(announced).Should(BeNumerically(">", before))
+	})
+
+	It("should publish the nearest aircraft and count from snapshots", func() {
+		near := detection.New(aircraft.Aircraft{Icao: "4CADC0", Call: "RYR39ZW", Lat: 51.1, Long: 0.0, Alt: 12000}, observer)
+		far := detection.New(aircraft.Aircraft{Icao: "400A0B", Call: "BAW12", Lat: 51.5, Long: 0.0, Alt: 35000}, observer)
+		p.HandleSnapshot(events.NewSnapshot(time.Now(), []*detection.Detection{far, near}))
+
+		state := func() mqtt.HomeAssistantState {
+			var s mqtt.HomeAssistantState
+			if pk := b.last("godar/state")(); pk != nil {
+				_ = json.Unmarshal(pk.Payload, &s)
+			}
+			return s
+		}
+		Eventually(func() int { return state().AircraftCount }).Should(Equal(2))
+		Expect(b.last("godar/state")().FixedHeader.Retain).To(BeTrue())
+
+		s := state()
+		Expect(s.Nearest.Callsign).To(Equal("RYR39ZW"))
+		Expect(s.Nearest.Altitude).To(Equal(12000))
+		Expect(s.Nearest.Distance).To(BeNumerically("~", 11.12, 0.01))
+		Expect(s.Nearest.Bearing).To(Equal(0.0))
+		Expect(s.LastAlert).To(BeNil())
+
+		Expect(p.Send(far)).To(Succeed())
+		Eventually(func() *mqtt.HomeAssistantAircraft { return state().LastAlert }).ShouldNot(BeNil())
+		Expect(state().LastAlert.Callsign).To(Equal("BAW12"))
+
+		p.HandleSnapshot(events.NewSnapshot(time.Now(), nil))
+		Eventually(func() int { return state().AircraftCount }).Should(Equal(0))
+		Expect(state().Nearest).To(BeNil())
+		Expect(state().LastAlert.Callsign).To(Equal("BAW12"))
+	})
+})
@@ -0,0 +1,251 @@
+package mqtt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/events"
+	"go.uber.org/zap"
+)
+
+// Availability payloads published to the status topic
+const (
+	Online  = "online"
+	Offline = "offline"
+)
+
+// publishTimeout bounds how long a publish may block the caller
+const publishTimeout = 5 * time.Second
+
+// AircraftState is the retained payload published for each tracked aircraft
+type AircraftState struct {
+	Icao      string    `json:"icao"`
+	Callsign  string    `json:"callsign,omitempty"`
+	Reg       string    `json:"registration,omitempty"`
+	Type      string    `json:"type,omitempty"`
+	Species   string    `json:"species,omitempty"`
+	Altitude  int       `json:"altitude"`
+	Speed     float64   `json:"speed"`
+	Track     float64   `json:"track"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Squawk    int       `json:"squawk,omitempty"`
+	Military  bool      `json:"military"`
+	Distance  float64   `json:"distance_km"`
+	Bearing   float64   `json:"bearing"`
+	Event     string    `json:"event"`
+	Time      time.Time `json:"time"`
+}
+
+// Publisher publishes per-aircraft state and alerts to an MQTT broker
+type Publisher struct {
+	client   paho.Client
+	clientID string
+	prefix   string
+	qos      byte
+	logger   *zap.Logger
+
+	homeAssistant config.HomeAssistantConfig
+	mu            sync.Mutex
+	state         HomeAssistantState // Last published Home Assistant sensor state
+}
+
+// NewPublisher connects to the configured broker.
+// The status topic is set to online on connect and to offline by the broker's last will.
+// Home Assistant discovery configs are published on connect when enabled.
+func NewPublisher(cfg config.MQTTConfig, logger *zap.Logger) (*Publisher, error) {
+	p := &Publisher{
+		clientID:      cfg.ClientID,
+		prefix:        strings.TrimSuffix(cfg.TopicPrefix, "/"),
+		qos:           byte(cfg.QoS),
+		logger:        logger,
+		homeAssistant: cfg.HomeAssistant,
+	}
+	if p.prefix == "" {
+		p.prefix = "godar"
+	}
+	if p.clientID == "" {
+		p.clientID = "godar"
+	}
+	if p.homeAssistant.DiscoveryPrefix == "" {
+		p.homeAssistant.DiscoveryPrefix = "homeassistant"
+	}
+	if p.homeAssistant.DeviceName == "" {
+		p.homeAssistant.DeviceName = "Godar"
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(p.clientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectTimeout(10*time.Second).
+		SetWill(p.StatusTopic(), Offline, p.qos, true).
+		SetOnConnectHandler(func(c paho.Client) {
+			// Announce availability on every (re)connect
+			c.Publish(p.StatusTopic(), p.qos, true, Online)
+			if p.homeAssistant.Enabled {
+				p.onConnectHomeAssistant(c)
+			}
+		}).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			logger.Warn("MQTT connection lost", zap.Error(err))
+		})
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	p.client = paho.NewClient(opts)
+	token := p.client.Connect()
+	if !token.WaitTimeout(10 * time.Second) {
+		return nil, fmt.Errorf("timed out connecting to MQTT broker %s", cfg.Broker)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker %s: %w", cfg.Broker, err)
+	}
+
+	logger.Info("Connected to MQTT broker",
+		zap.String("broker", cfg.Broker),
+		zap.String("topic_prefix", p.prefix))
+
+	return p, nil
+}
+
+// newTLSConfig builds the TLS configuration, or returns nil when no TLS options are set
+func newTLSConfig(cfg config.MQTTConfig) (*tls.Config, error) {
+	if cfg.CACert == "" && cfg.ClientCert == "" && !cfg.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CACert != "" {
+		pem, err := os.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MQTT CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MQTT client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// StatusTopic returns the availability topic
+func (p *Publisher) StatusTopic() string {
+	return p.prefix + "/status"
+}
+
+// AlertsTopic returns the topic detections are published to
+func (p *Publisher) AlertsTopic() string {
+	return p.prefix + "/alerts"
+}
+
+// AircraftTopic returns the retained state topic for an aircraft
+func (p *Publisher) AircraftTopic(aircraftID string) string {
+	return p.prefix + "/aircraft/" + strings.ToLower(aircraftID)
+}
+
+// HandleEvent publishes the aircraft's latest state, clearing the retained message once it is lost.
+// It is intended to be subscribed to the monitor's event bus.
+func (p *Publisher) HandleEvent(e events.Event) {
+	topic := p.AircraftTopic(e.AircraftID)
+
+	if e.Type == events.Lost {
+		// An empty retained message removes the aircraft from the broker
+		p.publish(topic, true, []byte{})
+		return
+	}
+
+	ac := e.Aircraft
+	state := AircraftState{
+		Icao:      ac.Icao,
+		Callsign:  ac.Call,
+		Reg:       ac.Reg,
+		Type:      ac.Type,
+		Altitude:  ac.Alt,
+		Speed:     ac.Spd,
+		Track:     ac.Trak,
+		Latitude:  ac.Lat,
+		Longitude: ac.Long,
+		Squawk:    int(ac.Sqk),
+		Military:  ac.Mil,
+		Distance:  e.Distance,
+		Bearing:   e.Bearing,
+		Event:     e.Type.String(),
+		Time:      e.Time,
+	}
+	if ac.Species != 0 {
+		state.Species = ac.Species.String()
+	}
+
+	payload, err := json.Marshal(state)
+	if err != nil {
+		p.logger.Error("Failed to encode aircraft state", zap.Error(err))
+		return
+	}
+	p.publish(topic, true, payload)
+}
+
+// Send publishes a detection to the alerts topic
+func (p *Publisher) Send(d *detection.Detection) error {
+	payload, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("failed to encode detection: %w", err)
+	}
+	if err := p.publish(p.AlertsTopic(), false, payload); err != nil {
+		return err
+	}
+	p.recordAlert(d)
+	return nil
+}
+
+// Close marks godar as offline and disconnects from the broker
+func (p *Publisher) Close() {
+	if token := p.client.Publish(p.StatusTopic(), p.qos, true, Offline); !token.WaitTimeout(publishTimeout) {
+		p.logger.Warn("Timed out publishing MQTT offline status")
+	}
+	p.client.Disconnect(250)
+}
+
+// publish sends a message and waits for it to be handed to the broker
+func (p *Publisher) publish(topic string, retained bool, payload []byte) error {
+	token := p.client.Publish(topic, p.qos, retained, payload)
+	if !token.WaitTimeout(publishTimeout) {
+		p.logger.Warn("Timed out publishing to MQTT", zap.String("topic", topic))
+		return fmt.Errorf("timed out publishing to %s", topic)
+	}
+	if err := token.Error(); err != nil {
+		p.logger.Error("Failed to publish to MQTT", zap.String("topic", topic), zap.Error(err))
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	return nil
+}
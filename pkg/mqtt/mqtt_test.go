@@ -0,0 +1,175 @@
+package mqtt_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/events"
+	"github.com/lyarwood/godar/pkg/mqtt"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+// broker is an embedded MQTT broker recording the last message on every topic
+type broker struct {
+	server   *mochi.Server
+	address  string
+	mu       sync.Mutex
+	messages map[string][]packets.Packet
+}
+
+func newBroker(ledger *auth.Ledger) *broker {
+	b := &broker{messages: make(map[string][]packets.Packet)}
+	b.server = mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if ledger != nil {
+		Expect(b.server.AddHook(new(auth.Hook), &auth.Options{Ledger: ledger})).To(Succeed())
+	} else {
+		Expect(b.server.AddHook(new(auth.AllowHook), nil)).To(Succeed())
+	}
+
+	tcp := listeners.NewTCP(listeners.Config{ID: "t1", Address: "127.0.0.1:0"})
+	Expect(b.server.AddListener(tcp)).To(Succeed())
+	Expect(b.server.Serve()).To(Succeed())
+	b.address = "tcp://" + tcp.Address()
+
+	Expect(b.server.Subscribe("#", 1, func(_ *mochi.Client, _ packets.Subscription, pk packets.Packet) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.messages[pk.TopicName] = append(b.messages[pk.TopicName], pk)
+	})).To(Succeed())
+	return b
+}
+
+// last returns the most recent message published to a topic
+func (b *broker) last(topic string) func() *packets.Packet {
+	return func() *packets.Packet {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		msgs := b.messages[topic]
+		if len(msgs) == 0 {
+			return nil
+		}
+		return &msgs[len(msgs)-1]
+	}
+}
+
+func payloadOf(pk *packets.Packet) string {
+	if pk == nil {
+		return ""
+	}
+	return string(pk.Payload)
+}
+
+var _ = Describe("Publisher", func() {
+	var (
+		b   *broker
+		cfg config.MQTTConfig
+	)
+
+	BeforeEach(func() {
+		b = newBroker(nil)
+		cfg = config.MQTTConfig{Enabled: true, Broker: b.address, ClientID: "godar-test", TopicPrefix: "godar", QoS: 1}
+	})
+
+	AfterEach(func() {
+		_ = b.server.Close()
+	})
+
+	It("should announce availability and go offline on close", func() {
+		p, err := mqtt.NewPublisher(cfg, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() string { return payloadOf(b.last("godar/status")()) }).Should(Equal(mqtt.Online))
+		Expect(b.last("godar/status")().FixedHeader.Retain).To(BeTrue())
+
+		p.Close()
+		Eventually(func() string { return payloadOf(b.last("godar/status")()) }).Should(Equal(mqtt.Offline))
+	})
+
+	It("should publish retained aircraft state and clear it when lost", func() {
+		p, err := mqtt.NewPublisher(cfg, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		defer p.Close()
+
+		p.HandleEvent(events.Event{
+			Type:       events.Approaching,
+			Time:       time.Now(),
+			AircraftID: "4CADC0",
+			Aircraft:   aircraft.Aircraft{Icao: "4CADC0", Call: "RYR39ZW", Alt: 38000, Species: aircraft.SpeciesLandPlane},
+			Distance:   12.5,
+			Bearing:    270,
+		})
+
+		Eventually(b.last("godar/aircraft/4cadc0")).ShouldNot(BeNil())
+		pk := b.last("godar/aircraft/4cadc0")()
+		Expect(pk.FixedHeader.Retain).To(BeTrue())
+		Expect(pk.FixedHeader.Qos).To(Equal(byte(1)))
+
+		var state mqtt.AircraftState
+		Expect(json.Unmarshal(pk.Payload, &state)).To(Succeed())
+		Expect(state.Callsign).To(Equal("RYR39ZW"))
+		Expect(state.Distance).To(Equal(12.5))
+		Expect(state.Event).To(Equal("Approaching"))
+		Expect(state.Species).To(Equal("LandPlane"))
+
+		p.HandleEvent(events.Event{Type: events.Lost, AircraftID: "4CADC0"})
+		Eventually(func() int { return len(b.last("godar/aircraft/4cadc0")().Payload) }).Should(Equal(0))
+	})
+
+	It("should publish alerts", func() {
+		p, err := mqtt.NewPublisher(cfg, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		defer p.Close()
+
+		d := detection.New(aircraft.Aircraft{Call: "BAW12", Lat: 51.5, Long: 0.001}, detection.Observer{Latitude: 51.0, Longitude: 0.0})
+		Expect(p.Send(d)).To(Succeed())
+
+		Eventually(b.last("godar/alerts")).ShouldNot(BeNil())
+		pk := b.last("godar/alerts")()
+		Expect(pk.FixedHeader.Retain).To(BeFalse())
+
+		var payload map[string]any
+		Expect(json.Unmarshal(pk.Payload, &payload)).To(Succeed())
+		Expect(payload["aircraft"]).To(HaveKeyWithValue("Call", "BAW12"))
+	})
+
+	It("should authenticate with username and password", func() {
+		_ = b.server.Close()
+		b = newBroker(&auth.Ledger{
+			Auth: auth.AuthRules{{Username: "godar", Password: "secret", Allow: true}},
+		})
+		cfg.Broker = b.address
+
+		cfg.Username, cfg.Password = "godar", "wrong"
+		_, err := mqtt.NewPublisher(cfg, zap.NewNop())
+		Expect(err).To(HaveOccurred())
+
+		cfg.Password = "secret"
+		p, err := mqtt.NewPublisher(cfg, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		p.Close()
+	})
+
+	It("should fail to load a missing CA certificate", func() {
+		cfg.Broker = "ssl://127.0.0.1:8883"
+		cfg.CACert = "/nonexistent/ca.pem"
+		_, err := mqtt.NewPublisher(cfg, zap.NewNop())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("CA certificate"))
+	})
+})
@@ -0,0 +1,188 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/geo"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Chat notifiers", func() {
+	var (
+		server *endpoint
+		d      *detection.Detection
+	)
+
+	decode := func() map[string]any {
+		var payload map[string]any
+		Expect(json.Unmarshal(<-server.bodies, &payload)).To(Succeed())
+		return payload
+	}
+
+	BeforeEach(func() {
+		server = newEndpoint()
+		d = detect(aircraft.Aircraft{Call: "RRR7<1>", Reg: "ZZ336", Type: "A330", Alt: 20000, Spd: 300, Trak: 180, Lat: 51.5, Long: 0.001, Mil: true})
+	})
+
+	Describe("SlackNotifier", func() {
+		It("should post a header and a section of fields", func() {
+			slack, err := notification.NewSlackNotifier(config.SlackConfig{URL: server.URL, SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(slack.Send(d)).To(Succeed())
+			<-server.requests
+
+			payload := decode()
+			Expect(payload).To(HaveKeyWithValue("text", "Aircraft Detected: RRR7<1>"))
+			blocks := payload["blocks"].([]any)
+			Expect(blocks).To(HaveLen(2))
+			Expect(blocks[0]).To(HaveKeyWithValue("type", "header"))
+			Expect(blocks[1]).To(HaveKeyWithValue("fields", ContainElement(HaveKeyWithValue("text", "*Altitude*\n20000 ft"))))
+			Expect(blocks[1]).NotTo(HaveKey("accessory"))
+		})
+
+		It("should split more than ten fields across sections", func() {
+			d.Band = "5 km"
+			d.PreviousDistance = 60
+			d.Passage = &detection.Passage{Time: time.Now(), Distance: 2.1, Direction: "N", Overhead: true}
+			slack, err := notification.NewSlackNotifier(config.SlackConfig{URL: server.URL, SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(slack.Send(d)).To(Succeed())
+			<-server.requests
+
+			blocks := decode()["blocks"].([]any)
+			Expect(blocks).To(HaveLen(3))
+			first := blocks[1].(map[string]any)["fields"].([]any)
+			second := blocks[2].(map[string]any)["fields"].([]any)
+			Expect(first).To(HaveLen(10))
+			Expect(second).NotTo(BeEmpty())
+			Expect(second).To(ContainElement(HaveKeyWithValue("text", HavePrefix("*Closest*"))))
+		})
+
+		It("should label transits whose body is unknown", func() {
+			d.Transits = []geo.Transit{{Time: time.Now().Add(time.Minute)}}
+			slack, err := notification.NewSlackNotifier(config.SlackConfig{URL: server.URL, SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(slack.Send(d)).To(Succeed())
+			<-server.requests
+
+			fields := decode()["blocks"].([]any)[1].(map[string]any)["fields"].([]any)
+			Expect(fields[0]).To(HaveKeyWithValue("text", HavePrefix("*Transit*")))
+		})
+	})
+
+	Describe("DiscordNotifier", func() {
+		It("should post an embed coloured for military aircraft", func() {
+			discord, err := notification.NewDiscordNotifier(config.DiscordConfig{URL: server.URL, Username: "godar", SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(discord.Send(d)).To(Succeed())
+			<-server.requests
+
+			payload := decode()
+			Expect(payload).To(HaveKeyWithValue("username", "godar"))
+			embed := payload["embeds"].([]any)[0].(map[string]any)
+			Expect(embed).To(HaveKeyWithValue("title", "Aircraft Detected: RRR7<1>"))
+			Expect(embed).To(HaveKeyWithValue("color", BeNumerically("==", 0xe74c3c)))
+			Expect(embed["fields"]).To(ContainElement(And(
+				HaveKeyWithValue("name", "Type"),
+				HaveKeyWithValue("value", "A330"),
+				HaveKeyWithValue("inline", true),
+			)))
+		})
+	})
+
+	Describe("MatrixNotifier", func() {
+		It("should send an m.notice with an escaped HTML body", func() {
+			matrix, err := notification.NewMatrixNotifier(config.MatrixConfig{
+				Homeserver:  server.URL,
+				AccessToken: "syt_token",
+				RoomID:      "!room:example.com",
+			}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(matrix.Send(d)).To(Succeed())
+			req := <-server.requests
+			Expect(req.Method).To(Equal(http.MethodPut))
+			Expect(req.URL.EscapedPath()).To(HavePrefix("/_matrix/client/v3/rooms/%21room:example.com/send/m.room.message/godar-"))
+			Expect(req.Header.Get("Authorization")).To(Equal("Bearer syt_token"))
+
+			payload := decode()
+			Expect(payload).To(HaveKeyWithValue("msgtype", "m.notice"))
+			Expect(payload).To(HaveKeyWithValue("format", "org.matrix.custom.html"))
+			Expect(payload["body"]).To(HavePrefix("Aircraft Detected: RRR7<1>\nType: A330"))
+			Expect(payload["formatted_body"]).To(HavePrefix("<b>Aircraft Detected: RRR7&lt;1&gt;</b><br><b>Type:</b> A330"))
+		})
+
+		It("should use a new transaction ID for every message", func() {
+			matrix, err := notification.NewMatrixNotifier(config.MatrixConfig{Homeserver: server.URL, AccessToken: "t", RoomID: "!r:x"}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(matrix.Send(d)).To(Succeed())
+			first := (<-server.requests).URL.Path
+			<-server.bodies
+			Expect(matrix.Send(d)).To(Succeed())
+			second := (<-server.requests).URL.Path
+			<-server.bodies
+
+			Expect(second).NotTo(Equal(first))
+		})
+	})
+
+	Describe("TelegramNotifier", func() {
+		It("should send a message when there is no image", func() {
+			telegram, err := notification.NewTelegramNotifier(config.TelegramConfig{APIURL: server.URL, Token: "123:abc", ChatID: "@spotters", SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(telegram.Send(d)).To(Succeed())
+			req := <-server.requests
+			Expect(req.URL.Path).To(Equal("/bot123:abc/sendMessage"))
+
+			payload := decode()
+			Expect(payload).To(HaveKeyWithValue("chat_id", "@spotters"))
+			Expect(payload).To(HaveKeyWithValue("parse_mode", "HTML"))
+			Expect(payload["text"]).To(HavePrefix("<b>Aircraft Detected: RRR7&lt;1&gt;</b>\n<b>Type:</b> A330"))
+		})
+
+		It("should upload the cached aircraft image with sendPhoto", func() {
+			home := GinkgoT().TempDir()
+			GinkgoT().Setenv("HOME", home)
+			cacheDir := filepath.Join(home, ".cache", "godar", "images")
+			Expect(os.MkdirAll(cacheDir, 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(cacheDir, "ZZ336.jpg"), []byte("jpeg"), 0644)).To(Succeed())
+
+			telegram, err := notification.NewTelegramNotifier(config.TelegramConfig{APIURL: server.URL, Token: "123:abc", ChatID: "42"}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(telegram.Send(d)).To(Succeed())
+			req := <-server.requests
+			<-server.bodies
+			Expect(req.URL.Path).To(Equal("/bot123:abc/sendPhoto"))
+			Expect(req.MultipartForm.Value["chat_id"]).To(Equal([]string{"42"}))
+			Expect(req.MultipartForm.Value["caption"][0]).To(ContainSubstring("<b>Altitude:</b> 20000 ft"))
+			Expect(req.MultipartForm.File["photo"][0].Filename).To(Equal("ZZ336.jpg"))
+		})
+
+		It("should not leak the bot token in errors", func() {
+			telegram, err := notification.NewTelegramNotifier(config.TelegramConfig{APIURL: "http://127.0.0.1:1", Token: "123:secret", ChatID: "42", SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = telegram.Send(d)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).NotTo(ContainSubstring("secret"))
+		})
+	})
+})
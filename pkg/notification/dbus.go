@@ -0,0 +1,247 @@
+package notification
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// Names of the freedesktop notification service
+const (
+	dbusNotificationsName      = "org.freedesktop.Notifications"
+	dbusNotificationsPath      = dbus.ObjectPath("/org/freedesktop/Notifications")
+	dbusNotificationsInterface = "org.freedesktop.Notifications"
+)
+
+// DBusMuteAction is the action key of the "Mute this aircraft" button
+const DBusMuteAction = "mute"
+
+// Urgency levels defined by the notification specification
+const (
+	DBusUrgencyLow      byte = 0
+	DBusUrgencyNormal   byte = 1
+	DBusUrgencyCritical byte = 2
+)
+
+// DBusNotifier shows desktop popups through org.freedesktop.Notifications.
+// Each aircraft keeps a single popup that is replaced in place as it gets closer.
+type DBusNotifier struct {
+	conn          *dbus.Conn
+	notifications dbus.BusObject
+	appName       string
+	expireTimeout int32
+	muteFor       time.Duration
+	markup        bool
+	images        *imageCache
+	format        *formatter
+	logger        *zap.Logger
+
+	mu       sync.Mutex
+	ids      map[string]uint32    // Aircraft to the ID of its popup
+	aircraft map[uint32]string    // Popup ID to aircraft
+	muted    map[string]time.Time // Aircraft to when its mute expires
+	signals  chan *dbus.Signal
+	done     chan struct{}
+}
+
+// NewDBusNotifier connects to the session bus, or cfg.Address when set, and starts listening for popup actions.
+// duration is used as the expiry of each popup, 0 leaves it to the notification server.
+func NewDBusNotifier(cfg config.DBusConfig, duration time.Duration, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*DBusNotifier, error) {
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("dbus: %w", err)
+	}
+
+	conn, err := connectDBus(cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("dbus: failed to connect: %w", err)
+	}
+
+	n := &DBusNotifier{
+		conn:          conn,
+		notifications: conn.Object(dbusNotificationsName, dbusNotificationsPath),
+		appName:       cfg.AppName,
+		expireTimeout: -1,
+		muteFor:       cfg.MuteFor,
+		images:        newImageCache(logger),
+		format:        format,
+		logger:        logger,
+		ids:           make(map[string]uint32),
+		aircraft:      make(map[uint32]string),
+		muted:         make(map[string]time.Time),
+		signals:       make(chan *dbus.Signal, 16),
+		done:          make(chan struct{}),
+	}
+	if n.appName == "" {
+		n.appName = "godar"
+	}
+	if duration > 0 {
+		n.expireTimeout = int32(duration.Milliseconds())
+	}
+
+	var capabilities []string
+	if err := n.notifications.Call(dbusNotificationsInterface+".GetCapabilities", 0).Store(&capabilities); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("dbus: notification service unavailable: %w", err)
+	}
+	n.markup = slices.Contains(capabilities, "body-markup")
+
+	for _, member := range []string{"ActionInvoked", "NotificationClosed"} {
+		if err := conn.AddMatchSignal(
+			dbus.WithMatchObjectPath(dbusNotificationsPath),
+			dbus.WithMatchInterface(dbusNotificationsInterface),
+			dbus.WithMatchMember(member),
+		); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("dbus: failed to subscribe to %s: %w", member, err)
+		}
+	}
+	conn.Signal(n.signals)
+	go n.handleSignals()
+
+	return n, nil
+}
+
+// connectDBus opens a private connection to a bus address, or to the session bus when address is empty
+func connectDBus(address string) (*dbus.Conn, error) {
+	if address == "" {
+		return dbus.ConnectSessionBus()
+	}
+	return dbus.Connect(address)
+}
+
+// Send shows a popup for a detection, replacing the aircraft's previous popup if it is still open
+func (n *DBusNotifier) Send(d *detection.Detection) error {
+	key := aircraftKey(d)
+
+	n.mu.Lock()
+	if until, ok := n.muted[key]; ok {
+		if time.Now().Before(until) {
+			n.mu.Unlock()
+			n.logger.Debug("Aircraft muted, skipping popup", zap.String("callsign", d.Callsign()))
+			return nil
+		}
+		delete(n.muted, key)
+	}
+	replacesID := n.ids[key]
+	n.mu.Unlock()
+
+	title, err := n.format.Title(d)
+	if err != nil {
+		return fmt.Errorf("dbus: %w", err)
+	}
+	body, err := n.format.Body(d)
+	if err != nil {
+		return fmt.Errorf("dbus: %w", err)
+	}
+	if n.markup {
+		body = html.EscapeString(body)
+	}
+
+	var actions []string
+	if n.muteFor > 0 {
+		actions = []string{DBusMuteAction, "Mute this aircraft"}
+	}
+
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(urgency(d)),
+	}
+	imagePath := n.images.forDetection(d)
+	if imagePath != "" {
+		hints["image-path"] = dbus.MakeVariant(imagePath)
+	}
+
+	var id uint32
+	err = n.notifications.Call(dbusNotificationsInterface+".Notify", 0,
+		n.appName, replacesID, imagePath, title, body, actions, hints, n.expireTimeout).Store(&id)
+	if err != nil {
+		return fmt.Errorf("dbus: failed to send notification: %w", err)
+	}
+
+	n.mu.Lock()
+	if replacesID != 0 && replacesID != id {
+		delete(n.aircraft, replacesID)
+	}
+	n.ids[key] = id
+	n.aircraft[id] = key
+	n.mu.Unlock()
+
+	n.logger.Debug("D-Bus notification sent",
+		zap.String("callsign", d.Callsign()),
+		zap.Uint32("id", id),
+		zap.Uint32("replaces_id", replacesID))
+
+	return nil
+}
+
+// Close stops listening for actions and disconnects from the bus
+func (n *DBusNotifier) Close() {
+	// Closing the connection also closes the signal channel, ending handleSignals
+	n.conn.Close()
+	<-n.done
+}
+
+// handleSignals mutes aircraft from popup actions and forgets popups once they are closed
+func (n *DBusNotifier) handleSignals() {
+	defer close(n.done)
+	for signal := range n.signals {
+		if len(signal.Body) < 2 {
+			continue
+		}
+		id, ok := signal.Body[0].(uint32)
+		if !ok {
+			continue
+		}
+
+		n.mu.Lock()
+		key, known := n.aircraft[id]
+		if known {
+			switch signal.Name {
+			case dbusNotificationsInterface + ".ActionInvoked":
+				if action, _ := signal.Body[1].(string); action == DBusMuteAction {
+					n.muted[key] = time.Now().Add(n.muteFor)
+					n.logger.Info("Aircraft muted", zap.String("aircraft", key), zap.Duration("for", n.muteFor))
+				}
+			case dbusNotificationsInterface + ".NotificationClosed":
+				delete(n.aircraft, id)
+				if n.ids[key] == id {
+					delete(n.ids, key)
+				}
+			}
+		}
+		n.mu.Unlock()
+	}
+}
+
+// burstKey shares a single popup between every burst summary
+const burstKey = "burst"
+
+// aircraftKey identifies an aircraft across detections, preferring its ICAO address
+func aircraftKey(d *detection.Detection) string {
+	if len(d.Burst) > 0 {
+		return burstKey
+	}
+	if d.Aircraft.Icao != "" {
+		return d.Aircraft.Icao
+	}
+	return d.Callsign()
+}
+
+// urgency maps the detection's priority onto the three urgency levels of the specification
+func urgency(d *detection.Detection) byte {
+	switch DetectionPriority(d) {
+	case PriorityUrgent:
+		return DBusUrgencyCritical
+	case PriorityLow:
+		return DBusUrgencyLow
+	default:
+		return DBusUrgencyNormal
+	}
+}
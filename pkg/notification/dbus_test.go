@@ -0,0 +1,210 @@
+package notification_test
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+// busConfig is a minimal session bus with the same policy as the stock session.conf
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%s</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// notifyCall records the arguments of a Notify call
+type notifyCall struct {
+	AppName       string
+	ReplacesID    uint32
+	Summary       string
+	Body          string
+	Actions       []string
+	Hints         map[string]dbus.Variant
+	ExpireTimeout int32
+	ID            uint32
+}
+
+// fakeNotifications is a stand-in notification server exported on the private bus
+type fakeNotifications struct {
+	mu     sync.Mutex
+	nextID uint32
+	open   map[uint32]bool
+	calls  []notifyCall
+}
+
+func (f *fakeNotifications) GetCapabilities() ([]string, *dbus.Error) {
+	return []string{"actions", "body", "body-markup"}, nil
+}
+
+func (f *fakeNotifications) Notify(appName string, replacesID uint32, _ string, summary, body string, actions []string, hints map[string]dbus.Variant, expireTimeout int32) (uint32, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := replacesID
+	if !f.open[id] {
+		// Like real servers, a popup that is no longer shown is replaced by a new one
+		f.nextID++
+		id = f.nextID
+	}
+	f.open[id] = true
+	f.calls = append(f.calls, notifyCall{appName, replacesID, summary, body, actions, hints, expireTimeout, id})
+	return id, nil
+}
+
+func (f *fakeNotifications) close(id uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.open, id)
+}
+
+func (f *fakeNotifications) last() notifyCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[len(f.calls)-1]
+}
+
+func (f *fakeNotifications) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+var _ = Describe("DBusNotifier", func() {
+	const (
+		path  = dbus.ObjectPath("/org/freedesktop/Notifications")
+		iface = "org.freedesktop.Notifications"
+	)
+
+	var (
+		server   *fakeNotifications
+		conn     *dbus.Conn
+		notifier *notification.DBusNotifier
+		d        *detection.Detection
+	)
+
+	BeforeEach(func() {
+		daemon, err := exec.LookPath("dbus-daemon")
+		if err != nil {
+			Skip("dbus-daemon is not installed")
+		}
+
+		home := GinkgoT().TempDir()
+		GinkgoT().Setenv("HOME", home)
+		cacheDir := filepath.Join(home, ".cache", "godar", "images")
+		Expect(os.MkdirAll(cacheDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cacheDir, "G-EUUA.jpg"), []byte("jpeg"), 0644)).To(Succeed())
+
+		// Start a private bus so the test never touches the user's desktop
+		busDir := GinkgoT().TempDir()
+		configFile := filepath.Join(busDir, "bus.conf")
+		Expect(os.WriteFile(configFile, []byte(strings.Replace(busConfig, "%s", busDir, 1)), 0644)).To(Succeed())
+		cmd := exec.Command(daemon, "--config-file="+configFile, "--nofork", "--print-address")
+		stdout, err := cmd.StdoutPipe()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cmd.Start()).To(Succeed())
+		DeferCleanup(func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		})
+		address, err := bufio.NewReader(stdout).ReadString('\n')
+		Expect(err).NotTo(HaveOccurred())
+		address = strings.TrimSpace(address)
+
+		server = &fakeNotifications{open: map[uint32]bool{}}
+		conn, err = dbus.Connect(address)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+		Expect(conn.Export(server, path, iface)).To(Succeed())
+		reply, err := conn.RequestName(iface, dbus.NameFlagDoNotQueue)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reply).To(Equal(dbus.RequestNameReplyPrimaryOwner))
+
+		notifier, err = notification.NewDBusNotifier(config.DBusConfig{Address: address, MuteFor: time.Hour}, 30*time.Second, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(notifier.Close)
+
+		d = detection.New(
+			aircraft.Aircraft{Icao: "400A0B", Call: "BAW12", Reg: "G-EUUA", Type: "A320", Alt: 12000, Spd: 300, Trak: 180, Lat: 51.1, Long: 0.001},
+			detection.Observer{Latitude: 51.0, Longitude: 0.0},
+		)
+	})
+
+	It("should replace the aircraft's popup in place", func() {
+		Expect(notifier.Send(d)).To(Succeed())
+		first := server.last()
+		Expect(first.AppName).To(Equal("godar"))
+		Expect(first.ReplacesID).To(BeZero())
+		Expect(first.Summary).To(Equal("Aircraft Detected: BAW12"))
+		Expect(first.ExpireTimeout).To(Equal(int32(30000)))
+		Expect(first.Actions).To(Equal([]string{notification.DBusMuteAction, "Mute this aircraft"}))
+		Expect(first.Hints["urgency"].Value()).To(Equal(notification.DBusUrgencyNormal))
+		Expect(first.Hints["image-path"].Value()).To(HaveSuffix("G-EUUA.jpg"))
+
+		d.PreviousDistance = d.Distance
+		d.Distance = 5.0
+		Expect(notifier.Send(d)).To(Succeed())
+		Expect(server.last().ReplacesID).To(Equal(first.ID))
+		Expect(server.last().Body).To(ContainSubstring("Distance: 5.00 km"))
+
+		other := detection.New(aircraft.Aircraft{Icao: "43C000", Call: "RRR7", Reg: "G-EUUA"}, d.Observer)
+		Expect(notifier.Send(other)).To(Succeed())
+		Expect(server.last().ReplacesID).To(BeZero())
+	})
+
+	It("should mark emergencies as critical", func() {
+		d.Aircraft.Sqk = 7700
+		Expect(notifier.Send(d)).To(Succeed())
+		Expect(server.last().Hints["urgency"].Value()).To(Equal(notification.DBusUrgencyCritical))
+	})
+
+	It("should mute an aircraft from its popup", func() {
+		Expect(notifier.Send(d)).To(Succeed())
+		Expect(conn.Emit(path, iface+".ActionInvoked", server.last().ID, notification.DBusMuteAction)).To(Succeed())
+
+		// Popups stop once the action has been handled
+		Eventually(func() bool {
+			before := server.count()
+			Expect(notifier.Send(d)).To(Succeed())
+			return server.count() == before
+		}).Should(BeTrue())
+	})
+
+	It("should open a new popup once the previous one is closed", func() {
+		Expect(notifier.Send(d)).To(Succeed())
+		first := server.last().ID
+		server.close(first)
+		Expect(conn.Emit(path, iface+".NotificationClosed", first, uint32(2))).To(Succeed())
+
+		Expect(notifier.Send(d)).To(Succeed())
+		second := server.last().ID
+		Expect(second).NotTo(Equal(first))
+
+		// Later updates replace the new popup
+		Eventually(func() uint32 {
+			Expect(notifier.Send(d)).To(Succeed())
+			return server.last().ReplacesID
+		}).Should(Equal(second))
+	})
+})
@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// Embed colours used for Discord notifications
+const (
+	discordColorCivil    = 0x3498db
+	discordColorMilitary = 0xe74c3c
+)
+
+// DiscordNotifier posts detections to a Discord webhook as embeds
+type DiscordNotifier struct {
+	url       string
+	username  string
+	skipImage bool
+	images    *imageCache
+	client    *http.Client
+	logger    *zap.Logger
+	format    *formatter
+}
+
+// NewDiscordNotifier creates a Discord notifier for a webhook URL
+func NewDiscordNotifier(cfg config.DiscordConfig, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*DiscordNotifier, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("discord: url is required")
+	}
+
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("discord: %w", err)
+	}
+
+	return &DiscordNotifier{
+		url:       cfg.URL,
+		username:  cfg.Username,
+		skipImage: cfg.SkipImage,
+		images:    newImageCache(logger),
+		client:    newHTTPClient(cfg.Timeout),
+		logger:    logger,
+		format:    format,
+	}, nil
+}
+
+// Send posts an embed with one inline field per fact and the aircraft image as its thumbnail
+func (n *DiscordNotifier) Send(d *detection.Detection) error {
+	title, err := n.format.Title(d)
+	if err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+
+	color := discordColorCivil
+	if d.Aircraft.Mil {
+		color = discordColorMilitary
+	}
+
+	embed := map[string]any{
+		"title":     title,
+		"color":     color,
+		"timestamp": d.Time.UTC().Format(time.RFC3339),
+	}
+	if n.format.CustomBody() {
+		body, err := n.format.Body(d)
+		if err != nil {
+			return fmt.Errorf("discord: %w", err)
+		}
+		embed["description"] = body
+	} else {
+		var fields []map[string]any
+		for _, field := range n.format.Fields(d) {
+			fields = append(fields, map[string]any{
+				"name":   field.Name,
+				"value":  field.Value,
+				"inline": true,
+			})
+		}
+		embed["fields"] = fields
+	}
+	if !n.skipImage {
+		if imageURL := n.images.urlForDetection(d); imageURL != "" {
+			embed["thumbnail"] = map[string]any{"url": imageURL}
+		}
+	}
+
+	payload := map[string]any{
+		"embeds": []map[string]any{embed},
+	}
+	if n.username != "" {
+		payload["username"] = n.username
+	}
+
+	if err := sendJSON(n.client, http.MethodPost, n.url, payload, nil); err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+
+	n.logger.Debug("Discord notification sent", zap.String("callsign", d.Callsign()))
+
+	return nil
+}
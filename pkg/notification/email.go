@@ -0,0 +1,375 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// Supported email TLS modes
+const (
+	EmailTLSStartTLS = "starttls"
+	EmailTLSImplicit = "implicit"
+	EmailTLSNone     = "none"
+)
+
+// emailTemplate renders the HTML body, one section per detection
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif">
+{{- range .}}
+<h2>{{.Title}}</h2>
+{{- if .ImageCID}}
+<img src="{{.ImageCID}}" alt="{{.Type}}" style="max-width: 480px"><br>
+{{- end}}
+{{- if .Body}}
+<p style="white-space: pre-line">{{.Body}}</p>
+{{- else}}
+<table cellpadding="4">
+{{- range .Fields}}
+<tr><th align="left">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+// emailSection is the template data for a single detection
+type emailSection struct {
+	Title    string
+	Type     string
+	ImageCID template.URL
+	Body     string // Rendered body template, replaces Fields when set
+	Fields   []messageField
+}
+
+// emailImage is an aircraft image embedded in the email
+type emailImage struct {
+	cid  string
+	path string
+}
+
+// EmailNotifier sends HTML emails over SMTP, either one per detection or batched into a digest
+type EmailNotifier struct {
+	host               string
+	port               int
+	tlsMode            string
+	insecureSkipVerify bool
+	username           string
+	password           string
+	from               string
+	to                 []string
+	digest             time.Duration
+	skipImage          bool
+	timeout            time.Duration
+	images             *imageCache
+	logger             *zap.Logger
+	format             *formatter
+
+	mu      sync.Mutex
+	pending []*detection.Detection // Detections waiting for the digest to be sent
+	timer   *time.Timer
+}
+
+// NewEmailNotifier creates an SMTP notifier
+func NewEmailNotifier(cfg config.EmailConfig, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*EmailNotifier, error) {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return nil, fmt.Errorf("email: host, from and to are required")
+	}
+
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("email: %w", err)
+	}
+
+	e := &EmailNotifier{
+		host:               cfg.Host,
+		port:               cfg.Port,
+		tlsMode:            strings.ToLower(cfg.TLS),
+		insecureSkipVerify: cfg.InsecureSkipVerify,
+		username:           cfg.Username,
+		password:           cfg.Password,
+		from:               cfg.From,
+		to:                 cfg.To,
+		digest:             cfg.Digest,
+		skipImage:          cfg.SkipImage,
+		timeout:            cfg.Timeout,
+		images:             newImageCache(logger),
+		logger:             logger,
+		format:             format,
+	}
+
+	switch e.tlsMode {
+	case "":
+		e.tlsMode = EmailTLSStartTLS
+	case EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone:
+	default:
+		return nil, fmt.Errorf("email: unknown tls mode %q", cfg.TLS)
+	}
+	if e.port == 0 {
+		e.port = 587
+		if e.tlsMode == EmailTLSImplicit {
+			e.port = 465
+		}
+	}
+	if e.timeout == 0 {
+		e.timeout = defaultHTTPTimeout
+	}
+
+	return e, nil
+}
+
+// Send emails a detection, or queues it for the next digest when digest mode is enabled
+func (e *EmailNotifier) Send(d *detection.Detection) error {
+	if e.digest == 0 {
+		subject, err := e.format.Title(d)
+		if err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+		if err := e.sendEmail(subject, []*detection.Detection{d}); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pending = append(e.pending, d)
+	if e.timer == nil {
+		e.timer = time.AfterFunc(e.digest, e.flushDigest)
+	}
+	return nil
+}
+
+// Close sends any detections still waiting for the digest
+func (e *EmailNotifier) Close() {
+	e.mu.Lock()
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	e.mu.Unlock()
+	e.flushDigest()
+}
+
+// flushDigest sends every pending detection in a single email
+func (e *EmailNotifier) flushDigest() {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.timer = nil
+	e.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	subject := fmt.Sprintf("Godar digest: %d aircraft detected", len(batch))
+	if err := e.sendEmail(subject, batch); err != nil {
+		e.logger.Error("Failed to send email digest", zap.Int("detections", len(batch)), zap.Error(err))
+		return
+	}
+	e.logger.Debug("Email digest sent", zap.Int("detections", len(batch)))
+}
+
+// sendEmail renders and delivers an email for the given detections
+func (e *EmailNotifier) sendEmail(subject string, detections []*detection.Detection) error {
+	msg, err := e.buildMessage(subject, detections)
+	if err != nil {
+		return err
+	}
+	return e.deliver(msg)
+}
+
+// buildMessage renders a multipart/related message with the HTML body and its inline images
+func (e *EmailNotifier) buildMessage(subject string, detections []*detection.Detection) ([]byte, error) {
+	var images []emailImage
+	cids := map[string]string{} // Image path to content ID, so each image is embedded once
+	sections := make([]emailSection, 0, len(detections))
+
+	for _, d := range detections {
+		title, err := e.format.Title(d)
+		if err != nil {
+			return nil, err
+		}
+		section := emailSection{
+			Title: title,
+			Type:  d.Aircraft.Type,
+		}
+		if e.format.CustomBody() {
+			if section.Body, err = e.format.Body(d); err != nil {
+				return nil, err
+			}
+		} else {
+			section.Fields = e.format.Fields(d)
+		}
+		if !e.skipImage {
+			if imagePath := e.images.forDetection(d); imagePath != "" {
+				cid, ok := cids[imagePath]
+				if !ok {
+					cid = fmt.Sprintf("aircraft-%d@godar", len(images))
+					cids[imagePath] = cid
+					images = append(images, emailImage{cid: cid, path: imagePath})
+				}
+				section.ImageCID = template.URL("cid:" + cid)
+			}
+		}
+		sections = append(sections, section)
+	}
+
+	var htmlBody bytes.Buffer
+	if err := emailTemplate.Execute(&htmlBody, sections); err != nil {
+		return nil, fmt.Errorf("failed to render email: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	htmlPart, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	qp := quotedprintable.NewWriter(htmlPart)
+	if _, err := qp.Write(htmlBody.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+
+	for _, image := range images {
+		data, err := os.ReadFile(image.path)
+		if err != nil {
+			e.logger.Debug("Failed to read aircraft image", zap.String("image", image.path), zap.Error(err))
+			continue
+		}
+		imagePart, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {http.DetectContentType(data)},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + image.cid + ">"},
+			"Content-Disposition":       {mime.FormatMediaType("inline", map[string]string{"filename": filepath.Base(image.path)})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(imagePart, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%d.godar@%s>\r\n", time.Now().UnixNano(), e.host)
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/related; boundary=%q; type=\"text/html\"\r\n\r\n", parts.Boundary())
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// writeBase64Lines writes data as base64 wrapped at 76 characters as required by MIME
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(76, len(encoded))
+		if _, err := w.Write([]byte(encoded[:n] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// deliver connects to the SMTP server and sends the message to every recipient
+func (e *EmailNotifier) deliver(msg []byte) error {
+	addr := net.JoinHostPort(e.host, strconv.Itoa(e.port))
+	tlsConfig := &tls.Config{ServerName: e.host, InsecureSkipVerify: e.insecureSkipVerify}
+	dialer := &net.Dialer{Timeout: e.timeout}
+
+	var conn net.Conn
+	var err error
+	if e.tlsMode == EmailTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(e.timeout))
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if e.tlsMode == EmailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return fmt.Errorf("MAIL FROM failed: %w", err)
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
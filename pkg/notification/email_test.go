@@ -0,0 +1,253 @@
+package notification_test
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+// smtpMessage is an email accepted by the stand-in SMTP server
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer runs a minimal plain text SMTP server that records every message it accepts
+func startSMTPServer() (string, int, chan smtpMessage, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	messages := make(chan smtpMessage, 10)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, messages)
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, messages, func() { listener.Close() }
+}
+
+func serveSMTP(conn net.Conn, messages chan<- smtpMessage) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	var msg smtpMessage
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+			msg.auth = strings.ReplaceAll(string(credentials), "\x00", ":")
+			reply("235 Authenticated")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<> ")
+			reply("250 OK")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<> "))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.data = data.String()
+			messages <- msg
+			msg = smtpMessage{}
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// emailParts parses a message into its subject, HTML body and inline attachments keyed by Content-ID
+func emailParts(data string) (string, string, map[string]string) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	Expect(err).NotTo(HaveOccurred())
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	Expect(err).NotTo(HaveOccurred())
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(mediaType).To(Equal("multipart/related"))
+
+	var html string
+	inline := map[string]string{}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		Expect(err).NotTo(HaveOccurred())
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			body, _ := io.ReadAll(quotedprintable.NewReader(part))
+			html = string(body)
+			continue
+		}
+		body, _ := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		inline[part.Header.Get("Content-ID")] = string(body)
+	}
+	return subject, html, inline
+}
+
+var _ = Describe("EmailNotifier", func() {
+	var (
+		host     string
+		port     int
+		messages chan smtpMessage
+		stop     func()
+		cfg      config.EmailConfig
+		d        *detection.Detection
+	)
+
+	BeforeEach(func() {
+		host, port, messages, stop = startSMTPServer()
+		cfg = config.EmailConfig{
+			Host:      host,
+			Port:      port,
+			TLS:       notification.EmailTLSNone,
+			Username:  "godar",
+			Password:  "secret",
+			From:      "godar@example.com",
+			To:        []string{"spotter@example.com", "club@example.com"},
+			SkipImage: true,
+		}
+		d = detection.New(
+			aircraft.Aircraft{Call: "BAW12", Reg: "G-EUUA", Type: "A320", Alt: 35000, Spd: 450, Trak: 180, Lat: 51.5, Long: 0.001},
+			detection.Observer{Latitude: 51.0, Longitude: 0.0},
+		)
+	})
+
+	AfterEach(func() {
+		stop()
+	})
+
+	It("should email each detection to every recipient", func() {
+		email, err := notification.NewEmailNotifier(cfg, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(email.Send(d)).To(Succeed())
+
+		var msg smtpMessage
+		Eventually(messages).Should(Receive(&msg))
+		Expect(msg.auth).To(Equal(":godar:secret"))
+		Expect(msg.from).To(Equal("godar@example.com"))
+		Expect(msg.to).To(Equal([]string{"spotter@example.com", "club@example.com"}))
+
+		subject, html, inline := emailParts(msg.data)
+		Expect(subject).To(Equal("Aircraft Detected: BAW12"))
+		Expect(html).To(ContainSubstring("<th align=\"left\">Altitude</th><td>35000 ft</td>"))
+		Expect(inline).To(BeEmpty())
+	})
+
+	It("should embed the aircraft image inline", func() {
+		home := GinkgoT().TempDir()
+		GinkgoT().Setenv("HOME", home)
+		cacheDir := filepath.Join(home, ".cache", "godar", "images")
+		Expect(os.MkdirAll(cacheDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cacheDir, "G-EUUA.jpg"), []byte("jpeg"), 0644)).To(Succeed())
+
+		cfg.SkipImage = false
+		email, err := notification.NewEmailNotifier(cfg, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(email.Send(d)).To(Succeed())
+
+		var msg smtpMessage
+		Eventually(messages).Should(Receive(&msg))
+		_, html, inline := emailParts(msg.data)
+		Expect(html).To(ContainSubstring(`<img src="cid:aircraft-0@godar"`))
+		Expect(inline).To(HaveKeyWithValue("<aircraft-0@godar>", "jpeg"))
+	})
+
+	It("should batch detections into a digest", func() {
+		cfg.Digest = 200 * time.Millisecond
+		email, err := notification.NewEmailNotifier(cfg, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(email.Send(d)).To(Succeed())
+		other := *d
+		other.Aircraft.Call = "EZY34"
+		Expect(email.Send(&other)).To(Succeed())
+		Consistently(messages, 100*time.Millisecond).ShouldNot(Receive())
+
+		var msg smtpMessage
+		Eventually(messages).Should(Receive(&msg))
+		subject, html, _ := emailParts(msg.data)
+		Expect(subject).To(Equal("Godar digest: 2 aircraft detected"))
+		Expect(html).To(ContainSubstring("Aircraft Detected: BAW12"))
+		Expect(html).To(ContainSubstring("Aircraft Detected: EZY34"))
+	})
+
+	It("should flush a pending digest on close", func() {
+		cfg.Digest = time.Hour
+		email, err := notification.NewEmailNotifier(cfg, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(email.Send(d)).To(Succeed())
+		email.Close()
+
+		var msg smtpMessage
+		Eventually(messages).Should(Receive(&msg))
+		subject, _, _ := emailParts(msg.data)
+		Expect(subject).To(Equal("Godar digest: 1 aircraft detected"))
+	})
+
+	It("should require STARTTLS when configured", func() {
+		cfg.TLS = notification.EmailTLSStartTLS
+		email, err := notification.NewEmailNotifier(cfg, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = email.Send(d)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("does not support STARTTLS"))
+	})
+})
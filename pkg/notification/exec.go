@@ -0,0 +1,174 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// defaultExecTimeout is used when a hook has no timeout configured
+const defaultExecTimeout = 30 * time.Second
+
+// maxExecOutput limits how much of a hook's output is logged
+const maxExecOutput = 1024
+
+// ExecNotifier runs an external command for each detection.
+// The detection is written to the command's stdin as JSON and its key fields are set as GODAR_* environment variables.
+type ExecNotifier struct {
+	name    string
+	command string
+	args    []string
+	env     []string
+	timeout time.Duration
+	slots   chan struct{} // Limits concurrent runs
+	wg      sync.WaitGroup
+	logger  *zap.Logger
+}
+
+// NewExecNotifier creates a hook notifier for a command
+func NewExecNotifier(cfg config.ExecConfig, logger *zap.Logger) (*ExecNotifier, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("exec: command is required")
+	}
+
+	n := &ExecNotifier{
+		name:    cfg.Name,
+		command: cfg.Command,
+		args:    cfg.Args,
+		timeout: cfg.Timeout,
+		logger:  logger,
+	}
+	if n.name == "" {
+		n.name = cfg.Command
+	}
+	if n.timeout == 0 {
+		n.timeout = defaultExecTimeout
+	}
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	n.slots = make(chan struct{}, maxConcurrent)
+
+	for key, value := range cfg.Env {
+		n.env = append(n.env, key+"="+value)
+	}
+
+	return n, nil
+}
+
+// Send starts the command in the background.
+// It fails without running the command if max_concurrent runs are already in progress.
+func (n *ExecNotifier) Send(d *detection.Detection) error {
+	input, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("exec %q: failed to encode detection: %w", n.name, err)
+	}
+
+	select {
+	case n.slots <- struct{}{}:
+	default:
+		return fmt.Errorf("exec %q: skipped %s, %d runs already in progress", n.name, d.Callsign(), cap(n.slots))
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		defer func() { <-n.slots }()
+		n.run(d, input)
+	}()
+
+	return nil
+}
+
+// Close waits for running commands to finish
+func (n *ExecNotifier) Close() {
+	n.wg.Wait()
+}
+
+// run executes the command and logs its exit status and output
+func (n *ExecNotifier) run(d *detection.Detection, input []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, n.command, n.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(append(os.Environ(), detectionEnv(d)...), n.env...)
+	// Don't wait forever for output from processes the command left running
+	cmd.WaitDelay = time.Second
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	start := time.Now()
+	err := cmd.Run()
+
+	fields := []zap.Field{
+		zap.String("hook", n.name),
+		zap.String("callsign", d.Callsign()),
+		zap.Int("exit_code", cmd.ProcessState.ExitCode()),
+		zap.Duration("duration", time.Since(start)),
+		zap.String("output", truncate(output.String(), maxExecOutput)),
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		n.logger.Warn("Exec hook timed out", append(fields, zap.Duration("timeout", n.timeout))...)
+	case err != nil:
+		n.logger.Warn("Exec hook failed", append(fields, zap.Error(err))...)
+	default:
+		n.logger.Debug("Exec hook finished", fields...)
+	}
+}
+
+// detectionEnv returns the GODAR_* environment variables describing a detection
+func detectionEnv(d *detection.Detection) []string {
+	ac := d.Aircraft
+	env := map[string]string{
+		"GODAR_CALLSIGN":       d.Callsign(),
+		"GODAR_ICAO":           ac.Icao,
+		"GODAR_REGISTRATION":   ac.Reg,
+		"GODAR_TYPE":           ac.Type,
+		"GODAR_CATEGORY":       d.Category(),
+		"GODAR_ALTITUDE":       strconv.Itoa(ac.Alt),
+		"GODAR_SPEED":          strconv.FormatFloat(ac.Spd, 'f', 1, 64),
+		"GODAR_LATITUDE":       strconv.FormatFloat(ac.Lat, 'f', 6, 64),
+		"GODAR_LONGITUDE":      strconv.FormatFloat(ac.Long, 'f', 6, 64),
+		"GODAR_MILITARY":       strconv.FormatBool(ac.Mil),
+		"GODAR_DISTANCE_KM":    strconv.FormatFloat(d.Distance, 'f', 2, 64),
+		"GODAR_SLANT_RANGE_KM": strconv.FormatFloat(d.SlantRange, 'f', 2, 64),
+		"GODAR_ELEVATION":      strconv.FormatFloat(d.Elevation, 'f', 0, 64),
+		"GODAR_BEARING":        strconv.FormatFloat(d.Bearing, 'f', 0, 64),
+		"GODAR_DIRECTION":      d.Direction,
+		"GODAR_CLOCK":          strconv.Itoa(d.ClockPosition),
+		"GODAR_BRAA":           d.BRAA.String(),
+	}
+
+	vars := make([]string, 0, len(env))
+	for key, value := range env {
+		vars = append(vars, key+"="+value)
+	}
+	return vars
+}
+
+// truncate shortens s to at most n bytes, marking that it was cut
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,116 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var _ = Describe("ExecNotifier", func() {
+	var (
+		dir    string
+		logger *zap.Logger
+		logs   *observer.ObservedLogs
+		d      *detection.Detection
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		var core zapcore.Core
+		core, logs = observer.New(zapcore.DebugLevel)
+		logger = zap.New(core)
+		d = detection.New(
+			aircraft.Aircraft{Call: "BAW12", Icao: "400A0B", Type: "A320", Alt: 35000, Spd: 450, Trak: 180, Lat: 51.5, Long: 0.001},
+			detection.Observer{Latitude: 51.0, Longitude: 0.0},
+		)
+	})
+
+	It("should pass the detection on stdin and key fields in the environment", func() {
+		hook, err := notification.NewExecNotifier(config.ExecConfig{
+			Command: "/bin/sh",
+			Args:    []string{"-c", `cat > "$OUT/stdin.json"; echo "$GODAR_CALLSIGN $GODAR_ALTITUDE $GODAR_DIRECTION $GODAR_EXTRA" > "$OUT/env"`},
+			Env:     map[string]string{"OUT": dir, "GODAR_EXTRA": "custom"},
+		}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(hook.Send(d)).To(Succeed())
+		hook.Close()
+
+		stdin, err := os.ReadFile(filepath.Join(dir, "stdin.json"))
+		Expect(err).NotTo(HaveOccurred())
+		var payload map[string]any
+		Expect(json.Unmarshal(stdin, &payload)).To(Succeed())
+		Expect(payload).To(HaveKeyWithValue("direction", "N"))
+		Expect(payload["aircraft"]).To(HaveKeyWithValue("Call", "BAW12"))
+
+		env, err := os.ReadFile(filepath.Join(dir, "env"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(env)).To(Equal("BAW12 35000 N custom\n"))
+
+		Expect(logs.FilterMessage("Exec hook finished").FilterField(zap.Int("exit_code", 0)).Len()).To(Equal(1))
+	})
+
+	It("should log the exit status and output of a failing command", func() {
+		hook, err := notification.NewExecNotifier(config.ExecConfig{
+			Name:    "camera",
+			Command: "/bin/sh",
+			Args:    []string{"-c", "echo no camera >&2; exit 3"},
+		}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(hook.Send(d)).To(Succeed())
+		hook.Close()
+
+		failed := logs.FilterMessage("Exec hook failed").All()
+		Expect(failed).To(HaveLen(1))
+		Expect(failed[0].ContextMap()).To(HaveKeyWithValue("hook", "camera"))
+		Expect(failed[0].ContextMap()).To(HaveKeyWithValue("exit_code", int64(3)))
+		Expect(failed[0].ContextMap()).To(HaveKeyWithValue("output", "no camera"))
+	})
+
+	It("should kill commands that exceed the timeout", func() {
+		hook, err := notification.NewExecNotifier(config.ExecConfig{
+			Command: "/bin/sleep",
+			Args:    []string{"10"},
+			Timeout: 100 * time.Millisecond,
+		}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		start := time.Now()
+		Expect(hook.Send(d)).To(Succeed())
+		hook.Close()
+
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(logs.FilterMessage("Exec hook timed out").Len()).To(Equal(1))
+	})
+
+	It("should skip notifications beyond the concurrency limit", func() {
+		hook, err := notification.NewExecNotifier(config.ExecConfig{
+			Command:       "/bin/sleep",
+			Args:          []string{"0.3"},
+			MaxConcurrent: 1,
+		}, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(hook.Send(d)).To(Succeed())
+		err = hook.Send(d)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("1 runs already in progress"))
+
+		hook.Close()
+		Expect(hook.Send(d)).To(Succeed())
+		hook.Close()
+	})
+})
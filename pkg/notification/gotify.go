@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/templates"
+	"go.uber.org/zap"
+)
+
+// GotifyNotifier sends detections to a Gotify server as application messages
+type GotifyNotifier struct {
+	url       string
+	token     string
+	priority  int
+	markdown  bool
+	click     *template.Template
+	skipImage bool
+	images    *imageCache
+	client    *http.Client
+	logger    *zap.Logger
+	format    *formatter
+}
+
+// gotifyMessage is the body of POST /message
+type gotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority,omitempty"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// NewGotifyNotifier creates a Gotify notifier using an application token
+func NewGotifyNotifier(cfg config.GotifyConfig, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*GotifyNotifier, error) {
+	if cfg.URL == "" || cfg.Token == "" {
+		return nil, fmt.Errorf("gotify: url and token are required")
+	}
+
+	click, err := templates.Parse("gotify-click", cfg.Click)
+	if err != nil {
+		return nil, fmt.Errorf("gotify: invalid click template: %w", err)
+	}
+
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("gotify: %w", err)
+	}
+
+	return &GotifyNotifier{
+		url:       strings.TrimSuffix(cfg.URL, "/") + "/message",
+		token:     cfg.Token,
+		priority:  cfg.Priority,
+		markdown:  cfg.Markdown,
+		click:     click,
+		skipImage: cfg.SkipImage,
+		images:    newImageCache(logger),
+		client:    newHTTPClient(cfg.Timeout),
+		logger:    logger,
+		format:    format,
+	}, nil
+}
+
+// Send posts a detection to Gotify.
+// Gotify cannot store attachments, so the aircraft image is referenced by URL.
+func (g *GotifyNotifier) Send(d *detection.Detection) error {
+	click, err := templates.Render(g.click, d)
+	if err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+
+	title, err := g.format.Title(d)
+	if err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+	message, err := g.format.Body(d)
+	if err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+
+	var imageURL string
+	if !g.skipImage {
+		imageURL = g.images.urlForDetection(d)
+	}
+
+	msg := gotifyMessage{
+		Title:    title,
+		Message:  message,
+		Priority: g.priority,
+		Extras:   map[string]any{},
+	}
+
+	if g.markdown {
+		// Markdown needs two trailing spaces to keep each line break
+		msg.Message = strings.ReplaceAll(msg.Message, "\n", "  \n")
+		if imageURL != "" {
+			msg.Message += fmt.Sprintf("\n\n![%s](%s)", d.Aircraft.Type, imageURL)
+		}
+		msg.Extras["client::display"] = map[string]any{"contentType": "text/markdown"}
+	}
+
+	notification := map[string]any{}
+	if click != "" {
+		notification["click"] = map[string]any{"url": click}
+	}
+	if imageURL != "" {
+		notification["bigImageUrl"] = imageURL
+	}
+	if len(notification) > 0 {
+		msg.Extras["client::notification"] = notification
+	}
+
+	header := http.Header{}
+	header.Set("X-Gotify-Key", g.token)
+	if err := sendJSON(g.client, http.MethodPost, g.url, msg, header); err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+
+	g.logger.Debug("Gotify notification sent",
+		zap.String("callsign", d.Callsign()),
+		zap.String("image", imageURL))
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("GotifyNotifier", func() {
+	var (
+		server *endpoint
+		d      *detection.Detection
+	)
+
+	BeforeEach(func() {
+		server = newEndpoint()
+		d = detect(baw12)
+	})
+
+	It("should post a message with the application token", func() {
+		gotify, err := notification.NewGotifyNotifier(config.GotifyConfig{URL: server.URL + "/", Token: "app-token", Priority: 8, SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(gotify.Send(d)).To(Succeed())
+
+		req := <-server.requests
+		Expect(req.URL.Path).To(Equal("/message"))
+		Expect(req.Header.Get("X-Gotify-Key")).To(Equal("app-token"))
+
+		var msg map[string]any
+		Expect(json.Unmarshal(<-server.bodies, &msg)).To(Succeed())
+		Expect(msg).To(HaveKeyWithValue("title", "Aircraft Detected: BAW12"))
+		Expect(msg).To(HaveKeyWithValue("priority", BeNumerically("==", 8)))
+		Expect(msg["message"]).To(ContainSubstring("Type: A320\nAltitude: 35000 ft"))
+		Expect(msg).NotTo(HaveKey("extras"))
+	})
+
+	It("should add markdown and click extras", func() {
+		gotify, err := notification.NewGotifyNotifier(config.GotifyConfig{
+			URL:       server.URL,
+			Token:     "app-token",
+			Markdown:  true,
+			Click:     "https://globe.adsbexchange.com/?icao={{.Aircraft.Icao}}",
+			SkipImage: true,
+		}, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(gotify.Send(d)).To(Succeed())
+		<-server.requests
+
+		var msg map[string]any
+		Expect(json.Unmarshal(<-server.bodies, &msg)).To(Succeed())
+		Expect(msg["message"]).To(ContainSubstring("Type: A320  \nAltitude: 35000 ft"))
+		Expect(msg["extras"]).To(HaveKeyWithValue("client::display", HaveKeyWithValue("contentType", "text/markdown")))
+		Expect(msg["extras"]).To(HaveKeyWithValue("client::notification",
+			HaveKeyWithValue("click", HaveKeyWithValue("url", "https://globe.adsbexchange.com/?icao=400A0B"))))
+	})
+
+	It("should require a token", func() {
+		_, err := notification.NewGotifyNotifier(config.GotifyConfig{URL: server.URL}, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,156 @@
+package notification
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// Reasons a notification is held back, as reported in the logs
+const (
+	heldPriority         = "priority"
+	heldQuietHours       = "quiet_hours"
+	heldChannelRateLimit = "channel_rate_limit"
+	heldRateLimit        = "rate_limit"
+)
+
+// routed is a detection on its way to a channel at the priority the rules gave it
+type routed struct {
+	d        *detection.Detection
+	priority Priority
+}
+
+// governor holds notifications back by priority, quiet hours and rate limits and collapses bursts into summaries.
+// Each channel has its own limits, the governor's rate limit and quiet hours apply to every channel.
+type governor struct {
+	quietHours *quietHours
+	burst      int // Notifications per channel and poll that are collapsed into a summary, 0 disables
+	logger     *zap.Logger
+
+	mu      sync.Mutex
+	limiter *tokenBucket
+}
+
+// newGovernor creates the shared limits from the configuration
+func newGovernor(cfg config.NotificationConfig, logger *zap.Logger) (*governor, error) {
+	quiet, err := parseQuietHours(cfg.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+	return &governor{
+		quietHours: quiet,
+		burst:      cfg.Burst.Threshold,
+		logger:     logger,
+		limiter:    newTokenBucket(cfg.RateLimit),
+	}, nil
+}
+
+// admit returns the part of a channel's share of a poll that may be sent.
+// Once as many notifications as the burst threshold remain they are collapsed into one summary.
+func (g *governor) admit(ch *channel, items []routed) []routed {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var admitted []routed
+	for _, item := range items {
+		switch {
+		case item.priority < ch.minPriority:
+			g.hold(ch, item, heldPriority)
+		case ch.quietHours.holds(item.d.Time, item.priority), g.quietHours.holds(item.d.Time, item.priority):
+			g.hold(ch, item, heldQuietHours)
+		default:
+			admitted = append(admitted, item)
+		}
+	}
+
+	if g.burst > 0 && len(admitted) >= g.burst {
+		admitted = []routed{g.summarise(ch, admitted)}
+	}
+
+	var allowed []routed
+	for _, item := range admitted {
+		if !ch.limiter.allow(item.d.Time) {
+			g.hold(ch, item, heldChannelRateLimit)
+			continue
+		}
+		if !g.limiter.allow(item.d.Time) {
+			ch.limiter.refund()
+			g.hold(ch, item, heldRateLimit)
+			continue
+		}
+		ch.sent++
+		allowed = append(allowed, item)
+	}
+	return allowed
+}
+
+// summarise collapses a burst into a single notification at the highest priority within it
+func (g *governor) summarise(ch *channel, items []routed) routed {
+	detections := make([]*detection.Detection, len(items))
+	var priority Priority
+	for i, item := range items {
+		detections[i] = item.d
+		priority = max(priority, item.priority)
+	}
+	summary := detection.Summarise(detections)
+	ch.collapsed += len(items)
+
+	g.logger.Info("Collapsed notification burst into a summary",
+		zap.String("channel", ch.name),
+		zap.Int("aircraft", len(items)),
+		zap.String("summary", summary.Summary()))
+
+	return routed{d: summary, priority: priority}
+}
+
+// hold records why a notification was not sent. Rate limits are logged at info level as they lose notifications
+// that would otherwise have been sent.
+func (g *governor) hold(ch *channel, item routed, reason string) {
+	if ch.held == nil {
+		ch.held = make(map[string]int)
+	}
+	ch.held[reason]++
+
+	log := g.logger.Debug
+	if reason == heldChannelRateLimit || reason == heldRateLimit {
+		log = g.logger.Info
+	}
+	log("Notification held back",
+		zap.String("reason", reason),
+		zap.String("channel", ch.name),
+		zap.String("callsign", item.d.Callsign()),
+		zap.Stringer("priority", item.priority))
+}
+
+// logState reports the remaining tokens, quiet hours and what each channel sent and held back since the last call
+func (g *governor) logState(channels []*channel, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fields := []zap.Field{zap.Bool("quiet_hours", g.quietHours.contains(now))}
+	if g.limiter != nil {
+		fields = append(fields, zap.Float64("tokens", g.limiter.remaining()))
+	}
+	g.logger.Debug("Notification governor state", fields...)
+
+	for _, ch := range channels {
+		if len(ch.notifier.backends) == 0 {
+			continue
+		}
+		fields := []zap.Field{
+			zap.String("channel", ch.name),
+			zap.Int("sent", ch.sent),
+			zap.Int("collapsed", ch.collapsed),
+			zap.Any("held", ch.held),
+			zap.Bool("quiet_hours", ch.quietHours.contains(now)),
+		}
+		if ch.limiter != nil {
+			fields = append(fields, zap.Float64("tokens", ch.limiter.remaining()))
+		}
+		g.logger.Debug("Notification channel state", fields...)
+		ch.sent, ch.collapsed, ch.held = 0, 0, nil
+	}
+}
@@ -0,0 +1,190 @@
+package notification
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification/images"
+	"go.uber.org/zap"
+)
+
+// imageCache downloads aircraft images once and shares them between backends
+type imageCache struct {
+	logger       *zap.Logger
+	cacheDir     string
+	imageService *images.AircraftImageService
+}
+
+// newImageCache creates an image cache under ~/.cache/godar/images
+func newImageCache(logger *zap.Logger) *imageCache {
+	return &imageCache{
+		logger:       logger,
+		cacheDir:     filepath.Join(os.Getenv("HOME"), ".cache", "godar", "images"),
+		imageService: images.NewAircraftImageService(),
+	}
+}
+
+// forDetection returns the path of a cached image for the detected aircraft, or "" if none was found
+func (c *imageCache) forDetection(d *detection.Detection) string {
+	return c.getAircraftImage(imageRegistration(d), d.Aircraft.Type)
+}
+
+// urlForDetection returns a remote image URL for the detected aircraft, or "" if none was found.
+// It is used by backends that can only reference images rather than upload them.
+func (c *imageCache) urlForDetection(d *detection.Detection) string {
+	if imageURL := c.getAircraftImageURL(strings.TrimSpace(strings.ToUpper(imageRegistration(d)))); imageURL != "" {
+		return imageURL
+	}
+	if d.Aircraft.Type == "" {
+		return ""
+	}
+	return c.getAircraftTypeImageURL(strings.TrimSpace(strings.ToUpper(d.Aircraft.Type)))
+}
+
+// imageRegistration prefers the registration over the callsign when looking up images
+func imageRegistration(d *detection.Detection) string {
+	if d.Aircraft.Reg != "" {
+		return d.Aircraft.Reg
+	}
+	return d.Aircraft.Call
+}
+
+// getAircraftImage attempts to fetch and cache an aircraft image
+func (c *imageCache) getAircraftImage(registration, aircraftType string) string {
+	if c.logger == nil {
+		return ""
+	}
+
+	// Create cache directory if it doesn't exist
+	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+		c.logger.Debug("Failed to create cache directory", zap.Error(err))
+		return ""
+	}
+
+	// Try to get image by registration first, then by aircraft type
+	imagePath := c.tryGetImageByRegistration(registration)
+	if imagePath == "" {
+		imagePath = c.tryGetImageByType(aircraftType)
+	}
+
+	return imagePath
+}
+
+// tryGetImageByRegistration attempts to fetch an image using the aircraft registration
+func (c *imageCache) tryGetImageByRegistration(registration string) string {
+	if registration == "" {
+		return ""
+	}
+
+	// Clean registration (remove common prefixes/suffixes)
+	cleanReg := strings.TrimSpace(strings.ToUpper(registration))
+	if len(cleanReg) < 3 {
+		return ""
+	}
+
+	cacheFile := filepath.Join(c.cacheDir, fmt.Sprintf("%s.jpg", cleanReg))
+
+	// Check if we already have this image cached
+	if _, err := os.Stat(cacheFile); err == nil {
+		return cacheFile
+	}
+
+	// Try to fetch from JetPhotos API (you'll need an API key)
+	// For now, we'll use a placeholder approach
+	imageURL := c.getAircraftImageURL(cleanReg)
+	if imageURL == "" {
+		return ""
+	}
+
+	if err := c.downloadImage(imageURL, cacheFile); err != nil {
+		c.logger.Debug("Failed to download aircraft image",
+			zap.String("registration", cleanReg),
+			zap.Error(err))
+		return ""
+	}
+
+	return cacheFile
+}
+
+// tryGetImageByType attempts to fetch a generic image for the aircraft type
+func (c *imageCache) tryGetImageByType(aircraftType string) string {
+	if aircraftType == "" {
+		return ""
+	}
+
+	cleanType := strings.TrimSpace(strings.ToUpper(aircraftType))
+	cacheFile := filepath.Join(c.cacheDir, fmt.Sprintf("type_%s.jpg", cleanType))
+
+	// Check if we already have this type cached
+	if _, err := os.Stat(cacheFile); err == nil {
+		return cacheFile
+	}
+
+	// Try to fetch generic aircraft type image
+	imageURL := c.getAircraftTypeImageURL(cleanType)
+	if imageURL == "" {
+		return ""
+	}
+
+	if err := c.downloadImage(imageURL, cacheFile); err != nil {
+		c.logger.Debug("Failed to download aircraft type image",
+			zap.String("type", cleanType),
+			zap.Error(err))
+		return ""
+	}
+
+	return cacheFile
+}
+
+// getAircraftImageURL returns the URL for an aircraft image by registration
+func (c *imageCache) getAircraftImageURL(registration string) string {
+	return c.imageService.GetAircraftImageURL(registration)
+}
+
+// getAircraftTypeImageURL returns the URL for a generic aircraft type image
+func (c *imageCache) getAircraftTypeImageURL(aircraftType string) string {
+	return c.imageService.GetAircraftTypeImageURL(aircraftType)
+}
+
+// downloadImage downloads an image from URL and saves it to the specified path.
+// The image is renamed into place once complete, so concurrent lookups never see a partial file.
+func (c *imageCache) downloadImage(imageURL, filePath string) error {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	resp, err := client.Get(imageURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch image: status %d", resp.StatusCode)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(filePath), ".download-*")
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+	return nil
+}
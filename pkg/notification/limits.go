@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+)
+
+// defaultRatePeriod is used when a rate limit has a count but no period
+const defaultRatePeriod = time.Hour
+
+// tokenBucket allows count notifications per period, at most burst of them back to back
+type tokenBucket struct {
+	capacity float64
+	rate     float64 // Tokens regained per second
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket creates a full bucket, or returns nil when the limit is disabled
+func newTokenBucket(cfg config.RateLimitConfig) *tokenBucket {
+	if cfg.Count <= 0 {
+		return nil
+	}
+	period := cfg.Period
+	if period <= 0 {
+		period = defaultRatePeriod
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = cfg.Count
+	}
+	return &tokenBucket{
+		capacity: float64(burst),
+		rate:     float64(cfg.Count) / period.Seconds(),
+		tokens:   float64(burst),
+	}
+}
+
+// allow takes a token if one is available at now. A nil bucket always allows.
+func (b *tokenBucket) allow(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	if b.last.IsZero() || now.After(b.last) {
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refund returns a token taken by allow when the notification was held back elsewhere
+func (b *tokenBucket) refund() {
+	if b != nil {
+		b.tokens = min(b.capacity, b.tokens+1)
+	}
+}
+
+// remaining returns the tokens left as of the last call to allow
+func (b *tokenBucket) remaining() float64 {
+	return b.tokens
+}
+
+// quietHours is a daily window of local time, which may span midnight
+type quietHours struct {
+	start    time.Duration // Offset from midnight
+	end      time.Duration
+	override Priority // Notifications of at least this priority are still sent, 0 for none
+}
+
+// parseQuietHours parses "HH:MM" start and end times, returning nil when neither is set
+func parseQuietHours(cfg config.QuietHoursConfig) (*quietHours, error) {
+	if cfg.Start == "" && cfg.End == "" {
+		return nil, nil
+	}
+	start, err := parseTimeOfDay(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet_hours.start: %w", err)
+	}
+	end, err := parseTimeOfDay(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet_hours.end: %w", err)
+	}
+	override, err := ParsePriority(cfg.Override)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet_hours.override: %w", err)
+	}
+	return &quietHours{start: start, end: end, override: override}, nil
+}
+
+// parseTimeOfDay converts "HH:MM" to an offset from midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// contains reports whether t falls within the quiet hours. A nil window never does.
+func (q *quietHours) contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if q.start <= q.end {
+		return offset >= q.start && offset < q.end
+	}
+	// The window spans midnight, e.g. 22:00 to 07:00
+	return offset >= q.start || offset < q.end
+}
+
+// holds reports whether a notification of the given priority is silenced at t
+func (q *quietHours) holds(t time.Time, priority Priority) bool {
+	if !q.contains(t) {
+		return false
+	}
+	return q.override == 0 || priority < q.override
+}
@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// MatrixNotifier posts detections to a Matrix room as m.notice messages
+type MatrixNotifier struct {
+	homeserver  string
+	accessToken string
+	roomID      string
+	txnPrefix   string
+	txnCounter  atomic.Uint64
+	client      *http.Client
+	logger      *zap.Logger
+	format      *formatter
+}
+
+// NewMatrixNotifier creates a Matrix notifier for a room
+func NewMatrixNotifier(cfg config.MatrixConfig, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*MatrixNotifier, error) {
+	if cfg.Homeserver == "" || cfg.AccessToken == "" || cfg.RoomID == "" {
+		return nil, fmt.Errorf("matrix: homeserver, access_token and room_id are required")
+	}
+
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("matrix: %w", err)
+	}
+
+	return &MatrixNotifier{
+		homeserver:  strings.TrimSuffix(cfg.Homeserver, "/"),
+		accessToken: cfg.AccessToken,
+		roomID:      cfg.RoomID,
+		// Transaction IDs must be unique per access token, so include the start time
+		txnPrefix: fmt.Sprintf("godar-%d", time.Now().UnixNano()),
+		client:    newHTTPClient(cfg.Timeout),
+		logger:    logger,
+		format:    format,
+	}, nil
+}
+
+// Send posts a notice with a plain text body and an HTML formatted body
+func (m *MatrixNotifier) Send(d *detection.Detection) error {
+	title, err := m.format.Title(d)
+	if err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+	body, err := m.format.Body(d)
+	if err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+
+	var formatted strings.Builder
+	formatted.WriteString("<b>" + html.EscapeString(title) + "</b>")
+	if m.format.CustomBody() {
+		formatted.WriteString("<br>" + strings.ReplaceAll(html.EscapeString(body), "\n", "<br>"))
+	} else {
+		for _, field := range m.format.Fields(d) {
+			formatted.WriteString("<br><b>" + html.EscapeString(field.Name) + ":</b> " + html.EscapeString(field.Value))
+		}
+	}
+
+	payload := map[string]any{
+		"msgtype":        "m.notice",
+		"body":           title + "\n" + body,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted.String(),
+	}
+
+	txnID := fmt.Sprintf("%s-%d", m.txnPrefix, m.txnCounter.Add(1))
+	sendURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.homeserver, url.PathEscape(m.roomID), url.PathEscape(txnID))
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+m.accessToken)
+	if err := sendJSON(m.client, http.MethodPut, sendURL, payload, header); err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+
+	m.logger.Debug("Matrix notification sent",
+		zap.String("callsign", d.Callsign()),
+		zap.String("room", m.roomID))
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package notification
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/templates"
+)
+
+// formatter renders the title and body of a notification.
+// Configured templates replace the built-in text, see config.MessageTemplate.
+type formatter struct {
+	title            *template.Template
+	body             *template.Template
+	viewableDistance float64
+	predictionWindow time.Duration
+}
+
+// newFormatter parses the title and body templates, either of which may be empty
+func newFormatter(tmpl config.MessageTemplate, viewableDistance float64, predictionWindow time.Duration) (*formatter, error) {
+	title, err := templates.Parse("title", tmpl.Title)
+	if err != nil {
+		return nil, fmt.Errorf("invalid title template: %w", err)
+	}
+	body, err := templates.Parse("body", tmpl.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+
+	return &formatter{
+		title:            title,
+		body:             body,
+		viewableDistance: viewableDistance,
+		predictionWindow: predictionWindow,
+	}, nil
+}
+
+// Title returns the notification title for a detection
+func (f *formatter) Title(d *detection.Detection) (string, error) {
+	if len(d.Burst) > 0 {
+		return d.Summary(), nil
+	}
+	if f.title == nil {
+		return formatTitle(d), nil
+	}
+	return templates.Render(f.title, f.message(d))
+}
+
+// Body returns the notification body for a detection
+func (f *formatter) Body(d *detection.Detection) (string, error) {
+	if len(d.Burst) > 0 {
+		return formatFields(burstFields(d)), nil
+	}
+	if f.body == nil {
+		return formatMessage(d, f.viewableDistance, f.predictionWindow), nil
+	}
+	return templates.Render(f.body, f.message(d))
+}
+
+// Fields returns the built-in facts for backends that lay them out as cards
+func (f *formatter) Fields(d *detection.Detection) []messageField {
+	if len(d.Burst) > 0 {
+		return burstFields(d)
+	}
+	return messageFields(d, f.viewableDistance, f.predictionWindow)
+}
+
+// burstFields lists every aircraft of a summary, nearest first.
+// Summaries always use the built-in text as templates describe a single aircraft.
+func burstFields(d *detection.Detection) []messageField {
+	fields := make([]messageField, len(d.Burst))
+	for i, b := range d.Burst {
+		fields[i] = messageField{b.Callsign(), fmt.Sprintf("%.1f km %s, %d ft", b.Distance, b.Direction, b.Aircraft.Alt)}
+	}
+	return fields
+}
+
+// CustomBody reports whether a body template replaces the built-in fields
+func (f *formatter) CustomBody() bool {
+	return f.body != nil
+}
+
+// message wraps a detection for the title and body templates
+func (f *formatter) message(d *detection.Detection) templates.Message {
+	return templates.NewMessage(d, f.viewableDistance, f.predictionWindow)
+}
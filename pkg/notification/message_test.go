@@ -0,0 +1,160 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Message templates", func() {
+	var d *detection.Detection
+
+	BeforeEach(func() {
+		// Keep image lookups in the cache so no request leaves the test
+		home := GinkgoT().TempDir()
+		GinkgoT().Setenv("HOME", home)
+		cacheDir := filepath.Join(home, ".cache", "godar", "images")
+		Expect(os.MkdirAll(cacheDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cacheDir, "G-EUUA.jpg"), []byte("jpeg"), 0644)).To(Succeed())
+
+		d = detection.New(
+			aircraft.Aircraft{Call: "BAW12", Reg: "G-EUUA", Type: "A320", Alt: 12000, Spd: 300, Trak: 180, Lat: 51.1, Long: 0.001},
+			detection.Observer{Latitude: 51.0, Longitude: 0.0},
+		)
+	})
+
+	It("should render the desktop title and body from templates", func() {
+		sender := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, 30*time.Second, zap.NewNop(), sender, 15.0, 30*time.Minute)
+		Expect(notifier.SetMessageTemplate(config.MessageTemplate{
+			Title: `{{.Callsign}} at {{ft .Aircraft.Alt}}`,
+			Body:  `{{pad 6 .Aircraft.Type}}|{{km .Distance}}{{with .Prediction}} closest in {{duration .TimeToClosest}}{{end}}`,
+		})).To(Succeed())
+
+		Expect(notifier.Send(d)).To(Succeed())
+		notifications := sender.GetNotifications()
+		Expect(notifications).To(HaveLen(1))
+		Expect(notifications[0].Title).To(Equal("BAW12 at 12000 ft"))
+		Expect(notifications[0].Message).To(HavePrefix("A320  |11.13 km closest in "))
+	})
+
+	It("should describe a burst summary instead of rendering templates", func() {
+		sender := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, 30*time.Second, zap.NewNop(), sender, 15.0, 30*time.Minute)
+		Expect(notifier.SetMessageTemplate(config.MessageTemplate{Title: `{{upper .Aircraft.Reg}}`})).To(Succeed())
+
+		far := detection.New(aircraft.Aircraft{Call: "EZY1", Alt: 3000, Lat: 51.0, Long: 0.2}, d.Observer)
+		Expect(notifier.Send(detection.Summarise([]*detection.Detection{far, d}))).To(Succeed())
+		notifications := sender.GetNotifications()
+		Expect(notifications).To(HaveLen(1))
+		Expect(notifications[0].Title).To(Equal("2 aircraft in range, nearest BAW12 11.1 km"))
+		Expect(notifications[0].Message).To(Equal("BAW12: 11.1 km N, 12000 ft\nEZY1: 14.0 km E, 3000 ft"))
+	})
+
+	It("should keep the built-in body when only the title is templated", func() {
+		sender := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, 30*time.Second, zap.NewNop(), sender, 15.0, 30*time.Minute)
+		Expect(notifier.SetMessageTemplate(config.MessageTemplate{Title: `{{upper .Aircraft.Reg}}`})).To(Succeed())
+
+		Expect(notifier.Send(d)).To(Succeed())
+		notifications := sender.GetNotifications()
+		Expect(notifications[0].Title).To(Equal("G-EUUA"))
+		Expect(notifications[0].Message).To(ContainSubstring("Altitude: 12000 ft"))
+	})
+
+	It("should show the predicted altitude and bounds of the closest approach", func() {
+		sender := notification.NewMockNotificationSender()
+		notifier := notification.NewNotifierWithSender(true, 30*time.Second, zap.NewNop(), sender, 15.0, 30*time.Minute)
+		d.ClosestApproach.Altitude = 9500
+		d.ClosestApproach.MinDistance, d.ClosestApproach.MaxDistance = 0.2, 1.4
+
+		Expect(notifier.Send(d)).To(Succeed())
+		Expect(sender.GetNotifications()[0].Message).To(MatchRegexp(`Closest: 0\.\d km in \d+ min at 9500 ft \(0\.2–1\.4 km\)`))
+	})
+
+	It("should reject invalid templates", func() {
+		notifier := notification.NewNotifier(true, 30*time.Second, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(notifier.SetMessageTemplate(config.MessageTemplate{Body: `{{.Callsign`})).NotTo(Succeed())
+	})
+
+	It("should let channels override the shared templates", func() {
+		bodies := make(chan []byte, 2)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies <- body
+		}))
+		defer server.Close()
+
+		notifier, err := notification.NewFromConfig(config.NotificationConfig{
+			MessageTemplate: config.MessageTemplate{Title: `shared {{.Callsign}}`, Body: `shared body`},
+			Slack:           []config.SlackConfig{{URL: server.URL, SkipImage: true}},
+			Discord: []config.DiscordConfig{{
+				URL:             server.URL,
+				SkipImage:       true,
+				MessageTemplate: config.MessageTemplate{Body: `{{.Aircraft.Type}} {{nm .Distance}}`},
+			}},
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notifier.Send(d)).To(Succeed())
+
+		var slack, discord map[string]any
+		Expect(json.Unmarshal(<-bodies, &slack)).To(Succeed())
+		Expect(json.Unmarshal(<-bodies, &discord)).To(Succeed())
+
+		Expect(slack).To(HaveKeyWithValue("text", "shared BAW12"))
+		Expect(slack["blocks"].([]any)[1]).To(HaveKeyWithValue("text", HaveKeyWithValue("text", "shared body")))
+
+		embed := discord["embeds"].([]any)[0]
+		Expect(embed).To(HaveKeyWithValue("title", "shared BAW12"))
+		Expect(embed).To(HaveKeyWithValue("description", "A320 6.0 nm"))
+		Expect(embed).NotTo(HaveKey("fields"))
+	})
+
+	It("should render one backend with the templates of each channel it is routed through", func() {
+		bodies := make(chan []byte, 2)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies <- body
+		}))
+		defer server.Close()
+
+		router, err := notification.NewFromConfig(config.NotificationConfig{
+			MessageTemplate: config.MessageTemplate{Title: `shared {{.Callsign}}`},
+			Slack: []config.SlackConfig{{
+				URL:             server.URL,
+				SkipImage:       true,
+				MessageTemplate: config.MessageTemplate{Title: `slack {{.Callsign}}`},
+			}},
+			Channels: []config.ChannelConfig{
+				{Name: "military", Backends: []string{"slack"}, MessageTemplate: config.MessageTemplate{Title: `MIL {{.Callsign}}`}},
+				{Name: "all", Backends: []string{"slack"}},
+			},
+			Rules: []config.RuleConfig{
+				{Military: true, Channels: []string{"military"}},
+				{Channels: []string{"all"}},
+			},
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		d.Aircraft.Mil = true
+		Expect(router.Send(d)).To(Succeed())
+
+		var military, all map[string]any
+		Expect(json.Unmarshal(<-bodies, &military)).To(Succeed())
+		Expect(json.Unmarshal(<-bodies, &all)).To(Succeed())
+		Expect(military).To(HaveKeyWithValue("text", "MIL BAW12"))
+		Expect(all).To(HaveKeyWithValue("text", "slack BAW12"))
+	})
+})
@@ -0,0 +1,201 @@
+package notification
+
+import (
+	"errors"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// Backend is implemented by every notifier that can deliver a detection
+type Backend interface {
+	Send(d *detection.Detection) error
+}
+
+// closer is implemented by backends that hold resources or pending work
+type closer interface {
+	Close()
+}
+
+// MultiNotifier delivers each detection to several backends
+type MultiNotifier struct {
+	backends []Backend
+}
+
+// NewMultiNotifier creates a notifier that fans out to the given backends
+func NewMultiNotifier(backends ...Backend) *MultiNotifier {
+	return &MultiNotifier{backends: backends}
+}
+
+// Add registers another backend
+func (m *MultiNotifier) Add(b Backend) {
+	m.backends = append(m.backends, b)
+}
+
+// Send delivers the detection to every backend, even if some of them fail
+func (m *MultiNotifier) Send(d *detection.Detection) error {
+	var errs []error
+	for _, b := range m.backends {
+		if err := b.Send(d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close releases every backend that needs it, flushing any pending work
+func (m *MultiNotifier) Close() {
+	for _, b := range m.backends {
+		if c, ok := b.(closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// NewFromConfig creates a notifier for every backend enabled in the configuration and routes detections between them.
+// Titles and bodies come from the channel's templates, then the backend's, then notification.title and notification.body.
+func NewFromConfig(cfg config.NotificationConfig, logger *zap.Logger) (*Router, error) {
+	router, err := NewRouter(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := addBackends(router, cfg, logger); err != nil {
+		// Release the backends built before the one that failed
+		router.Close()
+		return nil, err
+	}
+	return router, nil
+}
+
+// addBackends creates every backend enabled in the configuration and adds it to the router
+func addBackends(router *Router, cfg config.NotificationConfig, logger *zap.Logger) error {
+	if cfg.Desktop && cfg.DBus.Enabled {
+		err := addPerChannel(router, BackendDesktop, cfg.DBus.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			dbusCfg := cfg.DBus
+			dbusCfg.MessageTemplate = tmpl
+			return NewDBusNotifier(dbusCfg, cfg.Duration, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	} else if cfg.Desktop {
+		err := addPerChannel(router, BackendDesktop, config.MessageTemplate{}, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			desktop := NewNotifier(cfg.Enabled, cfg.Duration, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+			return desktop, desktop.SetMessageTemplate(tmpl)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, webhookCfg := range cfg.Webhooks {
+		webhook, err := NewWebhookNotifier(webhookCfg, logger)
+		if err != nil {
+			return err
+		}
+		router.Add(BackendWebhooks, webhook)
+	}
+
+	for _, ntfyCfg := range cfg.Ntfy {
+		err := addPerChannel(router, BackendNtfy, ntfyCfg.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			ntfyCfg.MessageTemplate = tmpl
+			return NewNtfyNotifier(ntfyCfg, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, gotifyCfg := range cfg.Gotify {
+		err := addPerChannel(router, BackendGotify, gotifyCfg.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			gotifyCfg.MessageTemplate = tmpl
+			return NewGotifyNotifier(gotifyCfg, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, slackCfg := range cfg.Slack {
+		err := addPerChannel(router, BackendSlack, slackCfg.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			slackCfg.MessageTemplate = tmpl
+			return NewSlackNotifier(slackCfg, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, discordCfg := range cfg.Discord {
+		err := addPerChannel(router, BackendDiscord, discordCfg.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			discordCfg.MessageTemplate = tmpl
+			return NewDiscordNotifier(discordCfg, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, matrixCfg := range cfg.Matrix {
+		err := addPerChannel(router, BackendMatrix, matrixCfg.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			matrixCfg.MessageTemplate = tmpl
+			return NewMatrixNotifier(matrixCfg, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, telegramCfg := range cfg.Telegram {
+		err := addPerChannel(router, BackendTelegram, telegramCfg.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			telegramCfg.MessageTemplate = tmpl
+			return NewTelegramNotifier(telegramCfg, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, emailCfg := range cfg.Email {
+		err := addPerChannel(router, BackendEmail, emailCfg.MessageTemplate, cfg.MessageTemplate, func(tmpl config.MessageTemplate) (Backend, error) {
+			emailCfg.MessageTemplate = tmpl
+			return NewEmailNotifier(emailCfg, logger, cfg.ViewableDistance, cfg.PredictionWindow)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, execCfg := range cfg.Exec {
+		hook, err := NewExecNotifier(execCfg, logger)
+		if err != nil {
+			return err
+		}
+		router.Add(BackendExec, hook)
+	}
+
+	if cfg.Voice.Enabled {
+		voice, err := NewVoiceNotifier(cfg.Voice, logger)
+		if err != nil {
+			return err
+		}
+		router.Add(BackendVoice, voice)
+	}
+
+	return nil
+}
+
+// addPerChannel builds a backend for every channel it is delivered through, so each channel can render its own messages.
+// The channel's templates take precedence over the backend's, which take precedence over the shared ones.
+func addPerChannel(router *Router, backendType string, backend, shared config.MessageTemplate, build func(config.MessageTemplate) (Backend, error)) error {
+	for _, ch := range router.channelsFor(backendType) {
+		b, err := build(ch.template.Or(backend).Or(shared))
+		if err != nil {
+			return err
+		}
+		ch.notifier.Add(b)
+	}
+	return nil
+}
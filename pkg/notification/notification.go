@@ -2,16 +2,13 @@ package notification
 
 import (
 	"fmt"
-	"io"
-	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gen2brain/beeep"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
 	"github.com/lyarwood/godar/pkg/geo"
-	"github.com/lyarwood/godar/pkg/notification/images"
 	"go.uber.org/zap"
 )
 
@@ -30,97 +27,68 @@ func (d *DefaultNotificationSender) Notify(title, message, iconPath string) erro
 
 // Notifier handles sending notifications
 type Notifier struct {
-	enabled          bool
-	duration         time.Duration
-	logger           *zap.Logger
-	cacheDir         string
-	imageService     *images.AircraftImageService
-	sender           NotificationSender
-	viewableDistance float64
-	predictionWindow time.Duration
+	enabled  bool
+	duration time.Duration
+	logger   *zap.Logger
+	images   *imageCache
+	sender   NotificationSender
+	format   *formatter
 }
 
 // NewNotifier creates a new notification handler
 func NewNotifier(enabled bool, duration time.Duration, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) *Notifier {
-	cacheDir := filepath.Join(os.Getenv("HOME"), ".cache", "godar", "images")
 	return &Notifier{
-		enabled:          enabled,
-		duration:         duration,
-		logger:           logger,
-		cacheDir:         cacheDir,
-		imageService:     images.NewAircraftImageService(),
-		sender:           &DefaultNotificationSender{},
-		viewableDistance: viewableDistance,
-		predictionWindow: predictionWindow,
+		enabled:  enabled,
+		duration: duration,
+		logger:   logger,
+		images:   newImageCache(logger),
+		sender:   &DefaultNotificationSender{},
+		format:   &formatter{viewableDistance: viewableDistance, predictionWindow: predictionWindow},
 	}
 }
 
 // NewNotifierWithSender creates a new notification handler with a custom sender (for testing)
 func NewNotifierWithSender(enabled bool, duration time.Duration, logger *zap.Logger, sender NotificationSender, viewableDistance float64, predictionWindow time.Duration) *Notifier {
-	cacheDir := filepath.Join(os.Getenv("HOME"), ".cache", "godar", "images")
 	return &Notifier{
-		enabled:          enabled,
-		duration:         duration,
-		logger:           logger,
-		cacheDir:         cacheDir,
-		imageService:     images.NewAircraftImageService(),
-		sender:           sender,
-		viewableDistance: viewableDistance,
-		predictionWindow: predictionWindow,
+		enabled:  enabled,
+		duration: duration,
+		logger:   logger,
+		images:   newImageCache(logger),
+		sender:   sender,
+		format:   &formatter{viewableDistance: viewableDistance, predictionWindow: predictionWindow},
 	}
 }
 
+// SetMessageTemplate replaces the built-in title and body with templates
+func (n *Notifier) SetMessageTemplate(tmpl config.MessageTemplate) error {
+	format, err := newFormatter(tmpl, n.format.viewableDistance, n.format.predictionWindow)
+	if err != nil {
+		return err
+	}
+	n.format = format
+	return nil
+}
+
 // Send sends a desktop notification for a detected aircraft.
-func (n *Notifier) Send(callsign, aircraftType string, altitude int, speed float64, distance float64, direction string, heading float64, aircraftLat, aircraftLon, observerLat, observerLon, userHeading float64, previousDistance ...float64) error {
+func (n *Notifier) Send(d *detection.Detection) error {
 	if !n.enabled {
 		return nil
 	}
 
-	notificationTitle := fmt.Sprintf("Aircraft Detected: %s", callsign)
-
-	// Calculate bearing to aircraft
-	bearing := geo.CalculateBearing(observerLat, observerLon, aircraftLat, aircraftLon)
-
-	// Calculate clock position based on user's heading
-	clockPosition := geo.BearingToClockPosition(userHeading, bearing)
-
-	// Build notification message - always include clock position
-	directionInfo := fmt.Sprintf("%s (%d o'clock)", direction, clockPosition)
-
-	// Calculate BRAA (Bearing, Range, Altitude, Aspect)
-	rangeNm := geo.KmToNauticalMiles(distance)
-	aspect := geo.CalculateAspect(heading, bearing)
-	braa := fmt.Sprintf("%03.0f/%.0f/%d/%s", bearing, rangeNm, altitude, aspect)
-
-	notificationMessage := fmt.Sprintf("Type: %s\nAltitude: %d ft\nSpeed: %.1f knots\nDistance: %.2f km\nDirection: %s\nBRAA: %s",
-		aircraftType, altitude, speed, distance, directionInfo, braa)
-
-	// Add previous distance information if available
-	if len(previousDistance) > 0 && previousDistance[0] > 0 {
-		distanceChange := previousDistance[0] - distance
-		changeDirection := "closer"
-		if distanceChange < 0 {
-			changeDirection = "farther"
-			distanceChange = -distanceChange
-		}
-		notificationMessage += fmt.Sprintf("\nPrevious: %.2f km (%s by %.2f km)",
-			previousDistance[0], changeDirection, distanceChange)
+	ac := d.Aircraft
+	callsign := d.Callsign()
+	notificationTitle, err := n.format.Title(d)
+	if err != nil {
+		return fmt.Errorf("failed to format notification: %w", err)
 	}
-
-	// Calculate and add closest approach information if aircraft has valid heading and speed
-	if heading > 0 && speed > 1.0 && aircraftLat != 0 && aircraftLon != 0 {
-		approach := geo.CalculateClosestApproach(observerLat, observerLon, aircraftLat, aircraftLon, heading, speed)
-		// Only show prediction if aircraft will approach within viewable distance and prediction window
-		if approach.WillApproach && approach.TimeToClosest > 0 && approach.TimeToClosest < n.predictionWindow && approach.Distance <= n.viewableDistance {
-			timeStr := geo.FormatTimeToClosest(approach.TimeToClosest)
-			notificationMessage += fmt.Sprintf("\nClosest: %.1f km in %s", approach.Distance, timeStr)
-		}
+	notificationMessage, err := n.format.Body(d)
+	if err != nil {
+		return fmt.Errorf("failed to format notification: %w", err)
 	}
 
-	// Try to get aircraft image
-	imagePath := n.getAircraftImage(callsign, aircraftType)
+	imagePath := n.images.forDetection(d)
 
-	err := n.sender.Notify(notificationTitle, notificationMessage, imagePath)
+	err = n.sender.Notify(notificationTitle, notificationMessage, imagePath)
 	if err != nil {
 		n.logger.Error("Failed to send notification",
 			zap.String("callsign", callsign),
@@ -130,135 +98,112 @@ func (n *Notifier) Send(callsign, aircraftType string, altitude int, speed float
 
 	n.logger.Debug("Notification sent",
 		zap.String("callsign", callsign),
-		zap.String("type", aircraftType),
+		zap.String("type", ac.Type),
 		zap.String("image", imagePath))
 
 	return nil
 }
 
-// getAircraftImage attempts to fetch and cache an aircraft image
-func (n *Notifier) getAircraftImage(callsign, aircraftType string) string {
-	if n.logger == nil {
-		return ""
-	}
-
-	// Create cache directory if it doesn't exist
-	if err := os.MkdirAll(n.cacheDir, 0755); err != nil {
-		n.logger.Debug("Failed to create cache directory", zap.Error(err))
-		return ""
+// formatTitle returns the notification title for a detection
+func formatTitle(d *detection.Detection) string {
+	switch d.Alert {
+	case detection.AlertApproaching:
+		return fmt.Sprintf("Aircraft Approaching: %s", d.Callsign())
+	case detection.AlertClosestApproach:
+		return fmt.Sprintf("Closest Approach: %s", d.Callsign())
+	case detection.AlertHeadsUp:
+		return fmt.Sprintf("Heads Up: %s", d.HeadsUp())
+	case detection.AlertTransit:
+		return fmt.Sprintf("Transit: %s", d.Transit())
+	default:
+		return fmt.Sprintf("Aircraft Detected: %s", d.Callsign())
 	}
-
-	// Try to get image by registration first, then by aircraft type
-	imagePath := n.tryGetImageByRegistration(callsign)
-	if imagePath == "" {
-		imagePath = n.tryGetImageByType(aircraftType)
-	}
-
-	return imagePath
 }
 
-// tryGetImageByRegistration attempts to fetch an image using the aircraft registration
-func (n *Notifier) tryGetImageByRegistration(registration string) string {
-	if registration == "" {
-		return ""
-	}
-
-	// Clean registration (remove common prefixes/suffixes)
-	cleanReg := strings.TrimSpace(strings.ToUpper(registration))
-	if len(cleanReg) < 3 {
-		return ""
-	}
-
-	cacheFile := filepath.Join(n.cacheDir, fmt.Sprintf("%s.jpg", cleanReg))
-
-	// Check if we already have this image cached
-	if _, err := os.Stat(cacheFile); err == nil {
-		return cacheFile
-	}
-
-	// Try to fetch from JetPhotos API (you'll need an API key)
-	// For now, we'll use a placeholder approach
-	imageURL := n.getAircraftImageURL(cleanReg)
-	if imageURL == "" {
-		return ""
-	}
-
-	if err := n.downloadImage(imageURL, cacheFile); err != nil {
-		n.logger.Debug("Failed to download aircraft image",
-			zap.String("registration", cleanReg),
-			zap.Error(err))
-		return ""
-	}
+// messageField is one line of the notification body, e.g. "Altitude: 35000 ft"
+type messageField struct {
+	Name  string
+	Value string
+}
 
-	return cacheFile
+// formatMessage returns the notification body shared by every human readable backend, one fact per line
+func formatMessage(d *detection.Detection, viewableDistance float64, predictionWindow time.Duration) string {
+	return formatFields(messageFields(d, viewableDistance, predictionWindow))
 }
 
-// tryGetImageByType attempts to fetch a generic image for the aircraft type
-func (n *Notifier) tryGetImageByType(aircraftType string) string {
-	if aircraftType == "" {
-		return ""
+// formatFields writes each field on its own line as "Name: Value"
+func formatFields(fields []messageField) string {
+	lines := make([]string, len(fields))
+	for i, field := range fields {
+		lines[i] = field.Name + ": " + field.Value
 	}
+	return strings.Join(lines, "\n")
+}
 
-	cleanType := strings.TrimSpace(strings.ToUpper(aircraftType))
-	cacheFile := filepath.Join(n.cacheDir, fmt.Sprintf("type_%s.jpg", cleanType))
+// messageFields returns the facts shown in a notification, for backends that lay them out as cards
+func messageFields(d *detection.Detection, viewableDistance float64, predictionWindow time.Duration) []messageField {
+	ac := d.Aircraft
 
-	// Check if we already have this type cached
-	if _, err := os.Stat(cacheFile); err == nil {
-		return cacheFile
+	var fields []messageField
+	if d.Band != "" {
+		fields = append(fields, messageField{"Entered", d.Band})
 	}
-
-	// Try to fetch generic aircraft type image
-	imageURL := n.getAircraftTypeImageURL(cleanType)
-	if imageURL == "" {
-		return ""
+	if d.Passage != nil {
+		fields = append(fields, messageField{"Closest approach", d.Passage.String()})
 	}
-
-	if err := n.downloadImage(imageURL, cacheFile); err != nil {
-		n.logger.Debug("Failed to download aircraft type image",
-			zap.String("type", cleanType),
-			zap.Error(err))
-		return ""
+	for _, t := range d.Transits {
+		fields = append(fields, messageField{t.Label(), t.String(d.Time)})
 	}
-
-	return cacheFile
-}
-
-// getAircraftImageURL returns the URL for an aircraft image by registration
-func (n *Notifier) getAircraftImageURL(registration string) string {
-	return n.imageService.GetAircraftImageURL(registration)
-}
-
-// getAircraftTypeImageURL returns the URL for a generic aircraft type image
-func (n *Notifier) getAircraftTypeImageURL(aircraftType string) string {
-	return n.imageService.GetAircraftTypeImageURL(aircraftType)
-}
-
-// downloadImage downloads an image from URL and saves it to the specified path
-func (n *Notifier) downloadImage(imageURL, filePath string) error {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	distance := fmt.Sprintf("%.2f km", d.Distance)
+	if d.SlantRange > 0 {
+		distance += fmt.Sprintf(" (%.2f km slant)", d.SlantRange)
 	}
-
-	resp, err := client.Get(imageURL)
-	if err != nil {
-		return fmt.Errorf("failed to fetch image: %w", err)
+	fields = append(fields, []messageField{
+		{"Type", ac.Type},
+		{"Altitude", fmt.Sprintf("%d ft", ac.Alt)},
+		{"Speed", fmt.Sprintf("%.1f knots", ac.Spd)},
+		{"Distance", distance},
+		// Always include clock position
+		{"Direction", fmt.Sprintf("%s (%d o'clock)", d.Direction, d.ClockPosition)},
+	}...)
+	if d.SlantRange > 0 {
+		fields = append(fields, messageField{"Look", d.LookAngle()})
 	}
-	defer resp.Body.Close()
+	if d.Hidden != "" {
+		fields = append(fields, messageField{"Hidden", d.Visibility()})
+	}
+	fields = append(fields, messageField{"BRAA", d.BRAA.String()})
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch image: status %d", resp.StatusCode)
+	if category := d.Category(); category != "" {
+		fields = append(fields, messageField{"Category", category})
 	}
 
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create image file: %w", err)
+	// Add previous distance information if available
+	if d.PreviousDistance > 0 {
+		distanceChange := d.PreviousDistance - d.Distance
+		changeDirection := "closer"
+		if distanceChange < 0 {
+			changeDirection = "farther"
+			distanceChange = -distanceChange
+		}
+		fields = append(fields, messageField{"Previous", fmt.Sprintf("%.2f km (%s by %.2f km)",
+			d.PreviousDistance, changeDirection, distanceChange)})
 	}
-	defer file.Close()
 
-	_, err = io.Copy(file, resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to save image: %w", err)
+	// Only show prediction if aircraft will approach within viewable distance and prediction window
+	if approach := d.ClosestApproach; approach != nil {
+		if approach.WillApproach && approach.TimeToClosest > 0 && approach.TimeToClosest < predictionWindow && approach.Distance <= viewableDistance {
+			timeStr := geo.FormatTimeToClosest(approach.TimeToClosest)
+			closest := fmt.Sprintf("%.1f km in %s", approach.Distance, timeStr)
+			if approach.Altitude > 0 {
+				closest += fmt.Sprintf(" at %d ft", approach.Altitude)
+			}
+			if approach.MaxDistance-approach.MinDistance >= 0.1 {
+				closest += fmt.Sprintf(" (%.1f–%.1f km)", approach.MinDistance, approach.MaxDistance)
+			}
+			fields = append(fields, messageField{"Closest", closest})
+		}
 	}
 
-	return nil
+	return fields
 }
@@ -0,0 +1,47 @@
+package notification_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/detection"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// baw12 is an airliner cruising 56 km north of the test observer
+var baw12 = aircraft.Aircraft{Call: "BAW12", Reg: "G-EUUA", Icao: "400A0B", Type: "A320", Alt: 35000, Spd: 450, Trak: 180, Lat: 51.5, Long: 0.001}
+
+// detect returns a detection of ac by an observer at 51N 0E
+func detect(ac aircraft.Aircraft) *detection.Detection {
+	return detection.New(ac, detection.Observer{Latitude: 51.0, Longitude: 0.0})
+}
+
+// endpoint stands in for the HTTP API of a notification backend, handing each request and its body to the spec
+type endpoint struct {
+	*httptest.Server
+	requests chan *http.Request
+	bodies   chan []byte
+	status   int // Response status, 200 unless the spec sets it before sending
+}
+
+// newEndpoint starts an endpoint that is closed when the spec ends
+func newEndpoint() *endpoint {
+	e := &endpoint{requests: make(chan *http.Request, 1), bodies: make(chan []byte, 1), status: http.StatusOK}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			// Keep uploads readable by the spec
+			Expect(r.ParseMultipartForm(1 << 20)).To(Succeed())
+		}
+		body, _ := io.ReadAll(r.Body)
+		e.requests <- r
+		e.bodies <- body
+		w.WriteHeader(e.status)
+	}))
+	DeferCleanup(e.Close)
+	return e
+}
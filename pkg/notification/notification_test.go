@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/detection"
 	"github.com/lyarwood/godar/pkg/notification"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -29,6 +31,19 @@ var _ = Describe("Notification", func() {
 		mockSender = notification.NewMockNotificationSender()
 	})
 
+	newDetection := func(callsign, aircraftType string, altitude int, speed, heading, aircraftLat, aircraftLon, observerLat, observerLon, userHeading float64) *detection.Detection {
+		return detection.New(
+			aircraft.Aircraft{Call: callsign, Type: aircraftType, Alt: altitude, Spd: speed, Trak: heading, Lat: aircraftLat, Long: aircraftLon},
+			detection.Observer{Latitude: observerLat, Longitude: observerLon, Heading: userHeading},
+		)
+	}
+
+	withDistances := func(d *detection.Detection, distance, previousDistance float64) *detection.Detection {
+		d.Distance = distance
+		d.PreviousDistance = previousDistance
+		return d
+	}
+
 	AfterEach(func() {
 		if logger != nil {
 			_ = logger.Sync()
@@ -64,7 +79,8 @@ var _ = Describe("Notification", func() {
 		Context("when notifications are enabled", func() {
 			It("should send notification with correct content", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "NE", 90.0, 51.5, -0.1, 51.0, 0.0, 0.0)
+				// Aircraft ~25.4 km to the north east of the observer
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 90.0, 51.16, 0.26, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -73,7 +89,7 @@ var _ = Describe("Notification", func() {
 				Expect(notifications[0].Message).To(ContainSubstring("Type: A320"))
 				Expect(notifications[0].Message).To(ContainSubstring("Altitude: 35000 ft"))
 				Expect(notifications[0].Message).To(ContainSubstring("Speed: 450.0 knots"))
-				Expect(notifications[0].Message).To(ContainSubstring("Distance: 25.50 km"))
+				Expect(notifications[0].Message).To(ContainSubstring("Distance: 25.47 km"))
 				Expect(notifications[0].Message).To(ContainSubstring("Direction: NE ("))
 				Expect(notifications[0].Message).To(ContainSubstring("o'clock)"))
 				Expect(notifications[0].Message).To(ContainSubstring("BRAA:"))
@@ -81,7 +97,7 @@ var _ = Describe("Notification", func() {
 
 			It("should handle empty callsign", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("", "A320", 35000, 450, 25.5, "N", 0.0, 51.5, -0.1, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("", "A320", 35000, 450, 0.0, 51.5, -0.1, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -91,7 +107,7 @@ var _ = Describe("Notification", func() {
 
 			It("should handle empty aircraft type", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "", 35000, 450, 25.5, "S", 180.0, 51.5, -0.1, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "", 35000, 450, 180.0, 51.5, -0.1, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -101,7 +117,7 @@ var _ = Describe("Notification", func() {
 
 			It("should handle zero values", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 0, 0, 0.0, "W", 270.0, 51.5, -0.1, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 0, 0, 270.0, 51.0, 0.0, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -113,7 +129,7 @@ var _ = Describe("Notification", func() {
 
 			It("should include previous distance when aircraft is getting closer", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 35000, 450, 20.0, "NE", 45.0, 51.5, -0.1, 51.0, 0.0, 0.0, 25.5)
+				err := notifier.Send(withDistances(newDetection("TEST123", "A320", 35000, 450, 45.0, 51.5, -0.1, 51.0, 0.0, 0.0), 20.0, 25.5))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -123,7 +139,7 @@ var _ = Describe("Notification", func() {
 
 			It("should include previous distance when aircraft is moving away", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 35000, 450, 30.0, "SW", 225.0, 51.5, -0.1, 51.0, 0.0, 0.0, 25.5)
+				err := notifier.Send(withDistances(newDetection("TEST123", "A320", 35000, 450, 225.0, 51.5, -0.1, 51.0, 0.0, 0.0), 30.0, 25.5))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -133,7 +149,7 @@ var _ = Describe("Notification", func() {
 
 			It("should handle zero previous distance", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "E", 90.0, 51.5, -0.1, 51.0, 0.0, 0.0, 0.0)
+				err := notifier.Send(withDistances(newDetection("TEST123", "A320", 35000, 450, 90.0, 51.5, -0.1, 51.0, 0.0, 0.0), 25.5, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -142,10 +158,23 @@ var _ = Describe("Notification", func() {
 				Expect(notifications[0].Message).NotTo(ContainSubstring("Previous:"))
 			})
 
+			It("should include the aircraft category when known", func() {
+				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
+				d := newDetection("HELI1", "EC35", 1500, 120, 90.0, 51.1, 0.0, 51.0, 0.0, 0.0)
+				d.Aircraft.Species = aircraft.SpeciesHelicopter
+				d.Aircraft.WTC = aircraft.WTCLight
+				err := notifier.Send(d)
+				Expect(err).To(BeNil())
+
+				notifications := mockSender.GetNotifications()
+				Expect(notifications).To(HaveLen(1))
+				Expect(notifications[0].Message).To(ContainSubstring("Category: Light Helicopter"))
+			})
+
 			It("should handle notification sender error", func() {
 				mockSender.SetShouldError(true, "test error")
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "N", 0.0, 51.5, -0.1, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 0.0, 51.5, -0.1, 51.0, 0.0, 0.0))
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("mock error: test error"))
 			})
@@ -154,14 +183,14 @@ var _ = Describe("Notification", func() {
 		Context("when notifications are disabled", func() {
 			It("should not send notification and return nil", func() {
 				notifier := notification.NewNotifierWithSender(false, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "SE", 135.0, 51.5, -0.1, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 135.0, 51.5, -0.1, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 				Expect(mockSender.GetNotificationCount()).To(Equal(0))
 			})
 
 			It("should not send notification with previous distance and return nil", func() {
 				notifier := notification.NewNotifierWithSender(false, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "NW", 315.0, 51.5, -0.1, 51.0, 0.0, 0.0, 30.0)
+				err := notifier.Send(withDistances(newDetection("TEST123", "A320", 35000, 450, 315.0, 51.5, -0.1, 51.0, 0.0, 0.0), 25.5, 30.0))
 				Expect(err).To(BeNil())
 				Expect(mockSender.GetNotificationCount()).To(Equal(0))
 			})
@@ -171,7 +200,7 @@ var _ = Describe("Notification", func() {
 			It("should include clock position for heading 0 (North) - aircraft to the east", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
 				// Aircraft to the east: observer at (51.0, 0.0), aircraft at (51.0, 0.5) = bearing ~90 degrees
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "E", 90.0, 51.0, 0.5, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 90.0, 51.0, 0.5, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -182,7 +211,7 @@ var _ = Describe("Notification", func() {
 			It("should include clock position for heading 90 (East) - aircraft to the north", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
 				// Aircraft to the north: observer at (51.0, 0.0), aircraft at (51.5, 0.0) = bearing ~0 degrees
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "N", 0.0, 51.5, 0.0, 51.0, 0.0, 90.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 0.0, 51.5, 0.0, 51.0, 0.0, 90.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -193,7 +222,7 @@ var _ = Describe("Notification", func() {
 			It("should include clock position for heading 180 (South) - aircraft to the east", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
 				// Aircraft to the east: observer at (51.0, 0.0), aircraft at (51.0, 0.5) = bearing ~90 degrees
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "E", 90.0, 51.0, 0.5, 51.0, 0.0, 180.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 90.0, 51.0, 0.5, 51.0, 0.0, 180.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -207,7 +236,7 @@ var _ = Describe("Notification", func() {
 			It("should include BRAA line with Hot aspect when aircraft heads toward observer", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
 				// Aircraft at (51.5, 0.0) heading south (180), observer at (51.0, 0.0) — bearing ~0, so heading toward observer
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "N", 180.0, 51.5, 0.0, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 180.0, 51.5, 0.0, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -219,7 +248,7 @@ var _ = Describe("Notification", func() {
 			It("should include BRAA line with Cold aspect when aircraft heads away from observer", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
 				// Aircraft at (51.5, 0.0) heading north (0), observer at (51.0, 0.0) — bearing ~0, heading same direction = Cold
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "N", 0.0, 51.5, 0.0, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 0.0, 51.5, 0.0, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -230,7 +259,7 @@ var _ = Describe("Notification", func() {
 			It("should include BRAA line with Flanking aspect for lateral movement", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
 				// Aircraft at (51.5, 0.0) heading east (90), observer at (51.0, 0.0) — bearing ~0, perpendicular = Flanking
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "N", 90.0, 51.5, 0.0, 51.0, 0.0, 0.0)
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 90.0, 51.5, 0.0, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
@@ -240,20 +269,20 @@ var _ = Describe("Notification", func() {
 
 			It("should include range in nautical miles in BRAA", func() {
 				notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-				// 25.5 km ≈ 13.77 nm
-				err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "N", 180.0, 51.5, 0.0, 51.0, 0.0, 0.0)
+				// 55.6 km ≈ 30.02 nm
+				err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 180.0, 51.5, 0.0, 51.0, 0.0, 0.0))
 				Expect(err).To(BeNil())
 
 				notifications := mockSender.GetNotifications()
 				Expect(notifications).To(HaveLen(1))
-				Expect(notifications[0].Message).To(ContainSubstring("/14/35000/"))
+				Expect(notifications[0].Message).To(ContainSubstring("/30/35000/"))
 			})
 		})
 
 	Describe("Send method", func() {
 		It("should be an alias for SendAircraftNotification", func() {
 			notifier := notification.NewNotifierWithSender(true, 30*time.Second, logger, mockSender, 15.0, 30*time.Minute)
-			err := notifier.Send("TEST123", "A320", 35000, 450, 25.5, "NE", 45.0, 51.5, -0.1, 51.0, 0.0, 0.0)
+			err := notifier.Send(newDetection("TEST123", "A320", 35000, 450, 45.0, 51.5, -0.1, 51.0, 0.0, 0.0))
 			Expect(err).To(BeNil())
 			Expect(mockSender.GetNotificationCount()).To(Equal(1))
 		})
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/templates"
+	"go.uber.org/zap"
+)
+
+// NtfyNotifier publishes detections to an ntfy topic, attaching the aircraft image when one is available
+type NtfyNotifier struct {
+	url       *url.URL
+	token     string
+	username  string
+	password  string
+	priority  int
+	tags      []string
+	click     *template.Template
+	skipImage bool
+	images    *imageCache
+	client    *http.Client
+	logger    *zap.Logger
+	format    *formatter
+}
+
+// NewNtfyNotifier creates an ntfy notifier for a topic URL
+func NewNtfyNotifier(cfg config.NtfyConfig, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*NtfyNotifier, error) {
+	topicURL, err := url.Parse(cfg.URL)
+	if err != nil || topicURL.Scheme == "" || topicURL.Host == "" {
+		return nil, fmt.Errorf("ntfy: invalid topic url %q", cfg.URL)
+	}
+
+	click, err := templates.Parse("ntfy-click", cfg.Click)
+	if err != nil {
+		return nil, fmt.Errorf("ntfy: invalid click template: %w", err)
+	}
+
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("ntfy: %w", err)
+	}
+
+	return &NtfyNotifier{
+		url:       topicURL,
+		token:     cfg.Token,
+		username:  cfg.Username,
+		password:  cfg.Password,
+		priority:  cfg.Priority,
+		tags:      cfg.Tags,
+		click:     click,
+		skipImage: cfg.SkipImage,
+		images:    newImageCache(logger),
+		client:    newHTTPClient(cfg.Timeout),
+		logger:    logger,
+		format:    format,
+	}, nil
+}
+
+// Send publishes a detection to the topic.
+// With an image the file is uploaded as an attachment and the text is sent as the message parameter.
+func (n *NtfyNotifier) Send(d *detection.Detection) error {
+	click, err := templates.Render(n.click, d)
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+
+	title, err := n.format.Title(d)
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	message, err := n.format.Body(d)
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+
+	query := n.url.Query()
+	query.Set("title", title)
+	if n.priority > 0 {
+		query.Set("priority", strconv.Itoa(n.priority))
+	}
+	if len(n.tags) > 0 {
+		query.Set("tags", strings.Join(n.tags, ","))
+	}
+	if click != "" {
+		query.Set("click", click)
+	}
+
+	method := http.MethodPost
+	var body io.Reader = strings.NewReader(message)
+	var filename string
+
+	if !n.skipImage {
+		if imagePath := n.images.forDetection(d); imagePath != "" {
+			image, err := os.Open(imagePath)
+			if err != nil {
+				n.logger.Debug("Failed to open aircraft image", zap.String("image", imagePath), zap.Error(err))
+			} else {
+				defer image.Close()
+				method = http.MethodPut
+				body = image
+				filename = filepath.Base(imagePath)
+				query.Set("message", message)
+			}
+		}
+	}
+
+	requestURL := *n.url
+	requestURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(method, requestURL.String(), body)
+	if err != nil {
+		return fmt.Errorf("ntfy: failed to create request: %w", err)
+	}
+	if filename != "" {
+		req.Header.Set("Filename", filename)
+	}
+	switch {
+	case n.token != "":
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	case n.username != "":
+		req.SetBasicAuth(n.username, n.password)
+	}
+
+	if err := doRequest(n.client, req); err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+
+	n.logger.Debug("ntfy notification sent",
+		zap.String("callsign", d.Callsign()),
+		zap.String("topic", n.url.Path),
+		zap.String("attachment", filename))
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package notification_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("NtfyNotifier", func() {
+	var (
+		server *endpoint
+		d      *detection.Detection
+	)
+
+	BeforeEach(func() {
+		server = newEndpoint()
+		d = detect(baw12)
+	})
+
+	It("should publish the notification text with priority, tags and click URL", func() {
+		ntfy, err := notification.NewNtfyNotifier(config.NtfyConfig{
+			URL:       server.URL + "/godar",
+			Token:     "tk_secret",
+			Priority:  4,
+			Tags:      []string{"airplane", "eyes"},
+			Click:     "https://globe.adsbexchange.com/?icao={{.Aircraft.Icao}}",
+			SkipImage: true,
+		}, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ntfy.Send(d)).To(Succeed())
+
+		req := <-server.requests
+		Expect(req.Method).To(Equal(http.MethodPost))
+		Expect(req.URL.Path).To(Equal("/godar"))
+		Expect(req.Header.Get("Authorization")).To(Equal("Bearer tk_secret"))
+		Expect(req.URL.Query().Get("title")).To(Equal("Aircraft Detected: BAW12"))
+		Expect(req.URL.Query().Get("priority")).To(Equal("4"))
+		Expect(req.URL.Query().Get("tags")).To(Equal("airplane,eyes"))
+		Expect(req.URL.Query().Get("click")).To(Equal("https://globe.adsbexchange.com/?icao=400A0B"))
+		Expect(string(<-server.bodies)).To(ContainSubstring("Type: A320\nAltitude: 35000 ft"))
+	})
+
+	It("should attach the cached aircraft image", func() {
+		home := GinkgoT().TempDir()
+		GinkgoT().Setenv("HOME", home)
+		cacheDir := filepath.Join(home, ".cache", "godar", "images")
+		Expect(os.MkdirAll(cacheDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cacheDir, "G-EUUA.jpg"), []byte("jpeg"), 0644)).To(Succeed())
+
+		ntfy, err := notification.NewNtfyNotifier(config.NtfyConfig{URL: server.URL + "/godar", Username: "user", Password: "pass"}, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ntfy.Send(d)).To(Succeed())
+
+		req := <-server.requests
+		Expect(req.Method).To(Equal(http.MethodPut))
+		Expect(req.Header.Get("Filename")).To(Equal("G-EUUA.jpg"))
+		Expect(req.URL.Query().Get("message")).To(ContainSubstring("Distance:"))
+		username, password, ok := req.BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("user"))
+		Expect(password).To(Equal("pass"))
+		Expect(string(<-server.bodies)).To(Equal("jpeg"))
+	})
+
+	It("should return an error including the server response", func() {
+		server.status = http.StatusForbidden
+		ntfy, err := notification.NewNtfyNotifier(config.NtfyConfig{URL: server.URL + "/godar", SkipImage: true}, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ntfy.Send(d)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("ntfy: unexpected status 403"))
+	})
+
+	It("should reject an invalid topic URL", func() {
+		_, err := notification.NewNtfyNotifier(config.NtfyConfig{URL: "godar"}, zap.NewNop(), 15.0, 30*time.Minute)
+		Expect(err).To(HaveOccurred())
+	})
+})
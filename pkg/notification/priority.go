@@ -0,0 +1,60 @@
+package notification
+
+import (
+	"fmt"
+
+	"github.com/lyarwood/godar/pkg/detection"
+)
+
+// Priority ranks how important a notification is, from PriorityLow to PriorityUrgent
+type Priority int
+
+// Priorities in increasing order of importance
+const (
+	PriorityLow Priority = iota + 1
+	PriorityNormal
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = map[Priority]string{
+	PriorityLow:    "low",
+	PriorityNormal: "normal",
+	PriorityHigh:   "high",
+	PriorityUrgent: "urgent",
+}
+
+// String returns the configuration name of the priority
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+// ParsePriority parses a priority name, returning 0 for an empty string
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return 0, nil
+	}
+	for p, name := range priorityNames {
+		if s == name {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid priority %q, must be low, normal, high or urgent", s)
+}
+
+// DetectionPriority rates emergencies as urgent, transits and military aircraft as high and aircraft moving away as low
+func DetectionPriority(d *detection.Detection) Priority {
+	switch {
+	case d.Emergency():
+		return PriorityUrgent
+	case d.Alert == detection.AlertTransit, d.Aircraft.Mil:
+		return PriorityHigh
+	case d.PreviousDistance > 0 && d.Distance > d.PreviousDistance:
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
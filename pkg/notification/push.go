@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxErrorBody limits how much of an error response is included in the returned error
+const maxErrorBody = 256
+
+// newHTTPClient returns a client with the configured timeout, or defaultHTTPTimeout when unset
+func newHTTPClient(timeout time.Duration) *http.Client {
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// doRequest sends a request and fails on any non-2xx response, including the start of its body in the error
+func doRequest(client *http.Client, req *http.Request) error {
+	req.Header.Set("User-Agent", "godar")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Drop the URL from the error as some APIs carry credentials in the path
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// sendJSON encodes a payload as JSON and sends it with doRequest
+func sendJSON(client *http.Client, method, url string, payload any, header http.Header) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return doRequest(client, req)
+}
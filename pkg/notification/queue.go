@@ -0,0 +1,176 @@
+package notification
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// DeliveryMetrics counts what the delivery queue has done since it started
+type DeliveryMetrics struct {
+	Queued    uint64        // Notifications handed to the queue
+	Delivered uint64        // Sent by every backend of their channel
+	Failed    uint64        // At least one backend returned an error
+	Dropped   uint64        // Discarded to make room while the queue was full, or handed over after it closed
+	Pending   int           // Waiting for a worker
+	Latency   time.Duration // Average time from being queued to being sent
+}
+
+// delivery is a detection waiting to be sent by the backends of a channel
+type delivery struct {
+	backend Backend
+	channel string
+	d       *detection.Detection
+	queued  time.Time
+}
+
+// deliveryQueue sends notifications on a pool of workers so slow backends do not hold up polling.
+// Each aircraft is always handled by the same worker, so its notifications arrive in order.
+type deliveryQueue struct {
+	shards []*shard
+	size   int
+	logger *zap.Logger
+	wg     sync.WaitGroup
+
+	queued, delivered, failed, dropped atomic.Uint64
+	latency                            atomic.Int64 // Total nanoseconds spent queued
+}
+
+// shard is the bounded backlog of a single worker
+type shard struct {
+	mu     sync.Mutex
+	ready  *sync.Cond
+	jobs   []delivery
+	closed bool
+}
+
+// newDeliveryQueue starts the workers, or returns nil to deliver on the caller's goroutine when none are configured
+func newDeliveryQueue(cfg config.QueueConfig, logger *zap.Logger) *deliveryQueue {
+	if cfg.Workers <= 0 {
+		return nil
+	}
+	q := &deliveryQueue{
+		shards: make([]*shard, cfg.Workers),
+		size:   max(cfg.Size, 1),
+		logger: logger,
+	}
+	for i := range q.shards {
+		s := &shard{}
+		s.ready = sync.NewCond(&s.mu)
+		q.shards[i] = s
+		q.wg.Add(1)
+		go q.work(s)
+	}
+	return q
+}
+
+// enqueue hands a notification to the worker of its aircraft, dropping that worker's oldest one if it is full.
+// Once the queue is closed the worker may have exited, so the notification is dropped instead.
+func (q *deliveryQueue) enqueue(job delivery) {
+	h := fnv.New32a()
+	h.Write([]byte(aircraftKey(job.d)))
+	s := q.shards[h.Sum32()%uint32(len(q.shards))]
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		q.dropped.Add(1)
+		q.logger.Warn("Notification queue closed, dropping notification",
+			zap.String("channel", job.channel),
+			zap.String("callsign", job.d.Callsign()))
+		return
+	}
+	if len(s.jobs) >= q.size {
+		oldest := s.jobs[0]
+		s.jobs = s.jobs[1:]
+		q.dropped.Add(1)
+		q.logger.Warn("Notification queue full, dropping oldest",
+			zap.String("channel", oldest.channel),
+			zap.String("callsign", oldest.d.Callsign()),
+			zap.Duration("queued_for", time.Since(oldest.queued)))
+	}
+	s.jobs = append(s.jobs, job)
+	s.mu.Unlock()
+
+	q.queued.Add(1)
+	s.ready.Signal()
+}
+
+// work sends a shard's notifications one at a time until it is closed and empty
+func (q *deliveryQueue) work(s *shard) {
+	defer q.wg.Done()
+	for {
+		s.mu.Lock()
+		for len(s.jobs) == 0 && !s.closed {
+			s.ready.Wait()
+		}
+		if len(s.jobs) == 0 {
+			s.mu.Unlock()
+			return
+		}
+		job := s.jobs[0]
+		s.jobs = s.jobs[1:]
+		s.mu.Unlock()
+
+		q.latency.Add(int64(time.Since(job.queued)))
+		if err := job.backend.Send(job.d); err != nil {
+			q.failed.Add(1)
+			q.logger.Error("Failed to send notification",
+				zap.String("channel", job.channel),
+				zap.String("callsign", job.d.Callsign()),
+				zap.Error(err))
+			continue
+		}
+		q.delivered.Add(1)
+	}
+}
+
+// close stops accepting work and waits for every queued notification to be sent
+func (q *deliveryQueue) close() {
+	for _, s := range q.shards {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		s.ready.Broadcast()
+	}
+	q.wg.Wait()
+}
+
+// metrics returns the delivery counters, which are zero when notifications are sent without a queue
+func (q *deliveryQueue) metrics() DeliveryMetrics {
+	if q == nil {
+		return DeliveryMetrics{}
+	}
+	m := DeliveryMetrics{
+		Queued:    q.queued.Load(),
+		Delivered: q.delivered.Load(),
+		Failed:    q.failed.Load(),
+		Dropped:   q.dropped.Load(),
+	}
+	for _, s := range q.shards {
+		s.mu.Lock()
+		m.Pending += len(s.jobs)
+		s.mu.Unlock()
+	}
+	if sent := m.Delivered + m.Failed; sent > 0 {
+		m.Latency = time.Duration(q.latency.Load() / int64(sent))
+	}
+	return m
+}
+
+// fields returns the metrics for logging
+func (m DeliveryMetrics) fields() []zap.Field {
+	return []zap.Field{
+		zap.Uint64("queued", m.Queued),
+		zap.Uint64("delivered", m.Delivered),
+		zap.Uint64("failed", m.Failed),
+		zap.Uint64("dropped", m.Dropped),
+		zap.Int("pending", m.Pending),
+		zap.Duration("latency", m.Latency),
+	}
+}
@@ -0,0 +1,405 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// Backend types that channels refer to, matching their keys in the notification configuration
+const (
+	BackendDesktop  = "desktop"
+	BackendWebhooks = "webhooks"
+	BackendNtfy     = "ntfy"
+	BackendGotify   = "gotify"
+	BackendSlack    = "slack"
+	BackendDiscord  = "discord"
+	BackendMatrix   = "matrix"
+	BackendTelegram = "telegram"
+	BackendEmail    = "email"
+	BackendExec     = "exec"
+	BackendVoice    = "voice"
+	BackendMQTT     = "mqtt"
+)
+
+// backendTypes lists every backend type a channel may contain
+var backendTypes = []string{
+	BackendDesktop, BackendWebhooks, BackendNtfy, BackendGotify, BackendSlack, BackendDiscord,
+	BackendMatrix, BackendTelegram, BackendEmail, BackendExec, BackendVoice, BackendMQTT,
+}
+
+// DefaultChannel holds the backends that no channel lists
+const DefaultChannel = "default"
+
+// Router delivers each detection to the channels selected by the routing rules.
+// Without rules every detection is delivered to every channel.
+type Router struct {
+	channels []*channel
+	rules    []*rule
+	bands    map[string]*band // Keyed by label
+	governor *governor
+	queue    *deliveryQueue // nil when delivering on the caller's goroutine
+	logger   *zap.Logger
+
+	mu      sync.Mutex
+	pending []*detection.Detection // Detections of the current poll waiting for Flush
+}
+
+// channel is a named group of backends sharing a minimum priority, quiet hours, rate limit and message templates
+type channel struct {
+	name        string
+	types       []string
+	notifier    *MultiNotifier
+	minPriority Priority
+	quietHours  *quietHours
+	template    config.MessageTemplate // Overrides the templates of its backends
+
+	// Guarded by the governor
+	limiter   *tokenBucket
+	sent      int
+	collapsed int
+	held      map[string]int // Notifications held back by reason
+}
+
+// band overrides the priority and channels of detections entering a distance band
+type band struct {
+	priority Priority
+	channels []*channel // Replace the rules when set
+}
+
+// rule routes the detections it matches to its channels.
+// Every criterion that is set must match.
+type rule struct {
+	name        string
+	military    bool
+	emergency   bool
+	watchlist   []string // Upper case glob patterns
+	minDistance float64
+	maxDistance float64
+	priority    Priority // Raises the priority of matching detections
+	channels    []*channel
+}
+
+// NewRouter creates the channels and rules from the configuration.
+// Backends are added afterwards with Add.
+func NewRouter(cfg config.NotificationConfig, logger *zap.Logger) (*Router, error) {
+	gov, err := newGovernor(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	r := &Router{governor: gov, bands: map[string]*band{}, queue: newDeliveryQueue(cfg.Queue, logger), logger: logger}
+
+	for _, channelCfg := range cfg.Channels {
+		ch, err := newChannel(channelCfg)
+		if err != nil {
+			return nil, fmt.Errorf("channel %q: %w", channelCfg.Name, err)
+		}
+		if r.channel(ch.name) != nil {
+			return nil, fmt.Errorf("channel %q is defined more than once", ch.name)
+		}
+		for _, backendType := range ch.types {
+			if !slices.Contains(backendTypes, backendType) {
+				return nil, fmt.Errorf("channel %q: unknown backend %q", ch.name, backendType)
+			}
+		}
+		r.channels = append(r.channels, ch)
+	}
+	if r.channel(DefaultChannel) == nil {
+		r.channels = append(r.channels, &channel{name: DefaultChannel, notifier: NewMultiNotifier()})
+	}
+
+	for _, bandCfg := range cfg.Bands {
+		b, err := r.newBand(bandCfg)
+		if err != nil {
+			return nil, fmt.Errorf("band %q: %w", bandCfg.Label(), err)
+		}
+		r.bands[bandCfg.Label()] = b
+	}
+
+	for i, ruleCfg := range cfg.Rules {
+		rl, err := r.newRule(ruleCfg)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i, ruleCfg.Name, err)
+		}
+		r.rules = append(r.rules, rl)
+	}
+
+	return r, nil
+}
+
+// newChannel creates a channel without any backends
+func newChannel(cfg config.ChannelConfig) (*channel, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	minPriority, err := ParsePriority(cfg.MinPriority)
+	if err != nil {
+		return nil, err
+	}
+	quiet, err := parseQuietHours(cfg.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+	return &channel{
+		name:        cfg.Name,
+		types:       cfg.Backends,
+		notifier:    NewMultiNotifier(),
+		minPriority: minPriority,
+		quietHours:  quiet,
+		template:    cfg.MessageTemplate,
+		limiter:     newTokenBucket(cfg.RateLimit),
+	}, nil
+}
+
+// newRule resolves a rule's channels and parses its criteria
+func (r *Router) newRule(cfg config.RuleConfig) (*rule, error) {
+	priority, err := ParsePriority(cfg.Priority)
+	if err != nil {
+		return nil, err
+	}
+
+	rl := &rule{
+		name:        cfg.Name,
+		military:    cfg.Military,
+		emergency:   cfg.Emergency,
+		minDistance: cfg.MinDistance,
+		maxDistance: cfg.MaxDistance,
+		priority:    priority,
+	}
+	for _, pattern := range cfg.Watchlist {
+		pattern = strings.ToUpper(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid watchlist pattern %q", pattern)
+		}
+		rl.watchlist = append(rl.watchlist, pattern)
+	}
+	if len(cfg.Channels) == 0 {
+		return nil, fmt.Errorf("at least one channel is required")
+	}
+	for _, name := range cfg.Channels {
+		ch := r.channel(name)
+		if ch == nil {
+			return nil, fmt.Errorf("unknown channel %q", name)
+		}
+		rl.channels = append(rl.channels, ch)
+	}
+	return rl, nil
+}
+
+// newBand resolves a band's channels
+func (r *Router) newBand(cfg config.BandConfig) (*band, error) {
+	priority, err := ParsePriority(cfg.Priority)
+	if err != nil {
+		return nil, err
+	}
+	b := &band{priority: priority}
+	for _, name := range cfg.Channels {
+		ch := r.channel(name)
+		if ch == nil {
+			return nil, fmt.Errorf("unknown channel %q", name)
+		}
+		b.channels = append(b.channels, ch)
+	}
+	return b, nil
+}
+
+// channel returns the channel with the given name, or nil
+func (r *Router) channel(name string) *channel {
+	for _, ch := range r.channels {
+		if ch.name == name {
+			return ch
+		}
+	}
+	return nil
+}
+
+// Add registers a backend of the given type with every channel listing it, or with the default channel
+func (r *Router) Add(backendType string, b Backend) {
+	for _, ch := range r.channelsFor(backendType) {
+		ch.notifier.Add(b)
+	}
+}
+
+// channelsFor returns the channels listing a backend type, or the default channel when none does
+func (r *Router) channelsFor(backendType string) []*channel {
+	var listing []*channel
+	for _, ch := range r.channels {
+		if slices.Contains(ch.types, backendType) {
+			listing = append(listing, ch)
+		}
+	}
+	if len(listing) == 0 {
+		return []*channel{r.channel(DefaultChannel)}
+	}
+	return listing
+}
+
+// Send delivers a detection to every channel selected for it, even if some of them fail.
+// When bursts are collapsed the detection is held until Flush so it can be summarised with the rest of its poll,
+// except for heads-up alerts, which are raised between polls and would arrive late.
+func (r *Router) Send(d *detection.Detection) error {
+	if r.governor.burst > 0 && d.Alert != detection.AlertHeadsUp {
+		r.mu.Lock()
+		r.pending = append(r.pending, d)
+		r.mu.Unlock()
+		return nil
+	}
+	return r.dispatch([]*detection.Detection{d})
+}
+
+// Flush delivers the detections held back since the last poll and logs the governor state.
+// It is called once every poll has been processed.
+func (r *Router) Flush() error {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	err := r.dispatch(batch)
+	r.governor.logState(r.channels, time.Now())
+	if r.queue != nil {
+		r.logger.Debug("Notification queue state", r.queue.metrics().fields()...)
+	}
+	return err
+}
+
+// Metrics returns the delivery queue counters, which stay zero when no workers are configured
+func (r *Router) Metrics() DeliveryMetrics {
+	return r.queue.metrics()
+}
+
+// Close delivers any held or queued detections and releases the backends of every channel
+func (r *Router) Close() {
+	if err := r.Flush(); err != nil {
+		r.logger.Error("Failed to send held notifications", zap.Error(err))
+	}
+	if r.queue != nil {
+		r.queue.close()
+		r.logger.Info("Notification queue drained", r.queue.metrics().fields()...)
+	}
+	for _, ch := range r.channels {
+		ch.notifier.Close()
+	}
+}
+
+// route returns the channels a detection goes to and its priority on each.
+// A band the detection has entered may raise its priority and send it to the band's channels instead.
+func (r *Router) route(d *detection.Detection) map[*channel]Priority {
+	base := DetectionPriority(d)
+	targets := map[*channel]Priority{}
+
+	if b, ok := r.bands[d.Band]; ok {
+		base = max(base, b.priority)
+		if len(b.channels) > 0 {
+			for _, ch := range b.channels {
+				targets[ch] = base
+			}
+			return targets
+		}
+	}
+
+	if len(r.rules) == 0 {
+		for _, ch := range r.channels {
+			targets[ch] = base
+		}
+		return targets
+	}
+
+	for _, rl := range r.rules {
+		if !rl.matches(d) {
+			continue
+		}
+		priority := max(base, rl.priority)
+		for _, ch := range rl.channels {
+			targets[ch] = max(targets[ch], priority)
+		}
+	}
+	return targets
+}
+
+// dispatch routes a batch of detections and delivers each channel's share of it
+func (r *Router) dispatch(batch []*detection.Detection) error {
+	queued := map[*channel][]routed{}
+	for _, d := range batch {
+		targets := r.route(d)
+		if len(targets) == 0 {
+			r.logger.Debug("No routing rule matched", zap.String("callsign", d.Callsign()))
+			continue
+		}
+		for ch, priority := range targets {
+			queued[ch] = append(queued[ch], routed{d: d, priority: priority})
+		}
+	}
+
+	var errs []error
+	for _, ch := range r.channels {
+		if items, ok := queued[ch]; ok {
+			if err := r.deliver(ch, items); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver sends whatever the governor admits from a channel's share of a batch, or queues it for the workers
+func (r *Router) deliver(ch *channel, items []routed) error {
+	if len(ch.notifier.backends) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, item := range r.governor.admit(ch, items) {
+		if r.queue != nil {
+			r.queue.enqueue(delivery{backend: ch.notifier, channel: ch.name, d: item.d, queued: time.Now()})
+			continue
+		}
+		if err := ch.notifier.Send(item.d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// matches reports whether a detection meets every criterion of the rule
+func (rl *rule) matches(d *detection.Detection) bool {
+	if rl.military && !d.Aircraft.Mil {
+		return false
+	}
+	if rl.emergency && !d.Emergency() {
+		return false
+	}
+	if len(rl.watchlist) > 0 && !rl.watched(d) {
+		return false
+	}
+	if d.Distance < rl.minDistance {
+		return false
+	}
+	if rl.maxDistance > 0 && d.Distance > rl.maxDistance {
+		return false
+	}
+	return true
+}
+
+// watched reports whether the aircraft's ICAO address, callsign or registration is on the watchlist
+func (rl *rule) watched(d *detection.Detection) bool {
+	for _, pattern := range rl.watchlist {
+		for _, value := range []string{d.Aircraft.Icao, d.Aircraft.Call, d.Aircraft.Reg} {
+			if value == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, strings.ToUpper(value)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
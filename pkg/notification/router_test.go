@@ -0,0 +1,359 @@
+package notification_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+// recorder is a backend remembering the detections it was sent
+type recorder struct {
+	mu         sync.Mutex
+	detections []*detection.Detection
+}
+
+func (r *recorder) Send(d *detection.Detection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detections = append(r.detections, d)
+	return nil
+}
+
+// sent returns the callsigns of every detection received
+func (r *recorder) sent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	callsigns := make([]string, len(r.detections))
+	for i, d := range r.detections {
+		callsigns[i] = d.Callsign()
+	}
+	return callsigns
+}
+
+// gate is a backend that blocks every send until it is released
+type gate struct {
+	recorder
+	started chan string
+	release chan struct{}
+}
+
+func (g *gate) Send(d *detection.Detection) error {
+	g.started <- d.Callsign()
+	<-g.release
+	return g.recorder.Send(d)
+}
+
+// failing is a backend that always returns an error
+type failing struct{}
+
+func (failing) Send(*detection.Detection) error {
+	return errors.New("unreachable")
+}
+
+var _ = Describe("Router", func() {
+	var (
+		desktop, phone, hook *recorder
+		noon                 time.Time
+	)
+
+	newRouter := func(cfg config.NotificationConfig) *notification.Router {
+		router, err := notification.NewRouter(cfg, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		router.Add(notification.BackendDesktop, desktop)
+		router.Add(notification.BackendNtfy, phone)
+		router.Add(notification.BackendExec, hook)
+		return router
+	}
+
+	newDetection := func(ac aircraft.Aircraft, distance float64) *detection.Detection {
+		d := detection.New(ac, detection.Observer{Latitude: 51.0, Longitude: 0.0})
+		d.Distance = distance
+		d.Time = noon
+		return d
+	}
+
+	BeforeEach(func() {
+		desktop, phone, hook = &recorder{}, &recorder{}, &recorder{}
+		noon = time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	})
+
+	It("should deliver to every backend without rules", func() {
+		router := newRouter(config.NotificationConfig{})
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12"}, 10))).To(Succeed())
+		Expect(desktop.sent()).To(Equal([]string{"BAW12"}))
+		Expect(phone.sent()).To(Equal([]string{"BAW12"}))
+		Expect(hook.sent()).To(Equal([]string{"BAW12"}))
+	})
+
+	It("should route detections by rule", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels: []config.ChannelConfig{
+				{Name: "phone", Backends: []string{"ntfy"}},
+				{Name: "screen", Backends: []string{"desktop"}},
+			},
+			Rules: []config.RuleConfig{
+				{Name: "military", Military: true, Channels: []string{"phone", "screen"}},
+				{Name: "watchlist", Watchlist: []string{"g-euu*"}, Channels: []string{"phone"}},
+				{Name: "close", MaxDistance: 5, Channels: []string{"screen"}},
+				{Name: "emergency", Emergency: true, Channels: []string{"default"}},
+			},
+		})
+
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RRR7", Mil: true}, 40))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12", Reg: "G-EUUA"}, 40))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "EZY1"}, 3))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RYR1"}, 40))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "N123", Sqk: 7700}, 40))).To(Succeed())
+
+		Expect(phone.sent()).To(Equal([]string{"RRR7", "BAW12"}))
+		Expect(desktop.sent()).To(Equal([]string{"RRR7", "EZY1"}))
+		Expect(hook.sent()).To(Equal([]string{"N123"}))
+	})
+
+	It("should deliver through every channel listing a backend", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels: []config.ChannelConfig{
+				{Name: "military", Backends: []string{"ntfy"}},
+				{Name: "all", Backends: []string{"ntfy", "desktop"}},
+			},
+			Rules: []config.RuleConfig{
+				{Military: true, Channels: []string{"military"}},
+				{MaxDistance: 5, Channels: []string{"all"}},
+			},
+		})
+
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RRR7", Mil: true}, 40))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "EZY1"}, 3))).To(Succeed())
+
+		// The recorder was added to both channels
+		Expect(phone.sent()).To(Equal([]string{"RRR7", "EZY1"}))
+		Expect(desktop.sent()).To(Equal([]string{"EZY1"}))
+		Expect(hook.sent()).To(BeEmpty())
+	})
+
+	It("should route detections entering a band to its channels", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels: []config.ChannelConfig{{Name: "phone", Backends: []string{"ntfy"}, MinPriority: "high"}},
+			Rules:    []config.RuleConfig{{Channels: []string{"default"}}},
+			Bands: []config.BandConfig{
+				{Distance: 20},
+				{Distance: 1, Name: "overhead", Priority: "high", Channels: []string{"phone"}},
+			},
+		})
+
+		far := newDetection(aircraft.Aircraft{Call: "BAW12"}, 15)
+		far.Band = "20 km"
+		near := newDetection(aircraft.Aircraft{Call: "BAW12"}, 0.8)
+		near.Band = "overhead"
+
+		Expect(router.Send(far)).To(Succeed())
+		Expect(router.Send(near)).To(Succeed())
+		Expect(desktop.sent()).To(HaveLen(1))
+		Expect(phone.sent()).To(HaveLen(1))
+	})
+
+	It("should hold back detections below the channel's minimum priority", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels: []config.ChannelConfig{{Name: "phone", Backends: []string{"ntfy"}, MinPriority: "high"}},
+			Rules: []config.RuleConfig{
+				{Channels: []string{"phone"}},
+				{Name: "watchlist", Watchlist: []string{"BAW12"}, Priority: "high", Channels: []string{"phone"}},
+			},
+		})
+
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "EZY1"}, 10))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RRR7", Mil: true}, 10))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12"}, 10))).To(Succeed())
+		Expect(phone.sent()).To(Equal([]string{"RRR7", "BAW12"}))
+	})
+
+	It("should stay silent during quiet hours", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels: []config.ChannelConfig{{Name: "phone", Backends: []string{"ntfy"}, QuietHours: config.QuietHoursConfig{Start: "22:00", End: "07:00"}}},
+		})
+
+		night := newDetection(aircraft.Aircraft{Call: "BAW12"}, 10)
+		night.Time = time.Date(2025, 6, 1, 23, 30, 0, 0, time.Local)
+		morning := newDetection(aircraft.Aircraft{Call: "EZY1"}, 10)
+		morning.Time = time.Date(2025, 6, 2, 6, 59, 0, 0, time.Local)
+
+		Expect(router.Send(night)).To(Succeed())
+		Expect(router.Send(morning)).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RYR1"}, 10))).To(Succeed())
+		Expect(phone.sent()).To(Equal([]string{"RYR1"}))
+		Expect(desktop.sent()).To(HaveLen(3))
+	})
+
+	It("should rate limit each channel", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels: []config.ChannelConfig{{Name: "phone", Backends: []string{"ntfy"}, RateLimit: config.RateLimitConfig{Count: 2, Period: time.Minute}}},
+		})
+
+		for _, offset := range []time.Duration{0, time.Second, 2 * time.Second, 31 * time.Second} {
+			d := newDetection(aircraft.Aircraft{Call: "BAW12"}, 10)
+			d.Time = noon.Add(offset)
+			Expect(router.Send(d)).To(Succeed())
+		}
+		// Two are sent at once, the third is dropped and a token is regained every 30 seconds
+		Expect(phone.sent()).To(HaveLen(3))
+		Expect(desktop.sent()).To(HaveLen(4))
+	})
+
+	It("should rate limit every channel together", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels:  []config.ChannelConfig{{Name: "phone", Backends: []string{"ntfy"}, RateLimit: config.RateLimitConfig{Count: 1, Period: time.Hour}}},
+			RateLimit: config.RateLimitConfig{Count: 3, Period: time.Hour},
+		})
+
+		for _, callsign := range []string{"BAW12", "EZY1", "RYR1"} {
+			Expect(router.Send(newDetection(aircraft.Aircraft{Call: callsign}, 10))).To(Succeed())
+		}
+		// The phone's own limit holding EZY1 back leaves its global token to the default channel
+		Expect(phone.sent()).To(Equal([]string{"BAW12"}))
+		Expect(desktop.sent()).To(Equal([]string{"BAW12", "EZY1"}))
+		Expect(hook.sent()).To(Equal([]string{"BAW12", "EZY1"}))
+	})
+
+	It("should let priorities at or above the override through quiet hours", func() {
+		router := newRouter(config.NotificationConfig{
+			QuietHours: config.QuietHoursConfig{Start: "09:00", End: "17:00", Override: "high"},
+		})
+
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12"}, 10))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RRR7", Mil: true}, 10))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "N123", Sqk: 7700}, 10))).To(Succeed())
+		Expect(desktop.sent()).To(Equal([]string{"RRR7", "N123"}))
+	})
+
+	It("should collapse a burst into a summary when flushed", func() {
+		router := newRouter(config.NotificationConfig{
+			Channels: []config.ChannelConfig{{Name: "phone", Backends: []string{"ntfy"}}},
+			Rules: []config.RuleConfig{
+				{Channels: []string{"default"}},
+				{MaxDistance: 5, Channels: []string{"phone"}},
+			},
+			Burst: config.BurstConfig{Threshold: 3},
+		})
+
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "EZY1"}, 4))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12"}, 3))).To(Succeed())
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RYR1"}, 8))).To(Succeed())
+		Expect(desktop.sent()).To(BeEmpty())
+
+		Expect(router.Flush()).To(Succeed())
+		Expect(desktop.sent()).To(Equal([]string{"BAW12"}))
+		Expect(desktop.detections[0].Summary()).To(Equal("3 aircraft in range, nearest BAW12 3.0 km"))
+		Expect(hook.sent()).To(Equal([]string{"BAW12"}))
+		// Only two aircraft were routed to the phone, below the threshold
+		Expect(phone.sent()).To(Equal([]string{"EZY1", "BAW12"}))
+		Expect(phone.detections[0].Burst).To(BeEmpty())
+
+		Expect(router.Flush()).To(Succeed())
+		Expect(desktop.sent()).To(HaveLen(1))
+	})
+
+	It("should not hold heads-up alerts back for the next burst", func() {
+		router := newRouter(config.NotificationConfig{Burst: config.BurstConfig{Threshold: 3}})
+
+		Expect(router.Send(newDetection(aircraft.Aircraft{Call: "EZY1"}, 4))).To(Succeed())
+		headsUp := newDetection(aircraft.Aircraft{Call: "BAW12"}, 12)
+		headsUp.Alert = detection.AlertHeadsUp
+		Expect(router.Send(headsUp)).To(Succeed())
+		Expect(desktop.sent()).To(Equal([]string{"BAW12"}))
+
+		Expect(router.Flush()).To(Succeed())
+		Expect(desktop.sent()).To(Equal([]string{"BAW12", "EZY1"}))
+	})
+
+	Describe("delivery queue", func() {
+		It("should deliver each aircraft's notifications in order and drain on close", func() {
+			router, err := notification.NewRouter(config.NotificationConfig{Queue: config.QueueConfig{Workers: 3, Size: 50}}, zap.NewNop())
+			Expect(err).NotTo(HaveOccurred())
+			router.Add(notification.BackendDesktop, desktop)
+
+			for i := range 10 {
+				for _, icao := range []string{"400001", "400002", "400003", "400004"} {
+					Expect(router.Send(newDetection(aircraft.Aircraft{Icao: icao, Call: fmt.Sprintf("%s-%d", icao, i)}, 10))).To(Succeed())
+				}
+			}
+			router.Close()
+
+			Expect(desktop.sent()).To(HaveLen(40))
+			next := map[string]int{}
+			for _, callsign := range desktop.sent() {
+				icao, seq, _ := strings.Cut(callsign, "-")
+				Expect(seq).To(Equal(strconv.Itoa(next[icao])))
+				next[icao]++
+			}
+
+			metrics := router.Metrics()
+			Expect(metrics.Queued).To(BeEquivalentTo(40))
+			Expect(metrics.Delivered).To(BeEquivalentTo(40))
+			Expect(metrics.Pending).To(BeZero())
+		})
+
+		It("should drop the oldest waiting notification when full", func() {
+			router, err := notification.NewRouter(config.NotificationConfig{Queue: config.QueueConfig{Workers: 1, Size: 1}}, zap.NewNop())
+			Expect(err).NotTo(HaveOccurred())
+			slow := &gate{started: make(chan string, 3), release: make(chan struct{})}
+			router.Add(notification.BackendDesktop, slow)
+
+			Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12"}, 10))).To(Succeed())
+			Eventually(slow.started).Should(Receive(Equal("BAW12")))
+			// The send returns straight away while the worker is busy
+			Expect(router.Send(newDetection(aircraft.Aircraft{Call: "EZY1"}, 10))).To(Succeed())
+			Expect(router.Send(newDetection(aircraft.Aircraft{Call: "RYR1"}, 10))).To(Succeed())
+			Expect(router.Metrics().Pending).To(Equal(1))
+
+			close(slow.release)
+			router.Close()
+			Expect(slow.sent()).To(Equal([]string{"BAW12", "RYR1"}))
+			Expect(router.Metrics().Dropped).To(BeEquivalentTo(1))
+		})
+
+		It("should drop notifications sent after it closed", func() {
+			router, err := notification.NewRouter(config.NotificationConfig{Queue: config.QueueConfig{Workers: 2, Size: 10}}, zap.NewNop())
+			Expect(err).NotTo(HaveOccurred())
+			router.Add(notification.BackendDesktop, desktop)
+			router.Close()
+
+			Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12"}, 10))).To(Succeed())
+			Expect(desktop.sent()).To(BeEmpty())
+			Expect(router.Metrics().Queued).To(BeZero())
+			Expect(router.Metrics().Dropped).To(BeEquivalentTo(1))
+			Expect(router.Metrics().Pending).To(BeZero())
+		})
+
+		It("should count failed deliveries", func() {
+			router, err := notification.NewRouter(config.NotificationConfig{Queue: config.QueueConfig{Workers: 1, Size: 10}}, zap.NewNop())
+			Expect(err).NotTo(HaveOccurred())
+			router.Add(notification.BackendDesktop, failing{})
+
+			Expect(router.Send(newDetection(aircraft.Aircraft{Call: "BAW12"}, 10))).To(Succeed())
+			router.Close()
+			Expect(router.Metrics().Failed).To(BeEquivalentTo(1))
+			Expect(router.Metrics().Delivered).To(BeZero())
+		})
+	})
+
+	DescribeTable("should reject invalid routing",
+		func(cfg config.NotificationConfig, message string) {
+			_, err := notification.NewRouter(cfg, zap.NewNop())
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("unknown backend", config.NotificationConfig{Channels: []config.ChannelConfig{{Name: "a", Backends: []string{"pager"}}}}, `unknown backend "pager"`),
+		Entry("unknown channel", config.NotificationConfig{Rules: []config.RuleConfig{{Channels: []string{"pager"}}}}, `unknown channel "pager"`),
+	)
+})
@@ -0,0 +1,101 @@
+package notification
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// maxSlackFields is the most fields Block Kit accepts in one section
+const maxSlackFields = 10
+
+// SlackNotifier posts detections to a Slack incoming webhook as Block Kit messages
+type SlackNotifier struct {
+	url       string
+	skipImage bool
+	images    *imageCache
+	client    *http.Client
+	logger    *zap.Logger
+	format    *formatter
+}
+
+// NewSlackNotifier creates a Slack notifier for an incoming webhook URL
+func NewSlackNotifier(cfg config.SlackConfig, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*SlackNotifier, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("slack: url is required")
+	}
+
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("slack: %w", err)
+	}
+
+	return &SlackNotifier{
+		url:       cfg.URL,
+		skipImage: cfg.SkipImage,
+		images:    newImageCache(logger),
+		client:    newHTTPClient(cfg.Timeout),
+		logger:    logger,
+		format:    format,
+	}, nil
+}
+
+// Send posts a header block and sections listing the detection, with the aircraft image alongside the first
+func (s *SlackNotifier) Send(d *detection.Detection) error {
+	title, err := s.format.Title(d)
+	if err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+
+	var sections []map[string]any
+	if s.format.CustomBody() {
+		body, err := s.format.Body(d)
+		if err != nil {
+			return fmt.Errorf("slack: %w", err)
+		}
+		sections = append(sections, map[string]any{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": body}})
+	} else {
+		var fields []map[string]any
+		for _, field := range s.format.Fields(d) {
+			fields = append(fields, map[string]any{
+				"type": "mrkdwn",
+				"text": fmt.Sprintf("*%s*\n%s", field.Name, field.Value),
+			})
+		}
+		for chunk := range slices.Chunk(fields, maxSlackFields) {
+			sections = append(sections, map[string]any{"type": "section", "fields": chunk})
+		}
+	}
+	if !s.skipImage && len(sections) > 0 {
+		if imageURL := s.images.urlForDetection(d); imageURL != "" {
+			sections[0]["accessory"] = map[string]any{
+				"type":      "image",
+				"image_url": imageURL,
+				"alt_text":  d.Aircraft.Type,
+			}
+		}
+	}
+
+	blocks := []map[string]any{{
+		"type": "header",
+		"text": map[string]any{"type": "plain_text", "text": title},
+	}}
+	payload := map[string]any{
+		// Shown in notifications and clients that cannot render blocks
+		"text":   title,
+		"blocks": append(blocks, sections...),
+	}
+
+	if err := sendJSON(s.client, http.MethodPost, s.url, payload, nil); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+
+	s.logger.Debug("Slack notification sent", zap.String("callsign", d.Callsign()))
+
+	return nil
+}
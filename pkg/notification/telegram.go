@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"go.uber.org/zap"
+)
+
+// defaultTelegramAPIURL is the public Bot API endpoint
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramNotifier sends detections through a Telegram bot, as a photo with a caption when an image is available
+type TelegramNotifier struct {
+	apiURL    string
+	chatID    string
+	skipImage bool
+	images    *imageCache
+	client    *http.Client
+	logger    *zap.Logger
+	format    *formatter
+}
+
+// NewTelegramNotifier creates a Telegram notifier for a bot token and chat
+func NewTelegramNotifier(cfg config.TelegramConfig, logger *zap.Logger, viewableDistance float64, predictionWindow time.Duration) (*TelegramNotifier, error) {
+	if cfg.Token == "" || cfg.ChatID == "" {
+		return nil, fmt.Errorf("telegram: token and chat_id are required")
+	}
+
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultTelegramAPIURL
+	}
+
+	format, err := newFormatter(cfg.MessageTemplate, viewableDistance, predictionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("telegram: %w", err)
+	}
+
+	return &TelegramNotifier{
+		apiURL:    strings.TrimSuffix(apiURL, "/") + "/bot" + cfg.Token,
+		chatID:    cfg.ChatID,
+		skipImage: cfg.SkipImage,
+		images:    newImageCache(logger),
+		client:    newHTTPClient(cfg.Timeout),
+		logger:    logger,
+		format:    format,
+	}, nil
+}
+
+// Send uploads the cached aircraft image with sendPhoto, falling back to sendMessage without one
+func (t *TelegramNotifier) Send(d *detection.Detection) error {
+	text, err := t.caption(d)
+	if err != nil {
+		return fmt.Errorf("telegram: %w", err)
+	}
+
+	var imagePath string
+	if !t.skipImage {
+		imagePath = t.images.forDetection(d)
+	}
+
+	if imagePath != "" {
+		err = t.sendPhoto(imagePath, text)
+	} else {
+		err = sendJSON(t.client, http.MethodPost, t.apiURL+"/sendMessage", map[string]any{
+			"chat_id":    t.chatID,
+			"text":       text,
+			"parse_mode": "HTML",
+		}, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("telegram: %w", err)
+	}
+
+	t.logger.Debug("Telegram notification sent",
+		zap.String("callsign", d.Callsign()),
+		zap.String("image", imagePath))
+
+	return nil
+}
+
+// caption formats the detection as Telegram HTML
+func (t *TelegramNotifier) caption(d *detection.Detection) (string, error) {
+	title, err := t.format.Title(d)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("<b>" + html.EscapeString(title) + "</b>")
+	if t.format.CustomBody() {
+		body, err := t.format.Body(d)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("\n" + html.EscapeString(body))
+	} else {
+		for _, field := range t.format.Fields(d) {
+			b.WriteString("\n<b>" + html.EscapeString(field.Name) + ":</b> " + html.EscapeString(field.Value))
+		}
+	}
+	return b.String(), nil
+}
+
+// sendPhoto uploads an image file with a caption
+func (t *TelegramNotifier) sendPhoto(imagePath, caption string) error {
+	image, err := os.Open(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to open image: %w", err)
+	}
+	defer image.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	_ = form.WriteField("chat_id", t.chatID)
+	_ = form.WriteField("caption", caption)
+	_ = form.WriteField("parse_mode", "HTML")
+	part, err := form.CreateFormFile("photo", filepath.Base(imagePath))
+	if err != nil {
+		return fmt.Errorf("failed to create form: %w", err)
+	}
+	if _, err := io.Copy(part, image); err != nil {
+		return fmt.Errorf("failed to read image: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("failed to create form: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.apiURL+"/sendPhoto", &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	return doRequest(t.client, req)
+}
@@ -0,0 +1,158 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/phraseology"
+	"github.com/lyarwood/godar/pkg/templates"
+	"go.uber.org/zap"
+)
+
+// DefaultVoiceTemplate renders callouts like "Traffic, two o'clock, eight miles, descending through four thousand, hot"
+const DefaultVoiceTemplate = `Traffic, {{clock .ClockPosition}}, {{miles .BRAA.RangeNm}}, {{vertical .Aircraft.Vsi .Aircraft.Alt}}, {{lower .BRAA.Aspect}}`
+
+// voiceTextPlaceholder is replaced by the callout in the command arguments
+const voiceTextPlaceholder = "{text}"
+
+// defaultVoiceQueueSize is used when voice.queue_size is not set
+const defaultVoiceQueueSize = 10
+
+// VoiceNotifier speaks a callout for each detection through a text-to-speech command.
+// Callouts are queued and spoken one at a time so they never overlap.
+type VoiceNotifier struct {
+	command  string
+	args     []string
+	template *template.Template
+	timeout  time.Duration
+	queue    chan string
+	done     chan struct{}
+	once     sync.Once
+	logger   *zap.Logger
+}
+
+// NewVoiceNotifier creates a voice notifier and starts the goroutine speaking its callouts
+func NewVoiceNotifier(cfg config.VoiceConfig, logger *zap.Logger) (*VoiceNotifier, error) {
+	command := cfg.Command
+	if command == "" {
+		command = "espeak"
+	}
+
+	transitionAltitude := cfg.TransitionAltitude
+	if transitionAltitude == 0 {
+		transitionAltitude = phraseology.DefaultTransitionAltitude
+	}
+
+	text := cfg.Template
+	if text == "" {
+		text = DefaultVoiceTemplate
+	}
+	tmpl, err := templates.Parse("voice", text, templates.VoiceFuncs(transitionAltitude))
+	if err != nil {
+		return nil, fmt.Errorf("voice: invalid template: %w", err)
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultVoiceQueueSize
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultExecTimeout
+	}
+
+	v := &VoiceNotifier{
+		command:  command,
+		args:     cfg.Args,
+		template: tmpl,
+		timeout:  timeout,
+		queue:    make(chan string, queueSize),
+		done:     make(chan struct{}),
+		logger:   logger,
+	}
+	go v.speakLoop()
+
+	return v, nil
+}
+
+// Send renders the callout and queues it, dropping it if the queue is full
+func (v *VoiceNotifier) Send(d *detection.Detection) error {
+	text, err := v.Callout(d)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case v.queue <- text:
+		return nil
+	default:
+		return fmt.Errorf("voice: queue full, dropped callout for %s", d.Callsign())
+	}
+}
+
+// Callout renders the phrase spoken for a detection
+func (v *VoiceNotifier) Callout(d *detection.Detection) (string, error) {
+	text, err := templates.Render(v.template, d)
+	if err != nil {
+		return "", fmt.Errorf("voice: %w", err)
+	}
+	return text, nil
+}
+
+// Close speaks any queued callouts and stops the voice goroutine
+func (v *VoiceNotifier) Close() {
+	v.once.Do(func() { close(v.queue) })
+	<-v.done
+}
+
+// speakLoop speaks queued callouts one after another
+func (v *VoiceNotifier) speakLoop() {
+	defer close(v.done)
+	for text := range v.queue {
+		v.speak(text)
+	}
+}
+
+// speak runs the TTS command for a callout, passing it as an argument or on stdin
+func (v *VoiceNotifier) speak(text string) {
+	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	defer cancel()
+
+	args := make([]string, len(v.args))
+	viaArgs := false
+	for i, arg := range v.args {
+		if strings.Contains(arg, voiceTextPlaceholder) {
+			viaArgs = true
+		}
+		args[i] = strings.ReplaceAll(arg, voiceTextPlaceholder, text)
+	}
+
+	cmd := exec.CommandContext(ctx, v.command, args...)
+	if !viaArgs {
+		cmd.Stdin = strings.NewReader(text)
+	}
+	cmd.WaitDelay = time.Second
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		v.logger.Warn("Voice callout failed",
+			zap.String("command", v.command),
+			zap.String("text", text),
+			zap.Int("exit_code", cmd.ProcessState.ExitCode()),
+			zap.String("output", truncate(output.String(), maxExecOutput)),
+			zap.Error(err))
+		return
+	}
+
+	v.logger.Debug("Voice callout spoken", zap.String("text", text))
+}
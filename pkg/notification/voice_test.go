@@ -0,0 +1,101 @@
+package notification_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("VoiceNotifier", func() {
+	var (
+		dir string
+		d   *detection.Detection
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		// About 8 nm east of the observer, heading west towards them and descending
+		d = detection.New(
+			aircraft.Aircraft{Call: "BAW12", Type: "A320", Alt: 4000, Vsi: -1200, Spd: 250, Trak: 270, Lat: 51.0, Long: 0.214},
+			detection.Observer{Latitude: 51.0, Longitude: 0.0, Heading: 30},
+		)
+	})
+
+	It("should render the default callout in aviation phraseology", func() {
+		voice, err := notification.NewVoiceNotifier(config.VoiceConfig{Command: "true"}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		defer voice.Close()
+
+		Expect(voice.Callout(d)).To(Equal("Traffic, two o'clock, eight miles, descending through four thousand, hot"))
+	})
+
+	It("should render a custom template", func() {
+		voice, err := notification.NewVoiceNotifier(config.VoiceConfig{
+			Command:  "true",
+			Template: "{{.Callsign}}, bearing {{heading .Bearing}}, {{altitude 35000}}",
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		defer voice.Close()
+
+		Expect(voice.Callout(d)).To(Equal("BAW12, bearing zero niner zero, flight level three five zero"))
+	})
+
+	It("should pass the callout on stdin and speak callouts one at a time", func() {
+		log := filepath.Join(dir, "spoken")
+		voice, err := notification.NewVoiceNotifier(config.VoiceConfig{
+			Command:  "/bin/sh",
+			Args:     []string{"-c", `echo start >> "$0"; cat >> "$0"; echo >> "$0"; sleep 0.1; echo end >> "$0"`, log},
+			Template: "{{.Callsign}}",
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(voice.Send(d)).To(Succeed())
+		Expect(voice.Send(d)).To(Succeed())
+		voice.Close()
+
+		spoken, err := os.ReadFile(log)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(spoken)).To(Equal("start\nBAW12\nend\nstart\nBAW12\nend\n"))
+	})
+
+	It("should substitute the callout into the arguments", func() {
+		log := filepath.Join(dir, "spoken")
+		voice, err := notification.NewVoiceNotifier(config.VoiceConfig{
+			Command:  "/bin/sh",
+			Args:     []string{"-c", `echo "$1" > "$0"`, log, "{text}"},
+			Template: "{{clock .ClockPosition}}",
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(voice.Send(d)).To(Succeed())
+		voice.Close()
+
+		Expect(os.ReadFile(log)).To(BeEquivalentTo("two o'clock\n"))
+	})
+
+	It("should drop callouts when the queue is full", func() {
+		voice, err := notification.NewVoiceNotifier(config.VoiceConfig{
+			Command:   "/bin/sleep",
+			Args:      []string{"0.2"},
+			QueueSize: 1,
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		defer voice.Close()
+
+		var dropped error
+		for range 3 {
+			if err := voice.Send(d); err != nil {
+				dropped = err
+			}
+		}
+		Expect(dropped).To(MatchError(ContainSubstring("queue full")))
+	})
+})
@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/templates"
+	"go.uber.org/zap"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body when a secret is configured
+const SignatureHeader = "X-Godar-Signature"
+
+// defaultHTTPTimeout is used when an HTTP backend has no timeout configured
+const defaultHTTPTimeout = 10 * time.Second
+
+// WebhookNotifier POSTs a JSON payload describing each detection to an HTTP endpoint
+type WebhookNotifier struct {
+	name     string
+	url      string
+	headers  map[string]string
+	secret   []byte
+	template *template.Template
+	client   *http.Client
+	logger   *zap.Logger
+}
+
+// NewWebhookNotifier creates a webhook notifier, parsing its payload template.
+// Without a template the detection is sent as JSON.
+func NewWebhookNotifier(cfg config.WebhookConfig, logger *zap.Logger) (*WebhookNotifier, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook %q: url is required", cfg.Name)
+	}
+
+	w := &WebhookNotifier{
+		name:    cfg.Name,
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  newHTTPClient(cfg.Timeout),
+		logger:  logger,
+	}
+	if w.name == "" {
+		// Name it after the host alone, as the path and query often carry a token
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: invalid url: %w", errors.Unwrap(err))
+		}
+		w.name = u.Host
+	}
+	if cfg.Secret != "" {
+		w.secret = []byte(cfg.Secret)
+	}
+
+	tmpl, err := templates.Parse(w.name, cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %q: invalid template: %w", w.name, err)
+	}
+	w.template = tmpl
+
+	return w, nil
+}
+
+// Send renders the payload for a detection and POSTs it to the webhook
+func (w *WebhookNotifier) Send(d *detection.Detection) error {
+	body, err := w.render(d)
+	if err != nil {
+		return fmt.Errorf("webhook %q: %w", w.name, err)
+	}
+
+	// The body is sent as rendered so the signature covers exactly these bytes
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook %q: failed to create request: %w", w.name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range w.headers {
+		req.Header.Set(name, value)
+	}
+	if w.secret != nil {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(w.secret, body))
+	}
+
+	if err := doRequest(w.client, req); err != nil {
+		return fmt.Errorf("webhook %q: %w", w.name, err)
+	}
+
+	w.logger.Debug("Webhook delivered",
+		zap.String("webhook", w.name),
+		zap.String("callsign", d.Callsign()))
+
+	return nil
+}
+
+// render produces the JSON body for a detection
+func (w *WebhookNotifier) render(d *detection.Detection) ([]byte, error) {
+	if w.template == nil {
+		return json.Marshal(d)
+	}
+
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, d); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template did not render valid JSON: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body using secret
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
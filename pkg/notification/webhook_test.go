@@ -0,0 +1,141 @@
+package notification_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/config"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/notification"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("WebhookNotifier", func() {
+	var (
+		server *endpoint
+		d      *detection.Detection
+	)
+
+	BeforeEach(func() {
+		server = newEndpoint()
+		d = detect(baw12)
+	})
+
+	It("should POST the detection as JSON without a template", func() {
+		webhook, err := notification.NewWebhookNotifier(config.WebhookConfig{URL: server.URL}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(webhook.Send(d)).To(Succeed())
+
+		req := <-server.requests
+		Expect(req.Method).To(Equal(http.MethodPost))
+		Expect(req.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		var payload map[string]any
+		Expect(json.Unmarshal(<-server.bodies, &payload)).To(Succeed())
+		Expect(payload).To(HaveKey("braa"))
+		Expect(payload["aircraft"]).To(HaveKeyWithValue("Call", "BAW12"))
+	})
+
+	It("should render the payload template", func() {
+		webhook, err := notification.NewWebhookNotifier(config.WebhookConfig{
+			URL:      server.URL,
+			Template: `{"text": {{json (printf "%s %s at %.0f km, %d o'clock" .Callsign .Aircraft.Type .Distance .ClockPosition)}}, "braa": {{json .BRAA.String}}, "eta": {{json (duration .ClosestApproach.TimeToClosest)}}}`,
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(webhook.Send(d)).To(Succeed())
+
+		var payload map[string]string
+		Expect(json.Unmarshal(<-server.bodies, &payload)).To(Succeed())
+		Expect(payload["text"]).To(Equal("BAW12 A320 at 56 km, 12 o'clock"))
+		Expect(payload["braa"]).To(Equal("000/30/35000/Hot"))
+		Expect(payload["eta"]).To(Equal("4 min"))
+	})
+
+	It("should send custom headers and sign the body", func() {
+		webhook, err := notification.NewWebhookNotifier(config.WebhookConfig{
+			URL:     server.URL,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+			Secret:  "s3cret",
+		}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(webhook.Send(d)).To(Succeed())
+
+		req := <-server.requests
+		body := <-server.bodies
+		Expect(req.Header.Get("Authorization")).To(Equal("Bearer token"))
+		Expect(req.Header.Get(notification.SignatureHeader)).To(Equal("sha256=" + notification.Sign([]byte("s3cret"), body)))
+	})
+
+	It("should reject templates that fail to parse", func() {
+		_, err := notification.NewWebhookNotifier(config.WebhookConfig{URL: server.URL, Template: `{{.Broken`}, zap.NewNop())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid template"))
+	})
+
+	It("should fail when the template does not render JSON", func() {
+		webhook, err := notification.NewWebhookNotifier(config.WebhookConfig{URL: server.URL, Template: `callsign={{.Callsign}}`}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		err = webhook.Send(d)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("valid JSON"))
+	})
+
+	It("should report non-2xx responses", func() {
+		server.status = http.StatusBadGateway
+		webhook, err := notification.NewWebhookNotifier(config.WebhookConfig{URL: server.URL}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		err = webhook.Send(d)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("unexpected status 502"))
+	})
+
+	It("should keep the webhook url out of errors", func() {
+		webhook, err := notification.NewWebhookNotifier(config.WebhookConfig{URL: "http://127.0.0.1:1/hooks/s3cret-token"}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+
+		err = webhook.Send(d)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("127.0.0.1:1"))
+		Expect(err.Error()).NotTo(ContainSubstring("s3cret-token"))
+	})
+
+	It("should honour the per-endpoint timeout", func() {
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer slow.Close()
+
+		webhook, err := notification.NewWebhookNotifier(config.WebhookConfig{URL: slow.URL, Timeout: 20 * time.Millisecond}, zap.NewNop())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(webhook.Send(d)).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("MultiNotifier", func() {
+	It("should deliver to every backend and join errors", func() {
+		ok := notification.NewMockNotificationSender()
+		failing := notification.NewMockNotificationSender()
+		failing.SetShouldError(true, "down")
+
+		multi := notification.NewMultiNotifier(
+			notification.NewNotifierWithSender(true, time.Second, zap.NewNop(), failing, 15.0, 30*time.Minute),
+			notification.NewNotifierWithSender(true, time.Second, zap.NewNop(), ok, 15.0, 30*time.Minute),
+		)
+
+		err := multi.Send(detection.New(aircraft.Aircraft{Call: "BAW12"}, detection.Observer{}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("mock error: down"))
+		Expect(ok.GetNotificationCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,102 @@
+package phraseology
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultTransitionAltitude is the altitude in feet above which flight levels are used
+const DefaultTransitionAltitude = 6000
+
+// levelRate is the vertical speed in feet per minute below which an aircraft is considered level
+const levelRate = 300
+
+var digitWords = [...]string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "niner"}
+
+var clockWords = [...]string{"twelve", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "eleven", "twelve"}
+
+// Digits speaks each digit of a whole number separately, e.g. 350 is "three five zero"
+func Digits(n int) string {
+	s := strconv.Itoa(n)
+	words := make([]string, 0, len(s))
+	for _, r := range s {
+		if r == '-' {
+			words = append(words, "minus")
+			continue
+		}
+		words = append(words, digitWords[r-'0'])
+	}
+	return strings.Join(words, " ")
+}
+
+// Heading speaks a bearing or heading as three digits, e.g. 45 is "zero four five"
+func Heading(degrees float64) string {
+	h := int(math.Round(degrees)) % 360
+	if h < 0 {
+		h += 360
+	}
+	s := strconv.Itoa(h)
+	for len(s) < 3 {
+		s = "0" + s
+	}
+	words := make([]string, len(s))
+	for i, r := range s {
+		words[i] = digitWords[r-'0']
+	}
+	return strings.Join(words, " ")
+}
+
+// Altitude speaks an altitude in feet.
+// Above the transition altitude it is a flight level, e.g. "flight level three five zero".
+// Below it, whole thousands and hundreds are spoken as such, e.g. "one one thousand five hundred".
+func Altitude(feet, transitionAltitude int) string {
+	if feet > transitionAltitude {
+		return "flight level " + Digits(int(math.Round(float64(feet)/100)))
+	}
+
+	hundreds := int(math.Round(float64(feet) / 100))
+	if hundreds <= 0 {
+		return "surface"
+	}
+
+	var words []string
+	if thousands := hundreds / 10; thousands > 0 {
+		words = append(words, Digits(thousands), "thousand")
+	}
+	if rest := hundreds % 10; rest > 0 {
+		words = append(words, digitWords[rest], "hundred")
+	}
+	return strings.Join(words, " ")
+}
+
+// Vertical describes the altitude with the aircraft's vertical trend,
+// e.g. "descending through four thousand" or "flight level three five zero" when level
+func Vertical(verticalRate, feet, transitionAltitude int) string {
+	altitude := Altitude(feet, transitionAltitude)
+	switch {
+	case verticalRate >= levelRate:
+		return "climbing through " + altitude
+	case verticalRate <= -levelRate:
+		return "descending through " + altitude
+	default:
+		return altitude
+	}
+}
+
+// Miles speaks a distance in nautical miles rounded to a whole number, e.g. 14.2 is "one four miles"
+func Miles(nm float64) string {
+	n := int(math.Round(nm))
+	if n == 1 {
+		return "one mile"
+	}
+	return Digits(n) + " miles"
+}
+
+// Clock speaks a clock position, e.g. 2 is "two o'clock"
+func Clock(position int) string {
+	if position < 0 || position > 12 {
+		return ""
+	}
+	return clockWords[position] + " o'clock"
+}
@@ -0,0 +1,13 @@
+package phraseology_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPhraseology(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Phraseology Suite")
+}
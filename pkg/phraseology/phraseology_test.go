@@ -0,0 +1,64 @@
+package phraseology_test
+
+import (
+	"github.com/lyarwood/godar/pkg/phraseology"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Phraseology", func() {
+	DescribeTable("Digits",
+		func(n int, expected string) {
+			Expect(phraseology.Digits(n)).To(Equal(expected))
+		},
+		Entry("single digit", 8, "eight"),
+		Entry("niner", 19, "one niner"),
+		Entry("flight level", 350, "three five zero"),
+		Entry("zero", 0, "zero"),
+	)
+
+	DescribeTable("Heading",
+		func(degrees float64, expected string) {
+			Expect(phraseology.Heading(degrees)).To(Equal(expected))
+		},
+		Entry("padded", 45.0, "zero four five"),
+		Entry("rounded", 269.6, "two seven zero"),
+		Entry("north", 359.7, "zero zero zero"),
+	)
+
+	DescribeTable("Altitude",
+		func(feet int, expected string) {
+			Expect(phraseology.Altitude(feet, phraseology.DefaultTransitionAltitude)).To(Equal(expected))
+		},
+		Entry("flight level", 35000, "flight level three five zero"),
+		Entry("flight level rounding", 9449, "flight level niner four"),
+		Entry("thousands", 4000, "four thousand"),
+		Entry("thousands and hundreds", 2500, "two thousand five hundred"),
+		Entry("hundreds", 800, "eight hundred"),
+		Entry("at the transition altitude", 6000, "six thousand"),
+		Entry("ground", 20, "surface"),
+	)
+
+	It("should use the configured transition altitude", func() {
+		Expect(phraseology.Altitude(11000, 18000)).To(Equal("one one thousand"))
+		Expect(phraseology.Altitude(19000, 18000)).To(Equal("flight level one niner zero"))
+	})
+
+	DescribeTable("Vertical",
+		func(rate, feet int, expected string) {
+			Expect(phraseology.Vertical(rate, feet, phraseology.DefaultTransitionAltitude)).To(Equal(expected))
+		},
+		Entry("descending", -1200, 4000, "descending through four thousand"),
+		Entry("climbing", 1500, 12000, "climbing through flight level one two zero"),
+		Entry("level", 100, 35000, "flight level three five zero"),
+	)
+
+	It("should speak miles and clock positions", func() {
+		Expect(phraseology.Miles(8.3)).To(Equal("eight miles"))
+		Expect(phraseology.Miles(14.2)).To(Equal("one four miles"))
+		Expect(phraseology.Miles(0.9)).To(Equal("one mile"))
+		Expect(phraseology.Clock(2)).To(Equal("two o'clock"))
+		Expect(phraseology.Clock(12)).To(Equal("twelve o'clock"))
+	})
+})
@@ -0,0 +1,147 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/geo"
+	"github.com/lyarwood/godar/pkg/phraseology"
+)
+
+// Funcs are available to every user supplied template
+var Funcs = template.FuncMap{
+	// json encodes a value as JSON, e.g. {"callsign": {{json .Aircraft.Call}}}
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// duration formats a time.Duration like the notification text, e.g. "12 min"
+	"duration": geo.FormatTimeToClosest,
+	// km formats a distance in km, e.g. "12.40 km"
+	"km": func(km float64) string {
+		return fmt.Sprintf("%.2f km", km)
+	},
+	// nm converts a distance in km to nautical miles, e.g. "6.7 nm"
+	"nm": func(km float64) string {
+		return fmt.Sprintf("%.1f nm", geo.KmToNauticalMiles(km))
+	},
+	// ft formats an altitude in feet, e.g. "35000 ft"
+	"ft": func(feet int) string {
+		return fmt.Sprintf("%d ft", feet)
+	},
+	// knots formats a speed in knots, e.g. "450 kt"
+	"knots": func(knots float64) string {
+		return fmt.Sprintf("%.0f kt", knots)
+	},
+	// round rounds to the given number of decimal places
+	"round": func(places int, v float64) float64 {
+		scale := math.Pow(10, float64(places))
+		return math.Round(v*scale) / scale
+	},
+	// pad left aligns a value in a column of the given width
+	"pad": func(width int, v any) string {
+		return fmt.Sprintf("%-*v", width, v)
+	},
+	// padLeft right aligns a value in a column of the given width
+	"padLeft": func(width int, v any) string {
+		return fmt.Sprintf("%*v", width, v)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// VoiceFuncs verbalise numbers in aviation phraseology for spoken callouts
+func VoiceFuncs(transitionAltitude int) template.FuncMap {
+	return template.FuncMap{
+		"digits":  phraseology.Digits,
+		"heading": phraseology.Heading,
+		"miles":   phraseology.Miles,
+		"clock":   phraseology.Clock,
+		"altitude": func(feet int) string {
+			return phraseology.Altitude(feet, transitionAltitude)
+		},
+		"vertical": func(verticalRate, feet int) string {
+			return phraseology.Vertical(verticalRate, feet, transitionAltitude)
+		},
+	}
+}
+
+// Parse parses a template with Funcs and any extra functions, returning nil for an empty template
+func Parse(name, text string, extra ...template.FuncMap) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl := template.New(name).Funcs(Funcs)
+	for _, funcs := range extra {
+		tmpl = tmpl.Funcs(funcs)
+	}
+	return tmpl.Option("missingkey=error").Parse(text)
+}
+
+// Render executes a template, returning "" for a nil template
+func Render(tmpl *template.Template, data any) (string, error) {
+	if tmpl == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Validate parses a template and executes it against sample data, so mistakes are reported when configuration is loaded
+func Validate(name, text string, data any, extra ...template.FuncMap) error {
+	tmpl, err := Parse(name, text, extra...)
+	if err != nil {
+		return err
+	}
+	if tmpl == nil {
+		return nil
+	}
+	return tmpl.Execute(io.Discard, data)
+}
+
+// Message is the data available to notification title and body templates.
+// Every Detection field and method is promoted, e.g. .Aircraft.Reg, .Distance or .Callsign.
+type Message struct {
+	*detection.Detection
+	// Prediction is the closest approach when it is within the viewable distance and prediction window, nil otherwise
+	Prediction *geo.ClosestApproach
+}
+
+// NewMessage wraps a detection for a notification template
+func NewMessage(d *detection.Detection, viewableDistance float64, predictionWindow time.Duration) Message {
+	m := Message{Detection: d}
+	if approach := d.ClosestApproach; approach != nil {
+		if approach.WillApproach && approach.TimeToClosest > 0 && approach.TimeToClosest < predictionWindow && approach.Distance <= viewableDistance {
+			m.Prediction = approach
+		}
+	}
+	return m
+}
+
+// SampleDetection returns a fully populated detection used to validate templates
+func SampleDetection() *detection.Detection {
+	d := detection.New(aircraft.Aircraft{
+		Icao: "400A0B", Call: "BAW12", Reg: "G-EUUA", Type: "A320", Mdl: "Airbus A320 214",
+		Op: "British Airways", Alt: 12000, Vsi: -1200, Spd: 280, Trak: 180, Lat: 51.1, Long: 0.01,
+		Sqk: 2045, WTC: aircraft.WTCMedium, Species: aircraft.SpeciesLandPlane, EngType: aircraft.EngTypeJet,
+	}, detection.Observer{Latitude: 51.0, Longitude: 0.0})
+	d.PreviousDistance = d.Distance + 1
+	d.Track = []detection.TrackPoint{{Time: d.Time, Latitude: 51.1, Longitude: 0.01, Altitude: 12000, Distance: d.Distance}}
+	return d
+}
+
+// SampleMessage returns a message with a prediction used to validate title and body templates
+func SampleMessage() Message {
+	return NewMessage(SampleDetection(), math.MaxFloat64, math.MaxInt64)
+}
@@ -0,0 +1,82 @@
+package templates_test
+
+import (
+	"time"
+
+	"github.com/lyarwood/godar/pkg/aircraft"
+	"github.com/lyarwood/godar/pkg/detection"
+	"github.com/lyarwood/godar/pkg/templates"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Templates", func() {
+	render := func(text string, data any) string {
+		tmpl, err := templates.Parse("test", text)
+		Expect(err).NotTo(HaveOccurred())
+		out, err := templates.Render(tmpl, data)
+		Expect(err).NotTo(HaveOccurred())
+		return out
+	}
+
+	It("should return nil for an empty template", func() {
+		tmpl, err := templates.Parse("empty", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tmpl).To(BeNil())
+
+		out, err := templates.Render(tmpl, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(BeEmpty())
+	})
+
+	DescribeTable("Funcs",
+		func(text string, expected string) {
+			Expect(render(text, nil)).To(Equal(expected))
+		},
+		Entry("km", `{{km 12.4}}`, "12.40 km"),
+		Entry("nm", `{{nm 18.52}}`, "10.0 nm"),
+		Entry("ft", `{{ft 35000}}`, "35000 ft"),
+		Entry("knots", `{{knots 449.6}}`, "450 kt"),
+		Entry("round", `{{round 1 3.14159}}`, "3.1"),
+		Entry("pad", `[{{pad 6 "BAW1"}}]`, "[BAW1  ]"),
+		Entry("padLeft", `[{{padLeft 6 350}}]`, "[   350]"),
+		Entry("upper", `{{upper "baw12"}}`, "BAW12"),
+		Entry("json", `{{json "a\"b"}}`, `"a\"b"`),
+	)
+
+	It("should expose the detection and its prediction to messages", func() {
+		d := detection.New(
+			aircraft.Aircraft{Call: "BAW12", Type: "A320", Alt: 12000, Spd: 300, Trak: 180, Lat: 51.1, Long: 0.001},
+			detection.Observer{Latitude: 51.0, Longitude: 0.0},
+		)
+
+		msg := templates.NewMessage(d, 15.0, 30*time.Minute)
+		Expect(render(`{{.Callsign}} {{.Aircraft.Type}} {{km .Distance}}`, msg)).To(Equal("BAW12 A320 11.13 km"))
+		Expect(render(`{{with .Prediction}}closest {{km .Distance}}{{end}}`, msg)).To(Equal("closest 0.07 km"))
+
+		// Predictions outside the prediction window are left out
+		msg = templates.NewMessage(d, 15.0, time.Second)
+		Expect(msg.Prediction).To(BeNil())
+	})
+
+	Describe("Validate", func() {
+		It("should accept templates that render against the sample message", func() {
+			Expect(templates.Validate("title", `{{.Callsign}} {{ft .Aircraft.Alt}} {{.Prediction.Distance}}`, templates.SampleMessage())).To(Succeed())
+		})
+
+		It("should reject syntax errors", func() {
+			Expect(templates.Validate("title", `{{.Callsign`, templates.SampleMessage())).NotTo(Succeed())
+		})
+
+		It("should reject unknown fields", func() {
+			Expect(templates.Validate("title", `{{.Aircraft.Tail}}`, templates.SampleMessage())).NotTo(Succeed())
+		})
+
+		It("should accept voice functions when they are provided", func() {
+			text := `{{altitude .Aircraft.Alt}}`
+			Expect(templates.Validate("voice", text, templates.SampleDetection())).NotTo(Succeed())
+			Expect(templates.Validate("voice", text, templates.SampleDetection(), templates.VoiceFuncs(6000))).To(Succeed())
+		})
+	})
+})